@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strconv"
 	"strings"
 )
 
@@ -23,6 +24,21 @@ type Config struct {
 	GCSBucketName string
 	GCSFileName   string
 	GCSCredentials string // Path to service account JSON file
+
+	// Tolerância relativa (ex.: 0.01 = 1%) para divergência entre métricas importadas e recalculadas
+	MetricsTolerance float64
+
+	// Defasagem esperada, em meses, entre o mês de envio e a competência dos dados, por esfera.
+	// Esferas não listadas usam DefaultExpectedLag.
+	ExpectedLagByEsfera map[string]int
+	DefaultExpectedLag  int
+
+	// Papéis que podem aprovar a alteração de "enviar" solicitada por um editor (nunca o próprio solicitante)
+	EnviarApproverRoles []string
+
+	// Com RejectUnknownPortals, linhas cujo portal não consta no catálogo (nem como alias) são
+	// recusadas na importação; caso contrário são gravadas com foraDoCatalogo=true
+	RejectUnknownPortals bool
 }
 
 func LoadConfig() *Config {
@@ -32,6 +48,37 @@ func LoadConfig() *Config {
 		GCSBucketName: os.Getenv("GCS_BUCKET_NAME"),
 		GCSFileName:   os.Getenv("GCS_FILE_NAME"),
 		GCSCredentials: os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"),
+		MetricsTolerance: 0.01,
+		ExpectedLagByEsfera: map[string]int{"ESTADUAL": 2, "MUNICIPAL": 3},
+		DefaultExpectedLag:  3,
+		EnviarApproverRoles: []string{"admin"},
+	}
+
+	if v, err := strconv.ParseFloat(os.Getenv("METRICS_TOLERANCE"), 64); err == nil && v >= 0 {
+		config.MetricsTolerance = v
+	}
+	for esfera := range config.ExpectedLagByEsfera {
+		if v, err := strconv.Atoi(os.Getenv("EXPECTED_LAG_" + esfera)); err == nil && v >= 0 {
+			config.ExpectedLagByEsfera[esfera] = v
+		}
+	}
+	if v, err := strconv.Atoi(os.Getenv("EXPECTED_LAG_DEFAULT")); err == nil && v >= 0 {
+		config.DefaultExpectedLag = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("PORTAL_CATALOG_REJECT_UNKNOWN")); err == nil {
+		config.RejectUnknownPortals = v
+	}
+	// ENVIAR_APPROVER_ROLES=admin,editor permite que outro editor aprove
+	if v := os.Getenv("ENVIAR_APPROVER_ROLES"); v != "" {
+		roles := []string{}
+		for _, role := range strings.Split(v, ",") {
+			if role = strings.ToLower(strings.TrimSpace(role)); role != "" {
+				roles = append(roles, role)
+			}
+		}
+		if len(roles) > 0 {
+			config.EnviarApproverRoles = roles
+		}
 	}
 	
 	// Determine data source based on environment variable
@@ -0,0 +1,34 @@
+package controller
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// requireRole carrega o usuário autenticado (userID injetado pelo SessionAuthMiddleware)
+// e verifica se ele possui um dos papéis informados. Em caso de falha já escreve a resposta.
+func requireRole(ctx *gin.Context, userService service.UserService, roles ...string) (*model.User, bool) {
+    userIDVal, exists := ctx.Get("userID")
+    if !exists {
+        ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Não autorizado"})
+        return nil, false
+    }
+
+    currentUser, err := userService.GetUserByID(userIDVal.(primitive.ObjectID))
+    if err != nil || currentUser == nil {
+        ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Usuário não encontrado"})
+        return nil, false
+    }
+
+    for _, role := range roles {
+        if currentUser.Role == role {
+            return currentUser, true
+        }
+    }
+    ctx.JSON(http.StatusForbidden, gin.H{"error": "Acesso negado"})
+    return nil, false
+}
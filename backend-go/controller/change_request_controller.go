@@ -0,0 +1,115 @@
+package controller
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "solid_react_golang_mongo_project/backend-go/middleware"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// ChangeRequestController expõe a fila de alterações de "enviar" aguardando aprovação
+type ChangeRequestController struct {
+    service     service.ChangeRequestService
+    authService service.AuthService
+    userService service.UserService
+}
+
+func NewChangeRequestController(s service.ChangeRequestService, auth service.AuthService, userSvc service.UserService) *ChangeRequestController {
+    return &ChangeRequestController{service: s, authService: auth, userService: userSvc}
+}
+
+func (c *ChangeRequestController) RegisterRoutes(r *gin.RouterGroup) {
+    protected := r.Group("/change-requests")
+    protected.Use(middleware.SessionAuthMiddleware(c.authService))
+    {
+        protected.GET("", c.ListChangeRequests)
+        protected.GET("/:id", c.GetChangeRequest)
+        protected.POST("/:id/approve", c.Approve)
+        protected.POST("/:id/reject", c.Reject)
+    }
+}
+
+// ListChangeRequests lista as solicitações, da mais recente para a mais antiga.
+// Query params opcionais: status (PENDING, APPROVED, REJECTED) e portalId.
+func (c *ChangeRequestController) ListChangeRequests(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin", "editor"); !ok {
+        return
+    }
+    query := model.ChangeRequestQuery{Status: ctx.Query("status")}
+    if v := ctx.Query("portalId"); v != "" {
+        query.PortalIDs = []string{v}
+    }
+    requests, err := c.service.ListChangeRequests(query)
+    if err != nil {
+        writeChangeRequestError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, requests)
+}
+
+func (c *ChangeRequestController) GetChangeRequest(ctx *gin.Context) {
+    _, id, ok := c.changeRequest(ctx)
+    if !ok {
+        return
+    }
+    req, err := c.service.GetChangeRequest(id)
+    if err != nil {
+        writeChangeRequestError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, req)
+}
+
+// Approve aplica a alteração em nome do aprovador, que não pode ser o solicitante
+func (c *ChangeRequestController) Approve(ctx *gin.Context) {
+    user, id, ok := c.changeRequest(ctx)
+    if !ok {
+        return
+    }
+    req, err := c.service.Approve(id, user)
+    if err != nil {
+        writeChangeRequestError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, req)
+}
+
+// Reject descarta a alteração; o corpo aceita {"motivo": "..."}
+func (c *ChangeRequestController) Reject(ctx *gin.Context) {
+    user, id, ok := c.changeRequest(ctx)
+    if !ok {
+        return
+    }
+    var body struct {
+        Motivo string `json:"motivo"`
+    }
+    if ctx.Request.ContentLength > 0 {
+        if err := ctx.ShouldBindJSON(&body); err != nil {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+            return
+        }
+    }
+    req, err := c.service.Reject(id, user, body.Motivo)
+    if err != nil {
+        writeChangeRequestError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, req)
+}
+
+// changeRequest autentica o usuário (admin ou editor) e lê o :id da solicitação
+func (c *ChangeRequestController) changeRequest(ctx *gin.Context) (*model.User, primitive.ObjectID, bool) {
+    user, ok := requireRole(ctx, c.userService, "admin", "editor")
+    if !ok {
+        return nil, primitive.NilObjectID, false
+    }
+    id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("id").Error()})
+        return nil, primitive.NilObjectID, false
+    }
+    return user, id, true
+}
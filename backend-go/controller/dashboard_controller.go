@@ -0,0 +1,33 @@
+package controller
+
+import (
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// DashboardController expõe o resumo da entrega usado na tela de listagem
+type DashboardController struct {
+    service service.DashboardService
+}
+
+func NewDashboardController(s service.DashboardService) *DashboardController {
+    return &DashboardController{service: s}
+}
+
+func (c *DashboardController) RegisterRoutes(r *gin.RouterGroup) {
+    // Leitura pública, como a listagem de portais
+    r.GET("/dashboard", c.GetDashboard)
+}
+
+// GetDashboard retorna contagens, somas e médias das linhas da referência (query param
+// referencia; sem ele, de todas as linhas)
+func (c *DashboardController) GetDashboard(ctx *gin.Context) {
+    summary, err := c.service.Summary(ctx.Query("referencia"))
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular o resumo da entrega"})
+        return
+    }
+    ctx.JSON(http.StatusOK, summary)
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "solid_react_golang_mongo_project/backend-go/middleware"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// DeliveryController expõe o ciclo de vida das entregas (Referencia)
+type DeliveryController struct {
+    deliveryService service.DeliveryService
+    manifestService service.ManifestService
+    diffService     service.DeliveryDiffService
+    authService     service.AuthService
+    userService     service.UserService
+}
+
+func NewDeliveryController(deliverySvc service.DeliveryService, manifestSvc service.ManifestService, diffSvc service.DeliveryDiffService, auth service.AuthService, userSvc service.UserService) *DeliveryController {
+    return &DeliveryController{deliveryService: deliverySvc, manifestService: manifestSvc, diffService: diffSvc, authService: auth, userService: userSvc}
+}
+
+func (c *DeliveryController) RegisterRoutes(r *gin.RouterGroup) {
+    // Leitura pública, como a listagem de portais
+    r.GET("/deliveries", c.ListDeliveries)
+    r.GET("/deliveries/compare", c.Compare)
+    r.GET("/deliveries/compare.csv", c.CompareCSV)
+    r.GET("/deliveries/:referencia", c.GetDelivery)
+    r.GET("/deliveries/:referencia/summary", c.GetSummary)
+    r.GET("/deliveries/:referencia/manifest", c.GetManifest)
+    r.GET("/deliveries/:referencia/manifest.csv", c.DownloadManifestCSV)
+    r.GET("/deliveries/:referencia/manifests", c.ListManifests)
+
+    protected := r.Group("/deliveries")
+    protected.Use(middleware.SessionAuthMiddleware(c.authService))
+    {
+        protected.POST("", c.CreateDelivery)
+        protected.PUT("/:referencia/status", c.UpdateStatus)
+        protected.POST("/:referencia/manifest", c.GenerateManifest)
+    }
+}
+
+// ListDeliveries lista as entregas, da mais recente para a mais antiga
+func (c *DeliveryController) ListDeliveries(ctx *gin.Context) {
+    deliveries, err := c.deliveryService.ListDeliveries()
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar entregas"})
+        return
+    }
+    ctx.JSON(http.StatusOK, deliveries)
+}
+
+// GetDelivery retorna a entrega com o histórico de transições. "/" na referência pode ser enviada como "-".
+func (c *DeliveryController) GetDelivery(ctx *gin.Context) {
+    delivery, err := c.deliveryService.GetDelivery(ctx.Param("referencia"))
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, delivery)
+}
+
+// GetSummary retorna as contagens por status e de linhas marcadas para envio
+func (c *DeliveryController) GetSummary(ctx *gin.Context) {
+    summary, err := c.deliveryService.Summary(ctx.Param("referencia"))
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, summary)
+}
+
+// CreateDelivery abre uma entrega (OPEN) antes da importação das linhas (admins e editores)
+func (c *DeliveryController) CreateDelivery(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin", "editor"); !ok {
+        return
+    }
+    var body struct {
+        Referencia  string     `json:"referencia"`
+        DataEntrega model.Data `json:"dataEntrega"`
+    }
+    if err := ctx.ShouldBindJSON(&body); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos: " + err.Error()})
+        return
+    }
+    delivery, err := c.deliveryService.CreateDelivery(body.Referencia, body.DataEntrega)
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusCreated, delivery)
+}
+
+// UpdateStatus aplica uma transição de estado ({"status": "IN_REVIEW"}). Admins e editores podem
+// transitar; reabrir ou enviar uma entrega fechada exige admin.
+func (c *DeliveryController) UpdateStatus(ctx *gin.Context) {
+    user, ok := requireRole(ctx, c.userService, "admin", "editor")
+    if !ok {
+        return
+    }
+    var body struct {
+        Status string `json:"status"`
+    }
+    if err := ctx.ShouldBindJSON(&body); err != nil || body.Status == "" {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Campo 'status' é obrigatório"})
+        return
+    }
+    delivery, err := c.deliveryService.Transition(ctx.Param("referencia"), body.Status, user)
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, delivery)
+}
+
+// GenerateManifest gera o manifesto de envio (linhas com enviar=true) e o armazena (admins e editores)
+func (c *DeliveryController) GenerateManifest(ctx *gin.Context) {
+    user, ok := requireRole(ctx, c.userService, "admin", "editor")
+    if !ok {
+        return
+    }
+    manifest, err := c.manifestService.Generate(ctx.Param("referencia"), user.ID)
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusCreated, manifest)
+}
+
+// GetManifest retorna o manifesto mais recente da entrega, ou o informado em ?id=
+func (c *DeliveryController) GetManifest(ctx *gin.Context) {
+    manifest, err := c.manifestService.GetManifest(ctx.Param("referencia"), ctx.Query("id"))
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, manifest)
+}
+
+// DownloadManifestCSV baixa o CSV armazenado do manifesto (mais recente ou ?id=); o header
+// X-Checksum-SHA256 traz o checksum do arquivo
+func (c *DeliveryController) DownloadManifestCSV(ctx *gin.Context) {
+    manifest, err := c.manifestService.GetManifest(ctx.Param("referencia"), ctx.Query("id"))
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    filename := fmt.Sprintf("manifesto-%s-%s.csv", strings.ReplaceAll(manifest.Referencia, "/", "-"), manifest.ID.Hex())
+    ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+    ctx.Header("X-Checksum-SHA256", manifest.Checksum)
+    ctx.Data(http.StatusOK, "text/csv; charset=utf-8", []byte(manifest.CSV))
+}
+
+// ListManifests lista os manifestos gerados para a entrega, do mais recente para o mais antigo
+func (c *DeliveryController) ListManifests(ctx *gin.Context) {
+    manifests, err := c.manifestService.ListManifests(ctx.Param("referencia"))
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, manifests)
+}
+
+// Compare compara duas entregas (query params from, to e threshold, em %): portais novos e
+// removidos, transições de status e variações de volume acima do limiar
+func (c *DeliveryController) Compare(ctx *gin.Context) {
+    diff, ok := c.compare(ctx)
+    if !ok {
+        return
+    }
+    ctx.JSON(http.StatusOK, diff)
+}
+
+// CompareCSV baixa a mesma comparação em CSV separado por ';'
+func (c *DeliveryController) CompareCSV(ctx *gin.Context) {
+    diff, ok := c.compare(ctx)
+    if !ok {
+        return
+    }
+    var buf bytes.Buffer
+    if err := c.diffService.ExportCSV(&buf, diff); err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao exportar comparação"})
+        return
+    }
+    filename := fmt.Sprintf("comparacao-%s-%s.csv", strings.ReplaceAll(diff.From, "/", "-"), strings.ReplaceAll(diff.To, "/", "-"))
+    ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+    ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+func (c *DeliveryController) compare(ctx *gin.Context) (model.DeliveryDiff, bool) {
+    threshold := service.DefaultDiffThreshold
+    if v := ctx.Query("threshold"); v != "" {
+        parsed, err := strconv.ParseFloat(v, 64)
+        if err != nil {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("threshold").Error()})
+            return model.DeliveryDiff{}, false
+        }
+        threshold = parsed
+    }
+    diff, err := c.diffService.Compare(ctx.Query("from"), ctx.Query("to"), threshold)
+    if err != nil {
+        c.writeDeliveryError(ctx, err)
+        return model.DeliveryDiff{}, false
+    }
+    return diff, true
+}
+
+func (c *DeliveryController) writeDeliveryError(ctx *gin.Context, err error) {
+    switch {
+    case errors.Is(err, service.ErrInvalidDelivery), errors.Is(err, service.ErrInvalidDiff):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrDeliveryNotFound), errors.Is(err, service.ErrManifestNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrDeliveryExists), errors.Is(err, service.ErrInvalidTransition):
+        ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrManifestEmpty), errors.Is(err, service.ErrManifestBlocked):
+        ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrTransitionForbidden):
+        ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    default:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar entrega"})
+    }
+}
@@ -0,0 +1,147 @@
+package controller
+
+import (
+    "bytes"
+    "errors"
+    "fmt"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "github.com/gin-gonic/gin"
+    "solid_react_golang_mongo_project/backend-go/middleware"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// ImportExportController expõe a importação/exportação de portais em arquivos
+type ImportExportController struct {
+    importService service.PortalImportService
+    csvService    service.PortalCSVService
+    exportService service.DeliveryExportService
+    authService   service.AuthService
+    userService   service.UserService
+}
+
+func NewImportExportController(importSvc service.PortalImportService, csvSvc service.PortalCSVService, exportSvc service.DeliveryExportService, auth service.AuthService, userSvc service.UserService) *ImportExportController {
+    return &ImportExportController{importService: importSvc, csvService: csvSvc, exportService: exportSvc, authService: auth, userService: userSvc}
+}
+
+func (c *ImportExportController) RegisterRoutes(r *gin.RouterGroup) {
+    // Exportação segue a mesma regra de leitura pública da listagem
+    r.GET("/portals/export.csv", c.ExportCSV)
+    r.GET("/deliveries/:referencia/export.xlsx", c.ExportDeliveryXLSX)
+
+    protected := r.Group("/portals")
+    protected.Use(middleware.SessionAuthMiddleware(c.authService))
+    {
+        protected.POST("/import", c.ImportExcel)
+        protected.POST("/import.csv", c.ImportCSV)
+    }
+}
+
+// ImportExcel recebe a planilha (.xlsx, campo multipart "file") e importa todas as abas (somente admins).
+// Query params opcionais: headerRow (padrão 2), dateFmt (padrão dd/MM/yyyy) e dryRun.
+func (c *ImportExportController) ImportExcel(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+
+    fileHeader, err := ctx.FormFile("file")
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo não enviado (campo 'file')"})
+        return
+    }
+
+    opts := model.ImportOptions{DateFmt: ctx.Query("dateFmt")}
+    if v := ctx.Query("headerRow"); v != "" {
+        headerRow, convErr := strconv.Atoi(v)
+        if convErr != nil || headerRow < 1 {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": "headerRow inválido"})
+            return
+        }
+        opts.HeaderRow = headerRow
+    }
+    if v := ctx.Query("dryRun"); v != "" {
+        opts.DryRun, _ = strconv.ParseBool(v)
+    }
+
+    file, err := fileHeader.Open()
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Não foi possível ler o arquivo"})
+        return
+    }
+    defer file.Close()
+
+    report, err := c.importService.ImportWorkbook(fileHeader.Filename, file, opts)
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    ctx.JSON(http.StatusOK, report)
+}
+
+// ExportCSV exporta os portais em CSV (';') aplicando os mesmos filtros/ordenação de GET /portals
+func (c *ImportExportController) ExportCSV(ctx *gin.Context) {
+    query, err := parsePortalQuery(ctx)
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+
+    var buf bytes.Buffer
+    if err := c.csvService.ExportCSV(&buf, query); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    ctx.Header("Content-Disposition", `attachment; filename="portals.csv"`)
+    ctx.Data(http.StatusOK, "text/csv; charset=utf-8", buf.Bytes())
+}
+
+// ImportCSV importa portais de um CSV (';', campo multipart "file") (somente admins).
+// Linhas que não puderam ser interpretadas são listadas no relatório em "errors".
+func (c *ImportExportController) ImportCSV(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+
+    fileHeader, err := ctx.FormFile("file")
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Arquivo não enviado (campo 'file')"})
+        return
+    }
+    dryRun, _ := strconv.ParseBool(ctx.Query("dryRun"))
+
+    file, err := fileHeader.Open()
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Não foi possível ler o arquivo"})
+        return
+    }
+    defer file.Close()
+
+    report, err := c.csvService.ImportCSV(fileHeader.Filename, file, dryRun)
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    ctx.JSON(http.StatusOK, report)
+}
+
+// ExportDeliveryXLSX gera a planilha da entrega no layout do cliente (uma aba por DataEntrega).
+// Referências com "/" podem ser informadas com "-" (ex.: 11-2025).
+func (c *ImportExportController) ExportDeliveryXLSX(ctx *gin.Context) {
+    referencia := ctx.Param("referencia")
+
+    var buf bytes.Buffer
+    if err := c.exportService.ExportXLSX(&buf, referencia); err != nil {
+        if errors.Is(err, service.ErrReferenciaNotFound) {
+            ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+            return
+        }
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar planilha"})
+        return
+    }
+    fileName := fmt.Sprintf("entrega_%s.xlsx", strings.ReplaceAll(referencia, "/", "-"))
+    ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+    ctx.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", buf.Bytes())
+}
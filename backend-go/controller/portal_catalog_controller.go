@@ -0,0 +1,130 @@
+package controller
+
+import (
+    "errors"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "solid_react_golang_mongo_project/backend-go/middleware"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// PortalCatalogController expõe o cadastro mestre de portais: leitura para usuários autenticados,
+// alterações somente para admins
+type PortalCatalogController struct {
+    catalogService service.PortalCatalogService
+    authService    service.AuthService
+    userService    service.UserService
+}
+
+func NewPortalCatalogController(catalogSvc service.PortalCatalogService, auth service.AuthService, userSvc service.UserService) *PortalCatalogController {
+    return &PortalCatalogController{catalogService: catalogSvc, authService: auth, userService: userSvc}
+}
+
+func (c *PortalCatalogController) RegisterRoutes(r *gin.RouterGroup) {
+    catalogRouter := r.Group("/portal-catalog")
+    catalogRouter.Use(middleware.SessionAuthMiddleware(c.authService))
+    {
+        catalogRouter.GET("", c.ListEntries)
+        catalogRouter.GET("/:id", c.GetEntry)
+        catalogRouter.POST("", c.CreateEntry)
+        catalogRouter.PUT("/:id", c.UpdateEntry)
+        catalogRouter.DELETE("/:id", c.DeleteEntry)
+    }
+}
+
+// ListEntries lista o catálogo ordenado pelo nome canônico
+func (c *PortalCatalogController) ListEntries(ctx *gin.Context) {
+    entries, err := c.catalogService.ListEntries()
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar catálogo de portais"})
+        return
+    }
+    ctx.JSON(http.StatusOK, entries)
+}
+
+func (c *PortalCatalogController) GetEntry(ctx *gin.Context) {
+    id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+        return
+    }
+    entry, err := c.catalogService.GetEntry(id)
+    if err != nil {
+        c.writeCatalogError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, entry)
+}
+
+// CreateEntry cadastra um portal com seus aliases
+func (c *PortalCatalogController) CreateEntry(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+    var entry model.PortalCatalogEntry
+    if err := ctx.ShouldBindJSON(&entry); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+        return
+    }
+    created, err := c.catalogService.CreateEntry(entry)
+    if err != nil {
+        c.writeCatalogError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusCreated, created)
+}
+
+// UpdateEntry substitui o cadastro do portal (incluindo a lista de aliases)
+func (c *PortalCatalogController) UpdateEntry(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+    id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+        return
+    }
+    var entry model.PortalCatalogEntry
+    if err := ctx.ShouldBindJSON(&entry); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+        return
+    }
+    updated, err := c.catalogService.UpdateEntry(id, entry)
+    if err != nil {
+        c.writeCatalogError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, updated)
+}
+
+func (c *PortalCatalogController) DeleteEntry(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+    id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+        return
+    }
+    if err := c.catalogService.DeleteEntry(id); err != nil {
+        c.writeCatalogError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+func (c *PortalCatalogController) writeCatalogError(ctx *gin.Context, err error) {
+    switch {
+    case errors.Is(err, service.ErrInvalidCatalogEntry):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrCatalogEntryNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrCatalogConflict):
+        ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    default:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gravar catálogo de portais"})
+    }
+}
@@ -0,0 +1,151 @@
+package controller
+
+import (
+    "errors"
+    "net/http"
+
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "solid_react_golang_mongo_project/backend-go/middleware"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// PortalCommentController expõe as discussões de cada linha de portal (id = _id da linha)
+type PortalCommentController struct {
+    service     service.PortalCommentService
+    authService service.AuthService
+    userService service.UserService
+}
+
+func NewPortalCommentController(s service.PortalCommentService, auth service.AuthService, userSvc service.UserService) *PortalCommentController {
+    return &PortalCommentController{service: s, authService: auth, userService: userSvc}
+}
+
+func (c *PortalCommentController) RegisterRoutes(r *gin.RouterGroup) {
+    // Leitura pública, como a dos portais
+    r.GET("/portals/:id/comments", c.ListComments)
+
+    protected := r.Group("/portals")
+    protected.Use(middleware.SessionAuthMiddleware(c.authService))
+    {
+        protected.POST(":id/comments", c.AddComment)
+        protected.PUT(":id/comments/:commentId", c.EditComment)
+        protected.DELETE(":id/comments/:commentId", c.DeleteComment)
+        protected.PUT(":id/comments/:commentId/resolved", c.SetResolved)
+    }
+}
+
+// ListComments lista os comentários da linha em ordem cronológica, incluindo os não resolvidos
+// de competências anteriores do mesmo portal (herdado=true)
+func (c *PortalCommentController) ListComments(ctx *gin.Context) {
+    comments, err := c.service.ListComments(ctx.Param("id"))
+    if err != nil {
+        c.writeCommentError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, comments)
+}
+
+// AddComment cria um comentário ({"texto": "..."}) em nome do usuário autenticado (admins e editores)
+func (c *PortalCommentController) AddComment(ctx *gin.Context) {
+    user, ok := requireRole(ctx, c.userService, "admin", "editor")
+    if !ok {
+        return
+    }
+    var body struct {
+        Texto string `json:"texto"`
+    }
+    if err := ctx.ShouldBindJSON(&body); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+        return
+    }
+    comment, err := c.service.AddComment(ctx.Param("id"), body.Texto, user)
+    if err != nil {
+        c.writeCommentError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusCreated, comment)
+}
+
+// EditComment altera o texto de um comentário (somente o autor)
+func (c *PortalCommentController) EditComment(ctx *gin.Context) {
+    user, commentID, ok := c.commentRequest(ctx)
+    if !ok {
+        return
+    }
+    var body struct {
+        Texto string `json:"texto"`
+    }
+    if err := ctx.ShouldBindJSON(&body); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+        return
+    }
+    comment, err := c.service.EditComment(ctx.Param("id"), commentID, body.Texto, user)
+    if err != nil {
+        c.writeCommentError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, comment)
+}
+
+// DeleteComment remove um comentário (somente o autor)
+func (c *PortalCommentController) DeleteComment(ctx *gin.Context) {
+    user, commentID, ok := c.commentRequest(ctx)
+    if !ok {
+        return
+    }
+    if err := c.service.DeleteComment(ctx.Param("id"), commentID, user); err != nil {
+        c.writeCommentError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// SetResolved marca o comentário como resolvido ou o reabre ({"resolvido": true|false})
+func (c *PortalCommentController) SetResolved(ctx *gin.Context) {
+    user, commentID, ok := c.commentRequest(ctx)
+    if !ok {
+        return
+    }
+    var body struct {
+        Resolvido *bool `json:"resolvido"`
+    }
+    if err := ctx.ShouldBindJSON(&body); err != nil || body.Resolvido == nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Campo 'resolvido' é obrigatório"})
+        return
+    }
+    comment, err := c.service.SetResolved(ctx.Param("id"), commentID, *body.Resolvido, user)
+    if err != nil {
+        c.writeCommentError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, comment)
+}
+
+// commentRequest valida o papel do usuário (admin/editor) e o commentId do path
+func (c *PortalCommentController) commentRequest(ctx *gin.Context) (*model.User, primitive.ObjectID, bool) {
+    user, ok := requireRole(ctx, c.userService, "admin", "editor")
+    if !ok {
+        return nil, primitive.NilObjectID, false
+    }
+    commentID, err := primitive.ObjectIDFromHex(ctx.Param("commentId"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("commentId").Error()})
+        return nil, primitive.NilObjectID, false
+    }
+    return user, commentID, true
+}
+
+func (c *PortalCommentController) writeCommentError(ctx *gin.Context, err error) {
+    switch {
+    case errors.Is(err, service.ErrInvalidComment):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrCommentForbidden):
+        ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrPortalNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar comentário"})
+    }
+}
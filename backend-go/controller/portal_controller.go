@@ -2,47 +2,218 @@
 package controller
 
 import (
+    "errors"
+    "fmt"
     "net/http"
+    "strconv"
+    "strings"
+    "time"
 
     "github.com/gin-gonic/gin"
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
     "solid_react_golang_mongo_project/backend-go/middleware"
+    "solid_react_golang_mongo_project/backend-go/model"
     "solid_react_golang_mongo_project/backend-go/service"
 )
 
 type PortalController struct {
-    service     service.PortalService
-    authService service.AuthService
-    userService service.UserService
+    service       service.PortalService
+    lagService    service.LagService
+    changeService  service.PortalChangeService
+    commentService service.PortalCommentService
+    requestService service.ChangeRequestService
+    authService    service.AuthService
+    userService    service.UserService
 }
 
-func NewPortalController(s service.PortalService, auth service.AuthService, userSvc service.UserService) *PortalController {
-    return &PortalController{service: s, authService: auth, userService: userSvc}
+func NewPortalController(s service.PortalService, lagSvc service.LagService, changeSvc service.PortalChangeService, commentSvc service.PortalCommentService, requestSvc service.ChangeRequestService, auth service.AuthService, userSvc service.UserService) *PortalController {
+    return &PortalController{service: s, lagService: lagSvc, changeService: changeSvc, commentService: commentSvc, requestService: requestSvc, authService: auth, userService: userSvc}
 }
 
 func (c *PortalController) RegisterRoutes(r *gin.RouterGroup) {
     // Rotas públicas de leitura
     r.GET("/portals", c.GetAllPortals)
+    r.GET("/portals/lag-report", c.GetLagReport)
     r.GET("/portals/:id", c.GetPortalByID)
 
     // Rotas protegidas para edição
     portalRouter := r.Group("/portals")
     portalRouter.Use(middleware.SessionAuthMiddleware(c.authService))
     {
+        portalRouter.PATCH("", c.BulkUpdatePortals)
         portalRouter.PUT(":id", c.UpdatePortal)
+        portalRouter.GET(":id/changes", c.GetChangeHistory)
     }
 }
 
+// GetAllPortals lista portais com filtros, ordenação e paginação no servidor.
+// Query params: portal, esfera, status, referencia, dataEntrega, mesAnoReferencia, enviar,
+// intervalos dataEntregaFrom/dataEntregaTo e mesAnoReferenciaFrom/mesAnoReferenciaTo,
+// sort (ex.: "esfera,-volumetriaServicos"), limit e offset.
 func (c *PortalController) GetAllPortals(ctx *gin.Context) {
-    portals, err := c.service.GetAllPortals()
+    query, err := parsePortalQuery(ctx)
     if err != nil {
-        ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
         return
     }
-    ctx.JSON(http.StatusOK, portals)
+    page, err := c.service.FindPortals(query)
+    if err != nil {
+        if errors.Is(err, service.ErrInvalidPortalQuery) {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar portais"})
+        return
+    }
+    // Cada linha traz o comentário não resolvido mais recente (ultimoComentario)
+    if err := c.commentService.AttachLatestUnresolved(page.Items); err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar comentários"})
+        return
+    }
+    // ... e a alteração de "enviar" aguardando aprovação (alteracaoPendente)
+    if err := c.requestService.AttachPending(page.Items); err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar alterações pendentes"})
+        return
+    }
+    ctx.JSON(http.StatusOK, page)
+}
+
+// parsePortalQuery monta a PortalQuery a partir da query string
+func parsePortalQuery(ctx *gin.Context) (model.PortalQuery, error) {
+    query := model.PortalQuery{
+        Portal:     ctx.Query("portal"),
+        Esfera:     ctx.Query("esfera"),
+        Status:     ctx.Query("status"),
+        Referencia: ctx.Query("referencia"),
+    }
+    // Datas ("dd/MM/yyyy", "yyyy-MM-dd" ou "MM/yyyy") e competências ("MM/YYYY"), exatas ou em intervalo
+    dates := map[string]*model.Data{
+        "dataEntrega":     &query.DataEntrega,
+        "dataEntregaFrom": &query.DataEntregaDe,
+        "dataEntregaTo":   &query.DataEntregaAte,
+    }
+    for name, target := range dates {
+        if err := target.UnmarshalText([]byte(ctx.Query(name))); err != nil {
+            return query, errInvalidParam(name)
+        }
+    }
+    competencias := map[string]*model.Competencia{
+        "mesAnoReferencia":     &query.MesAnoReferencia,
+        "mesAnoReferenciaFrom": &query.MesAnoReferenciaDe,
+        "mesAnoReferenciaTo":   &query.MesAnoReferenciaAte,
+    }
+    for name, target := range competencias {
+        if err := target.UnmarshalText([]byte(ctx.Query(name))); err != nil {
+            return query, errInvalidParam(name)
+        }
+    }
+    if v := ctx.Query("enviar"); v != "" {
+        enviar, err := strconv.ParseBool(v)
+        if err != nil {
+            return query, errInvalidParam("enviar")
+        }
+        query.Enviar = &enviar
+    }
+    if v := ctx.Query("sort"); v != "" {
+        for _, f := range strings.Split(v, ",") {
+            f = strings.TrimSpace(f)
+            if f == "" {
+                continue
+            }
+            sf := model.SortField{Field: strings.TrimPrefix(f, "-"), Desc: strings.HasPrefix(f, "-")}
+            query.Sort = append(query.Sort, sf)
+        }
+    }
+    if v := ctx.Query("limit"); v != "" {
+        limit, err := strconv.Atoi(v)
+        if err != nil {
+            return query, errInvalidParam("limit")
+        }
+        query.Limit = limit
+    }
+    if v := ctx.Query("offset"); v != "" {
+        offset, err := strconv.Atoi(v)
+        if err != nil {
+            return query, errInvalidParam("offset")
+        }
+        query.Offset = offset
+    }
+    return query, nil
+}
+
+// GetChangeHistory lista as edições da linha (id = _id do portal), da mais recente para a mais antiga.
+// Filtros opcionais: userId, from e to (RFC3339 ou AAAA-MM-DD; "to" só com a data inclui o dia inteiro).
+func (c *PortalController) GetChangeHistory(ctx *gin.Context) {
+    query := model.PortalChangeQuery{PortalID: ctx.Param("id")}
+    if v := ctx.Query("userId"); v != "" {
+        userID, err := primitive.ObjectIDFromHex(v)
+        if err != nil {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("userId").Error()})
+            return
+        }
+        query.UserID = userID
+    }
+    var err error
+    if query.De, err = parseDateParam(ctx.Query("from"), false); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("from").Error()})
+        return
+    }
+    if query.Ate, err = parseDateParam(ctx.Query("to"), true); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("to").Error()})
+        return
+    }
+
+    if _, err := c.service.GetPortalByID(query.PortalID); err != nil {
+        if errors.Is(err, service.ErrPortalNotFound) {
+            ctx.JSON(http.StatusNotFound, gin.H{"error": "Portal não encontrado"})
+            return
+        }
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar portal"})
+        return
+    }
+    changes, err := c.changeService.FindChanges(query)
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar histórico de alterações"})
+        return
+    }
+    ctx.JSON(http.StatusOK, changes)
+}
+
+// parseDateParam aceita RFC3339 ou AAAA-MM-DD; com endOfDay, a data simples vale até o fim do dia
+func parseDateParam(v string, endOfDay bool) (time.Time, error) {
+    if v == "" {
+        return time.Time{}, nil
+    }
+    if t, err := time.Parse(time.RFC3339, v); err == nil {
+        return t, nil
+    }
+    t, err := time.ParseInLocation("2006-01-02", v, time.Local)
+    if err != nil {
+        return t, err
+    }
+    if endOfDay {
+        t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+    }
+    return t, nil
 }
 
+func errInvalidParam(name string) error {
+    return fmt.Errorf("parâmetro inválido: %s", name)
+}
+
+// GetLagReport classifica os portais pela defasagem entre envio e competência.
+// Query params opcionais: referencia e esfera.
+func (c *PortalController) GetLagReport(ctx *gin.Context) {
+    report, err := c.lagService.Report(ctx.Query("referencia"), ctx.Query("esfera"))
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gerar relatório de defasagem"})
+        return
+    }
+    ctx.JSON(http.StatusOK, report)
+}
+
+// GetPortalByID retorna o portal com o header ETag da versão (usado no If-Match do PUT)
 func (c *PortalController) GetPortalByID(ctx *gin.Context) {
     id := ctx.Param("id")
     portal, err := c.service.GetPortalByID(id)
@@ -50,10 +221,156 @@ func (c *PortalController) GetPortalByID(ctx *gin.Context) {
         ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
         return
     }
-    ctx.JSON(http.StatusOK, portal)
+    ctx.Header("ETag", portalETag(portal))
+    ctx.JSON(http.StatusOK, c.withPending(portal))
+}
+
+// withPending preenche a alteração pendente do portal (erros na consulta apenas omitem o campo)
+func (c *PortalController) withPending(portal model.Portal) model.Portal {
+    portals := []model.Portal{portal}
+    if err := c.requestService.AttachPending(portals); err != nil {
+        return portal
+    }
+    return portals[0]
+}
+
+// writeChangeRequestError traduz os erros de solicitação de alteração em status HTTP
+func writeChangeRequestError(ctx *gin.Context, err error) {
+    switch {
+    case errors.Is(err, service.ErrChangeRequestNotFound), errors.Is(err, service.ErrPortalNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrChangeRequestPending), errors.Is(err, service.ErrChangeRequestDecided):
+        ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrChangeRequestForbidden):
+        ctx.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrDeliveryLocked):
+        ctx.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrVersionConflict):
+        ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+    default:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao processar solicitação de alteração"})
+    }
+}
+
+func portalETag(portal model.Portal) string {
+    return fmt.Sprintf(`"%d"`, portal.Version)
+}
+
+// parseIfMatch extrai a versão do header If-Match ("3" ou W/"3"); "*" ou ausente não condiciona
+func parseIfMatch(header string) (*int, error) {
+    header = strings.TrimSpace(header)
+    if header == "" || header == "*" {
+        return nil, nil
+    }
+    version, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(header, "W/"), `"`))
+    if err != nil {
+        return nil, err
+    }
+    return &version, nil
+}
+
+// editablePortalFields são os campos que PUT /portals/:id e PATCH /portals podem alterar
+type editablePortalFields struct {
+    ObservacaoTimeDados *string `json:"observacaoTimeDados"`
+    Enviar              *bool   `json:"enviar"`
+    Status              *string `json:"status"`
+    StatusManual        *bool   `json:"statusManual"`
+    NovosDados          *bool   `json:"novosDados"`
+}
+
+// updates valida os campos informados e monta o mapa de atualização apenas com eles
+func (f editablePortalFields) updates() (bson.M, error) {
+    updates := bson.M{}
+    if f.ObservacaoTimeDados != nil { updates["observacaoTimeDados"] = *f.ObservacaoTimeDados }
+    if f.Enviar != nil { updates["enviar"] = *f.Enviar }
+    if f.NovosDados != nil { updates["novosDados"] = *f.NovosDados }
+    if f.Status != nil {
+        // Validar status permitido
+        allowed := map[string]bool{model.StatusOK: true, model.StatusWarning: true, model.StatusError: true}
+        if !allowed[*f.Status] {
+            return nil, fmt.Errorf("Status inválido")
+        }
+        updates["status"] = *f.Status
+    }
+    // statusManual=false remove o override e devolve o status à classificação automática
+    if f.StatusManual != nil {
+        if *f.StatusManual && f.Status == nil {
+            return nil, fmt.Errorf("Informe o status para defini-lo manualmente")
+        }
+        updates["statusManual"] = *f.StatusManual
+    }
+    if len(updates) == 0 {
+        return nil, fmt.Errorf("Nenhum campo válido para atualização")
+    }
+    return updates, nil
+}
+
+// BulkUpdatePortals aplica os mesmos campos editáveis do PUT a várias linhas (admins e editores).
+// Corpo: {"ids": [...]} e/ou {"filter": {"referencia": ..., "esfera": ...}}, "fields" e "dryRun".
+// Com dryRun (no corpo ou na query) apenas retorna quantas linhas seriam afetadas. Para editores,
+// "enviar" não é aplicado: cada linha com valor diferente recebe uma solicitação de alteração.
+func (c *PortalController) BulkUpdatePortals(ctx *gin.Context) {
+    user, ok := requireRole(ctx, c.userService, "admin", "editor")
+    if !ok {
+        return
+    }
+    var req struct {
+        IDs    []string             `json:"ids"`
+        Filter model.PortalQuery    `json:"filter"`
+        Fields editablePortalFields `json:"fields"`
+        DryRun bool                 `json:"dryRun"`
+    }
+    if err := ctx.ShouldBindJSON(&req); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+        return
+    }
+    if v := ctx.Query("dryRun"); v != "" {
+        req.DryRun, _ = strconv.ParseBool(v)
+    }
+    updates, err := req.Fields.updates()
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        return
+    }
+    query := req.Filter
+    query.IDs = append(query.IDs, req.IDs...)
+
+    var requests []model.ChangeRequest
+    if enviar, ok := updates["enviar"].(bool); ok && c.requestService.RequiresApproval(user) {
+        delete(updates, "enviar")
+        if !query.HasFilter() {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": "informe ids ou ao menos um filtro"})
+            return
+        }
+        query.Sort, query.Limit, query.Offset = nil, 0, 0
+        if requests, err = c.requestService.RequestEnviarBulk(query, enviar, user, req.DryRun); err != nil {
+            writeChangeRequestError(ctx, err)
+            return
+        }
+    }
+
+    result := model.PortalBulkUpdateResult{DryRun: req.DryRun}
+    if len(updates) > 0 {
+        result, err = c.service.BulkUpdate(query, updates, user.ID, req.DryRun)
+    }
+    result.ChangeRequests = requests
+    switch {
+    case errors.Is(err, service.ErrInvalidBulkUpdate):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrDeliveryLocked):
+        ctx.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+    case err != nil:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar portais"})
+    default:
+        ctx.JSON(http.StatusOK, result)
+    }
 }
 
-// UpdatePortal atualiza campos editáveis do portal (somente admins)
+// UpdatePortal atualiza campos editáveis do portal (admins e editores). Para evitar sobrescrever
+// edições concorrentes, o cliente informa a versão lida no header If-Match (412 se divergir) ou no
+// campo "version" do corpo (409 se divergir); em ambos os casos a resposta traz o portal atual.
+// Alterações de "enviar" feitas por editores viram uma solicitação pendente de aprovação
+// (changeRequest na resposta; 202 quando era o único campo alterado).
 func (c *PortalController) UpdatePortal(ctx *gin.Context) {
     // Verificar autenticação e autorização
     userIDVal, exists := ctx.Get("userID")
@@ -74,50 +391,93 @@ func (c *PortalController) UpdatePortal(ctx *gin.Context) {
     }
 
     id := ctx.Param("id")
-    // Payload esperado: apenas campos editáveis
-    // Campos editáveis suportados
+    // Payload esperado: apenas campos editáveis (e a versão lida, opcional)
     var req struct {
-        ObservacaoTimeDados *string `json:"observacaoTimeDados"`
-        Enviar              *bool   `json:"enviar"`
-        Status              *string `json:"status"`
-        PulouCompetencia    *bool   `json:"pulouCompetencia"`
-        DefasagemNosDados   *bool   `json:"defasagemNosDados"`
-        NovosDados          *bool   `json:"novosDados"`
+        editablePortalFields
+        Version *int `json:"version"`
     }
     if bindErr := ctx.ShouldBindJSON(&req); bindErr != nil {
         ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
         return
     }
+    expectedVersion, ifMatchErr := parseIfMatch(ctx.GetHeader("If-Match"))
+    if ifMatchErr != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "If-Match inválido"})
+        return
+    }
+    conflictStatus := http.StatusPreconditionFailed
+    if expectedVersion == nil && req.Version != nil {
+        expectedVersion = req.Version
+        conflictStatus = http.StatusConflict
+    }
 
-    // Montar mapa de atualizações apenas com campos fornecidos
-    updates := bson.M{}
-    if req.ObservacaoTimeDados != nil { updates["observacaoTimeDados"] = *req.ObservacaoTimeDados }
-    if req.Enviar != nil { updates["enviar"] = *req.Enviar }
-    if req.PulouCompetencia != nil { updates["pulouCompetencia"] = *req.PulouCompetencia }
-    if req.DefasagemNosDados != nil { updates["defasagemNosDados"] = *req.DefasagemNosDados }
-    if req.NovosDados != nil { updates["novosDados"] = *req.NovosDados }
-    if req.Status != nil {
-        // Validar status permitido
-        allowed := map[string]bool{"OK": true, "WARNING": true, "ERROR": true}
-        if !allowed[*req.Status] {
-            ctx.JSON(http.StatusBadRequest, gin.H{"error": "Status inválido"})
+    updates, fieldsErr := req.updates()
+    if fieldsErr != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": fieldsErr.Error()})
+        return
+    }
+
+    // Editores não alteram "enviar" diretamente: a mudança vira uma solicitação de aprovação,
+    // criada só depois das verificações de versão/entrega e da gravação dos demais campos
+    enviar, requestEnviar := updates["enviar"].(bool)
+    requestEnviar = requestEnviar && c.requestService.RequiresApproval(currentUser)
+    if requestEnviar {
+        delete(updates, "enviar")
+        if checkErr := c.service.CheckEditable(id, expectedVersion); checkErr != nil {
+            c.writeUpdateError(ctx, id, checkErr, conflictStatus)
             return
         }
-        updates["status"] = *req.Status
     }
 
-    if len(updates) == 0 {
-        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Nenhum campo válido para atualização"})
-        return
+    // Atualizar campos
+    if len(updates) > 0 || !requestEnviar {
+        if updateErr := c.service.UpdatePortalFieldsMap(id, updates, currentUser.ID, expectedVersion); updateErr != nil {
+            c.writeUpdateError(ctx, id, updateErr, conflictStatus)
+            return
+        }
     }
 
-    // Atualizar campos
-    if updateErr := c.service.UpdatePortalFieldsMap(id, updates); updateErr != nil {
-        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar portal"})
-        return
+    var changeRequest *model.ChangeRequest
+    if requestEnviar {
+        if changeRequest, err = c.requestService.RequestEnviar(id, enviar, currentUser); err != nil {
+            writeChangeRequestError(ctx, err)
+            return
+        }
+        if len(updates) == 0 && changeRequest != nil {
+            current, _ := c.service.GetPortalByID(id)
+            current = c.withPending(current)
+            ctx.Header("ETag", portalETag(current))
+            ctx.JSON(http.StatusAccepted, gin.H{"success": true, "portal": current, "changeRequest": changeRequest})
+            return
+        }
     }
 
     // Retornar o portal atualizado
     updated, _ := c.service.GetPortalByID(id)
+    updated = c.withPending(updated)
+    ctx.Header("ETag", portalETag(updated))
+    if changeRequest != nil {
+        ctx.JSON(http.StatusOK, gin.H{"success": true, "portal": updated, "changeRequest": changeRequest})
+        return
+    }
     ctx.JSON(http.StatusOK, gin.H{"success": true, "portal": updated})
 }
+
+// writeUpdateError traduz os erros da edição de uma linha em status HTTP; no conflito de versão,
+// devolve a linha atual (com ETag) para o cliente refazer a edição
+func (c *PortalController) writeUpdateError(ctx *gin.Context, id string, err error, conflictStatus int) {
+    switch {
+    case errors.Is(err, service.ErrVersionConflict):
+        current, _ := c.service.GetPortalByID(id)
+        ctx.Header("ETag", portalETag(current))
+        ctx.JSON(conflictStatus, gin.H{"error": err.Error(), "portal": current})
+    case errors.Is(err, service.ErrDeliveryLocked):
+        ctx.JSON(http.StatusLocked, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrPortalNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrInvalidPortal):
+        ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+    default:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao atualizar portal"})
+    }
+}
@@ -0,0 +1,67 @@
+package controller
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// PortalHistoryController expõe o histórico de entregas mensais de cada portal e as previsões feitas sobre ele
+type PortalHistoryController struct {
+    service         service.PortalHistoryService
+    forecastService service.ForecastService
+}
+
+func NewPortalHistoryController(s service.PortalHistoryService, forecastSvc service.ForecastService) *PortalHistoryController {
+    return &PortalHistoryController{service: s, forecastService: forecastSvc}
+}
+
+func (c *PortalHistoryController) RegisterRoutes(r *gin.RouterGroup) {
+    // O gin exige o mesmo nome de parâmetro de /portals/:id; aqui ele é o nome do portal
+    r.GET("/portals/:id/history", c.GetHistory)
+    r.GET("/portals/:id/forecast", c.GetForecast)
+}
+
+// GetHistory retorna a identidade do portal e suas entregas ordenadas por competência
+func (c *PortalHistoryController) GetHistory(ctx *gin.Context) {
+    history, err := c.service.GetHistory(ctx.Param("id"))
+    if errors.Is(err, service.ErrPortalNotFound) {
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+        return
+    }
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar histórico do portal"})
+        return
+    }
+    ctx.JSON(http.StatusOK, history)
+}
+
+// GetForecast projeta a métrica (padrão volumetriaServicos) para as próximas competências.
+// Query params: metric e horizon (1 a 24, padrão 3).
+func (c *PortalHistoryController) GetForecast(ctx *gin.Context) {
+    horizon := 3
+    if v := ctx.Query("horizon"); v != "" {
+        h, err := strconv.Atoi(v)
+        if err != nil {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("horizon").Error()})
+            return
+        }
+        horizon = h
+    }
+    forecast, err := c.forecastService.Forecast(ctx.Param("id"), ctx.DefaultQuery("metric", "volumetriaServicos"), horizon)
+    switch {
+    case errors.Is(err, service.ErrInvalidForecast):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrPortalNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrInsufficientHistory):
+        ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+    case err != nil:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao calcular previsão"})
+    default:
+        ctx.JSON(http.StatusOK, forecast)
+    }
+}
@@ -0,0 +1,48 @@
+package controller
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// PortalSearchController expõe a busca textual nas linhas de portal e nos comentários
+type PortalSearchController struct {
+    service service.PortalSearchService
+}
+
+func NewPortalSearchController(s service.PortalSearchService) *PortalSearchController {
+    return &PortalSearchController{service: s}
+}
+
+func (c *PortalSearchController) RegisterRoutes(r *gin.RouterGroup) {
+    // Leitura pública, como a listagem de portais
+    r.GET("/portals/search", c.Search)
+}
+
+// Search busca q em portal, esfera, status, observação e comentários. Query params: q
+// (obrigatório; aceita "frases" e -exclusões) e limit (padrão 20, máximo 100).
+func (c *PortalSearchController) Search(ctx *gin.Context) {
+    limit := 0
+    if v := ctx.Query("limit"); v != "" {
+        parsed, err := strconv.Atoi(v)
+        if err != nil {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("limit").Error()})
+            return
+        }
+        limit = parsed
+    }
+    result, err := c.service.Search(ctx.Query("q"), limit)
+    if err != nil {
+        if errors.Is(err, service.ErrInvalidSearch) {
+            ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+            return
+        }
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar portais"})
+        return
+    }
+    ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,153 @@
+package controller
+
+import (
+    "errors"
+    "net/http"
+    "strconv"
+
+    "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "solid_react_golang_mongo_project/backend-go/middleware"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/service"
+)
+
+// RuleController expõe a administração das regras de validação de portais (somente admins)
+type RuleController struct {
+    ruleService service.RuleService
+    authService service.AuthService
+    userService service.UserService
+}
+
+func NewRuleController(ruleSvc service.RuleService, auth service.AuthService, userSvc service.UserService) *RuleController {
+    return &RuleController{ruleService: ruleSvc, authService: auth, userService: userSvc}
+}
+
+func (c *RuleController) RegisterRoutes(r *gin.RouterGroup) {
+    rulesRouter := r.Group("/admin/rules")
+    rulesRouter.Use(middleware.SessionAuthMiddleware(c.authService))
+    {
+        rulesRouter.GET("", c.ListRules)
+        rulesRouter.POST("", c.CreateRule)
+        rulesRouter.PUT("/:id", c.UpdateRule)
+        rulesRouter.DELETE("/:id", c.DeleteRule)
+        rulesRouter.GET("/versions/current", c.GetCurrentVersion)
+        rulesRouter.GET("/versions/:version", c.GetVersion)
+    }
+}
+
+// ListRules lista todas as regras (ativas e inativas)
+func (c *RuleController) ListRules(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+    rules, err := c.ruleService.ListRules()
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar regras"})
+        return
+    }
+    ctx.JSON(http.StatusOK, rules)
+}
+
+// CreateRule cria uma regra e publica uma nova versão do conjunto de regras
+func (c *RuleController) CreateRule(ctx *gin.Context) {
+    user, ok := requireRole(ctx, c.userService, "admin")
+    if !ok {
+        return
+    }
+    var rule model.ValidationRule
+    if err := ctx.ShouldBindJSON(&rule); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+        return
+    }
+    created, err := c.ruleService.CreateRule(rule, user.ID)
+    if err != nil {
+        c.writeRuleError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusCreated, created)
+}
+
+// UpdateRule substitui uma regra e publica uma nova versão do conjunto de regras
+func (c *RuleController) UpdateRule(ctx *gin.Context) {
+    user, ok := requireRole(ctx, c.userService, "admin")
+    if !ok {
+        return
+    }
+    id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+        return
+    }
+    var rule model.ValidationRule
+    if err := ctx.ShouldBindJSON(&rule); err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "Dados inválidos"})
+        return
+    }
+    updated, err := c.ruleService.UpdateRule(id, rule, user.ID)
+    if err != nil {
+        c.writeRuleError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, updated)
+}
+
+// DeleteRule remove uma regra e publica uma nova versão do conjunto de regras
+func (c *RuleController) DeleteRule(ctx *gin.Context) {
+    user, ok := requireRole(ctx, c.userService, "admin")
+    if !ok {
+        return
+    }
+    id, err := primitive.ObjectIDFromHex(ctx.Param("id"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+        return
+    }
+    if err := c.ruleService.DeleteRule(id, user.ID); err != nil {
+        c.writeRuleError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, gin.H{"success": true})
+}
+
+// GetCurrentVersion retorna o conjunto de regras vigente
+func (c *RuleController) GetCurrentVersion(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+    set, err := c.ruleService.CurrentRuleSet()
+    if err != nil {
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao buscar versão das regras"})
+        return
+    }
+    ctx.JSON(http.StatusOK, set)
+}
+
+// GetVersion retorna o conjunto de regras de uma versão (ver Portal.regrasVersao)
+func (c *RuleController) GetVersion(ctx *gin.Context) {
+    if _, ok := requireRole(ctx, c.userService, "admin"); !ok {
+        return
+    }
+    version, err := strconv.Atoi(ctx.Param("version"))
+    if err != nil {
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": errInvalidParam("version").Error()})
+        return
+    }
+    set, err := c.ruleService.GetRuleSet(version)
+    if err != nil {
+        c.writeRuleError(ctx, err)
+        return
+    }
+    ctx.JSON(http.StatusOK, set)
+}
+
+func (c *RuleController) writeRuleError(ctx *gin.Context, err error) {
+    switch {
+    case errors.Is(err, service.ErrInvalidRule):
+        ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+    case errors.Is(err, service.ErrRuleNotFound):
+        ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+    default:
+        ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Erro ao gravar regra"})
+    }
+}
@@ -6,10 +6,13 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"solid_react_golang_mongo_project/backend-go/config"
 	"solid_react_golang_mongo_project/backend-go/controller"
+	"solid_react_golang_mongo_project/backend-go/model"
 	"solid_react_golang_mongo_project/backend-go/repository"
 	"solid_react_golang_mongo_project/backend-go/service"
 
@@ -56,11 +59,30 @@ func main() {
 
 	// Escolher o repository de portais baseado na configuração
 	var portalRepo repository.PortalRepository
+	var portalHistoryRepo repository.PortalHistoryRepository
+	var deliveryRepo repository.DeliveryRepository
+	var manifestRepo repository.ManifestRepository
+	var portalChangeRepo repository.PortalChangeRepository
+	var portalCommentRepo repository.PortalCommentRepository
+	var changeRequestRepo repository.ChangeRequestRepository
+	var portalCatalogRepo repository.PortalCatalogRepository
+	var ruleRepo repository.RuleRepository
+	var migrationRepo repository.MigrationRepository
+	var dateNormalizationRepo repository.DateNormalizationRepository
 
 	switch {
 	case cfg.IsMock():
 		fmt.Println("Inicializando Mock Portal Repository...")
 		portalRepo = repository.NewMockPortalRepository()
+		portalHistoryRepo = repository.NewMockPortalHistoryRepository()
+		deliveryRepo = repository.NewMockDeliveryRepository()
+		manifestRepo = repository.NewMockManifestRepository()
+		portalChangeRepo = repository.NewMockPortalChangeRepository()
+		portalCommentRepo = repository.NewMockPortalCommentRepository()
+		changeRequestRepo = repository.NewMockChangeRequestRepository()
+		portalCatalogRepo = repository.NewMockPortalCatalogRepository()
+		ruleRepo = repository.NewMockRuleRepository()
+		migrationRepo = repository.NewMockMigrationRepository()
 		fmt.Println("Mock Portal Repository inicializado")
 case cfg.IsGCS():
     fmt.Println("Inicializando GCS Portal Repository...")
@@ -72,33 +94,159 @@ case cfg.IsGCS():
     // Temporariamente usando MongoDB até resolver problemas de conectividade do GCS
     fmt.Println("GCS ainda não implementado, usando MongoDB...")
     portalRepo = repository.NewPortalRepositoryDB(db)
+    portalHistoryRepo = repository.NewPortalHistoryRepositoryDB(db)
+    deliveryRepo = repository.NewDeliveryRepositoryDB(db)
+    manifestRepo = repository.NewManifestRepositoryDB(db)
+    portalChangeRepo = repository.NewPortalChangeRepositoryDB(db)
+    portalCommentRepo = repository.NewPortalCommentRepositoryDB(db)
+    changeRequestRepo = repository.NewChangeRequestRepositoryDB(db)
+    portalCatalogRepo = repository.NewPortalCatalogRepositoryDB(db)
+    ruleRepo = repository.NewRuleRepositoryDB(db)
+    migrationRepo = repository.NewMigrationRepositoryDB(db)
+    dateNormalizationRepo = repository.NewDateNormalizationRepositoryDB(db)
     fmt.Println("MongoDB Portal Repository inicializado (fallback do GCS)")
     default: // MongoDB
         fmt.Println("Inicializando MongoDB Portal Repository...")
         portalRepo = repository.NewPortalRepositoryDB(db)
+        portalHistoryRepo = repository.NewPortalHistoryRepositoryDB(db)
+        deliveryRepo = repository.NewDeliveryRepositoryDB(db)
+        manifestRepo = repository.NewManifestRepositoryDB(db)
+        portalChangeRepo = repository.NewPortalChangeRepositoryDB(db)
+        portalCommentRepo = repository.NewPortalCommentRepositoryDB(db)
+        changeRequestRepo = repository.NewChangeRequestRepositoryDB(db)
+        portalCatalogRepo = repository.NewPortalCatalogRepositoryDB(db)
+        ruleRepo = repository.NewRuleRepositoryDB(db)
+        migrationRepo = repository.NewMigrationRepositoryDB(db)
+        dateNormalizationRepo = repository.NewDateNormalizationRepositoryDB(db)
         fmt.Println("MongoDB Portal Repository inicializado")
 	}
 
 	// Inicializar services
 	fmt.Println("Inicializando services...")
 	userService := service.NewUserService(userRepo)
-	portalService := service.NewPortalService(portalRepo)
+	metricsService := service.NewMetricsService(cfg.MetricsTolerance)
+	portalHistoryService := service.NewPortalHistoryService(portalHistoryRepo, portalRepo)
+	statusClassifier := service.NewStatusClassifier(service.DefaultStatusThresholds())
+	ruleService := service.NewRuleService(ruleRepo)
+	lagService := service.NewLagService(portalRepo, cfg.ExpectedLagByEsfera, cfg.DefaultExpectedLag)
+	deliveryService := service.NewDeliveryService(deliveryRepo, portalRepo)
+	portalChangeService := service.NewPortalChangeService(portalChangeRepo)
+	portalCommentService := service.NewPortalCommentService(portalCommentRepo, portalRepo)
+	portalCatalogService := service.NewPortalCatalogService(portalCatalogRepo, cfg.RejectUnknownPortals)
+	portalService := service.NewPortalService(portalRepo, service.PortalServiceDeps{
+		Metrics:    metricsService,
+		History:    portalHistoryService,
+		Status:     statusClassifier,
+		Rules:      ruleService,
+		Lag:        lagService,
+		Deliveries: deliveryService,
+		Changes:    portalChangeService,
+		Catalog:    portalCatalogService,
+	})
+	portalImportService := service.NewPortalImportService(portalService, portalCatalogService)
+	portalCSVService := service.NewPortalCSVService(portalService, portalCatalogService)
+	deliveryExportService := service.NewDeliveryExportService(portalService)
+	manifestService := service.NewManifestService(manifestRepo, portalService, deliveryService)
+	changeRequestService := service.NewChangeRequestService(changeRequestRepo, portalRepo, portalService, deliveryService, cfg.EnviarApproverRoles)
+	forecastService := service.NewForecastService(portalHistoryService)
+	dashboardService := service.NewDashboardService(portalRepo)
+	deliveryDiffService := service.NewDeliveryDiffService(deliveryService, portalRepo)
+	portalSearchService := service.NewPortalSearchService(portalRepo, portalCommentRepo)
 	authService := service.NewAuthService(userRepo, sessionRepo)
+	migrationService := service.NewMigrationService(migrationRepo, service.DefaultMigrations(service.MigrationDeps{
+		Portals: portalRepo,
+		History: portalHistoryService,
+		Dates:   dateNormalizationRepo,
+		Logf: func(format string, args ...interface{}) {
+			fmt.Printf("  "+format+"\n", args...)
+		},
+	}))
 	fmt.Println("Services inicializados")
 
-	// Inicializar dados dos portais
-	fmt.Println("Inicializando dados dos portais...")
-	if err := portalService.InitializeData(); err != nil {
-		log.Printf("Aviso: Erro ao inicializar dados dos portais: %v", err)
+	// "go run main.go ensure-indexes" cria os índices e lista os dados que impedem os únicos
+	if len(os.Args) > 1 && os.Args[1] == "ensure-indexes" {
+		report, err := repository.EnsureIndexes(db)
+		if err != nil {
+			log.Fatalf("Erro ao criar índices: %v", err)
+		}
+		printIndexReport(report, false)
+		if report.HasProblems() {
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Migrações: "go run main.go migrate [up [versão] | down [quantidade] | status]"
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		runMigrate(migrationService, os.Args[2:])
+		return
+	}
+
+	// Migração: "go run main.go rebuild-history" constrói portal_identities/entregas a partir de portals
+	if len(os.Args) > 1 && os.Args[1] == "rebuild-history" {
+		report, err := portalHistoryService.Rebuild()
+		if err != nil {
+			log.Fatalf("Erro ao reconstruir histórico dos portais: %v", err)
+		}
+		fmt.Printf("Histórico reconstruído: %d linhas, %d entregas, %d portais\n", report.ProcessedRows, report.Entregas, report.Portais)
+		for _, skipped := range report.Skipped {
+			fmt.Printf("  ignorada: %s\n", skipped)
+		}
+		return
+	}
+
+	// Migração: "go run main.go normalize-dates" regrava competências e datas no formato ordenável
+	if len(os.Args) > 1 && os.Args[1] == "normalize-dates" {
+		if cfg.IsMock() {
+			fmt.Println("Fonte de dados mock: não há documentos para normalizar")
+			return
+		}
+		report, err := repository.NewDateNormalizationRepositoryDB(db).NormalizeDates()
+		for _, c := range report.Colecoes {
+			fmt.Printf("%s: %d documentos, %d atualizados, %d valores inválidos\n", c.Colecao, c.Documentos, c.Atualizados, len(c.Invalidos))
+			for _, invalid := range c.Invalidos {
+				fmt.Printf("  inválido: %s\n", invalid)
+			}
+		}
+		if err != nil {
+			log.Fatalf("Erro ao normalizar datas: %v", err)
+		}
+		return
+	}
+
+	// Aplicar migrações pendentes (seed incluído). No modo mock schema_migrations fica em memória,
+	// então o seed e o histórico são montados a cada inicialização.
+	fmt.Println("Aplicando migrações pendentes...")
+	// Uma migração que falha interrompe a inicialização: o servidor não sobe com o banco pela metade
+	applied, err := migrationService.Up(0)
+	if err != nil {
+		log.Fatalf("Erro ao aplicar migrações (%d aplicadas antes da falha): %v", len(applied), err)
+	}
+	fmt.Printf("Migrações aplicadas: %d\n", len(applied))
+
+	// Índices do MongoDB (únicos, TTL das sessões e texto da busca). Usuários e sessões ficam
+	// no MongoDB mesmo com DATA_SOURCE=mock.
+	fmt.Println("Criando índices do MongoDB...")
+	if report, err := repository.EnsureIndexes(db); err != nil {
+		log.Printf("Aviso: Erro ao criar índices: %v", err)
 	} else {
-		fmt.Println("Dados dos portais inicializados com sucesso")
+		printIndexReport(report, true)
 	}
 
 	// Inicializar controllers
 	fmt.Println("Inicializando controllers...")
     userController := controller.NewUserController(userService, authService)
-    portalController := controller.NewPortalController(portalService, authService, userService)
+    portalController := controller.NewPortalController(portalService, lagService, portalChangeService, portalCommentService, changeRequestService, authService, userService)
     authController := controller.NewAuthController(authService)
+    portalHistoryController := controller.NewPortalHistoryController(portalHistoryService, forecastService)
+    ruleController := controller.NewRuleController(ruleService, authService, userService)
+    portalCommentController := controller.NewPortalCommentController(portalCommentService, authService, userService)
+    changeRequestController := controller.NewChangeRequestController(changeRequestService, authService, userService)
+    portalCatalogController := controller.NewPortalCatalogController(portalCatalogService, authService, userService)
+    dashboardController := controller.NewDashboardController(dashboardService)
+    portalSearchController := controller.NewPortalSearchController(portalSearchService)
+    deliveryController := controller.NewDeliveryController(deliveryService, manifestService, deliveryDiffService, authService, userService)
+    importExportController := controller.NewImportExportController(portalImportService, portalCSVService, deliveryExportService, authService, userService)
 	fmt.Println("Controllers inicializados")
 
 	// Configurar rotas com Gin
@@ -107,9 +255,9 @@ case cfg.IsGCS():
 	// Middleware CORS global com Gin
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"},
-		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization", "If-Match"},
+		ExposeHeaders:    []string{"Content-Length", "ETag"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -136,7 +284,16 @@ case cfg.IsGCS():
 	apiRouter := router.Group("/api")
 	userController.RegisterRoutes(apiRouter)
 	portalController.RegisterRoutes(apiRouter)
+	portalHistoryController.RegisterRoutes(apiRouter)
+	deliveryController.RegisterRoutes(apiRouter)
+	dashboardController.RegisterRoutes(apiRouter)
+	portalSearchController.RegisterRoutes(apiRouter)
+	portalCommentController.RegisterRoutes(apiRouter)
+	changeRequestController.RegisterRoutes(apiRouter)
+	portalCatalogController.RegisterRoutes(apiRouter)
+	ruleController.RegisterRoutes(apiRouter)
 	authController.RegisterRoutes(apiRouter)
+	importExportController.RegisterRoutes(apiRouter)
 
 	// Configurar e iniciar servidor Gin
 	fmt.Println("Servidor iniciado em http://0.0.0.0:8081")
@@ -168,3 +325,82 @@ func corsMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(w, r)
 	})
 }
+
+// runMigrate executa o subcomando migrate: sem argumentos ou "up" aplica as pendentes (até a
+// versão informada), "down" reverte as últimas (1 por padrão) e "status" lista a situação
+func runMigrate(migrationService service.MigrationService, args []string) {
+	action := "up"
+	if len(args) > 0 {
+		action = args[0]
+	}
+	number := 0
+	if len(args) > 1 {
+		n, err := strconv.Atoi(args[1])
+		if err != nil || n <= 0 {
+			log.Fatalf("Número inválido para migrate %s: %s", action, args[1])
+		}
+		number = n
+	}
+
+	switch action {
+	case "up":
+		applied, err := migrationService.Up(number)
+		for _, m := range applied {
+			fmt.Printf("aplicada: %d %s\n", m.Version, m.Nome)
+		}
+		if err != nil {
+			log.Fatalf("Erro ao aplicar migrações: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("Nenhuma migração pendente")
+		}
+	case "down":
+		if number == 0 {
+			number = 1
+		}
+		reverted, err := migrationService.Down(number)
+		for _, m := range reverted {
+			fmt.Printf("revertida: %d %s\n", m.Version, m.Nome)
+		}
+		if err != nil {
+			log.Fatalf("Erro ao reverter migrações: %v", err)
+		}
+	case "status":
+		status, err := migrationService.Status()
+		if err != nil {
+			log.Fatalf("Erro ao consultar migrações: %v", err)
+		}
+		for _, m := range status {
+			situacao := "pendente"
+			if m.Aplicada {
+				situacao = "aplicada em " + m.AplicadaEm.Format(time.RFC3339)
+			}
+			if m.SentidoUnico {
+				situacao += " (sentido único: down só remove o registro)"
+			}
+			fmt.Printf("%4d %-28s %s\n", m.Version, m.Nome, situacao)
+		}
+	default:
+		log.Fatalf("Uso: migrate [up [versão] | down [quantidade] | status]")
+	}
+}
+
+// printIndexReport mostra os índices não criados, com os valores repetidos; sem onlyProblems,
+// lista também os criados
+func printIndexReport(report model.IndexReport, onlyProblems bool) {
+	for _, idx := range report.Indices {
+		switch {
+		case idx.Criado:
+			if !onlyProblems {
+				fmt.Printf("%s.%s: ok\n", idx.Colecao, idx.Nome)
+			}
+		case idx.Erro != "":
+			fmt.Printf("Aviso: %s.%s não criado: %s\n", idx.Colecao, idx.Nome, idx.Erro)
+		default:
+			fmt.Printf("Aviso: %s.%s não criado: %d valores repetidos\n", idx.Colecao, idx.Nome, len(idx.Conflitos))
+			for _, c := range idx.Conflitos {
+				fmt.Printf("  %s (%d documentos): %s\n", c.Chave, c.Documentos, strings.Join(c.IDs, ", "))
+			}
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados de uma solicitação de alteração
+const (
+	ChangeRequestPending  = "PENDING"
+	ChangeRequestApproved = "APPROVED"
+	ChangeRequestRejected = "REJECTED"
+	// ChangeRequestFailed é uma solicitação aprovada cuja alteração não pôde ser aplicada (ex.:
+	// a linha foi editada depois do pedido); o motivo fica em Motivo
+	ChangeRequestFailed = "FAILED"
+)
+
+// ChangeRequest é uma alteração de "enviar" feita por um editor que aguarda a confirmação
+// de uma segunda pessoa antes de ser aplicada ao portal
+type ChangeRequest struct {
+	ID            primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	PortalID      string              `json:"portalId" bson:"portalId"`
+	Portal        string              `json:"portal" bson:"portal"`
+	Referencia    string              `json:"referencia" bson:"referencia"`
+	Campo         string              `json:"campo" bson:"campo"`
+	ValorAtual    bool                `json:"valorAtual" bson:"valorAtual"`
+	ValorProposto bool                `json:"valorProposto" bson:"valorProposto"`
+	Status        string              `json:"status" bson:"status"`
+	SolicitadoPor primitive.ObjectID  `json:"solicitadoPor" bson:"solicitadoPor"`
+	SolicitadoEm  time.Time           `json:"solicitadoEm" bson:"solicitadoEm"`
+	DecididoPor   *primitive.ObjectID `json:"decididoPor,omitempty" bson:"decididoPor,omitempty"`
+	DecididoEm    *time.Time          `json:"decididoEm,omitempty" bson:"decididoEm,omitempty"`
+	Motivo        string              `json:"motivo,omitempty" bson:"motivo,omitempty"`
+	// VersaoPortal é a versão da linha quando a solicitação foi criada: a aprovação só é aplicada
+	// se a linha não mudou desde então (nil em solicitações antigas)
+	VersaoPortal *int `json:"versaoPortal,omitempty" bson:"versaoPortal,omitempty"`
+}
+
+// ChangeRequestQuery filtra as solicitações; campos vazios não filtram
+type ChangeRequestQuery struct {
+	Status    string
+	PortalIDs []string
+}
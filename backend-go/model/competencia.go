@@ -0,0 +1,209 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Competencia representa o mês/ano de referência dos dados ("08/2024").
+//
+// Em JSON é serializada como "MM/YYYY"; no MongoDB, como "YYYY-MM", para que ordenação e
+// consultas por intervalo sigam a ordem cronológica. O valor zero (vazio) é gravado como null.
+// A planilha do cliente usa "SEM NOVOS DADOS" no lugar da competência quando o portal não
+// enviou dados novos; esse marcador é preservado (CompetenciaSemNovosDados).
+type Competencia struct {
+	Ano int
+	Mes int
+
+	semNovosDados bool
+	invalido      invalidBSON
+}
+
+// SemNovosDadosTexto é o texto usado pelo cliente no lugar da competência
+const SemNovosDadosTexto = "SEM NOVOS DADOS"
+
+// CompetenciaSemNovosDados é o marcador de portal sem dados novos na entrega
+var CompetenciaSemNovosDados = Competencia{semNovosDados: true}
+
+var (
+	competenciaMesAno = regexp.MustCompile(`^\s*(\d{1,2})[/-](\d{4})\s*$`)
+	competenciaAnoMes = regexp.MustCompile(`^\s*(\d{4})-(\d{1,2})\s*$`)
+)
+
+// ParseCompetencia aceita "8/2024", "08/2024", "08-2024" e "2024-08", além do marcador
+// "SEM NOVOS DADOS". Texto vazio resulta na competência zero.
+func ParseCompetencia(s string) (Competencia, error) {
+	if strings.TrimSpace(s) == "" {
+		return Competencia{}, nil
+	}
+	if strings.EqualFold(strings.TrimSpace(s), SemNovosDadosTexto) {
+		return CompetenciaSemNovosDados, nil
+	}
+	var mes, ano string
+	if m := competenciaMesAno.FindStringSubmatch(s); m != nil {
+		mes, ano = m[1], m[2]
+	} else if m := competenciaAnoMes.FindStringSubmatch(s); m != nil {
+		ano, mes = m[1], m[2]
+	} else {
+		return Competencia{}, fmt.Errorf("competência inválida: %q", s)
+	}
+	c := Competencia{}
+	c.Ano, _ = strconv.Atoi(ano)
+	c.Mes, _ = strconv.Atoi(mes)
+	if err := c.Validate(); err != nil {
+		return Competencia{}, fmt.Errorf("%v: %q", err, s)
+	}
+	return c, nil
+}
+
+// Validate confere mês (1 a 12) e ano (1900 a 9999) de uma competência preenchida
+func (c Competencia) Validate() error {
+	if c.IsZero() || c.semNovosDados {
+		return nil
+	}
+	if c.Mes < 1 || c.Mes > 12 {
+		return fmt.Errorf("mês inválido na competência")
+	}
+	if c.Ano < 1900 || c.Ano > 9999 {
+		return fmt.Errorf("ano inválido na competência")
+	}
+	return nil
+}
+
+// Index converte a competência em um número sequencial de meses (útil para diferenças e ordenação)
+func (c Competencia) Index() int {
+	return c.Ano*12 + c.Mes - 1
+}
+
+// AddMonths desloca a competência em n meses (n pode ser negativo)
+func (c Competencia) AddMonths(n int) Competencia {
+	idx := c.Index() + n
+	return Competencia{Ano: idx / 12, Mes: idx%12 + 1}
+}
+
+func (c Competencia) Before(other Competencia) bool {
+	return c.Index() < other.Index()
+}
+
+func (c Competencia) IsZero() bool {
+	return c.Ano == 0 && c.Mes == 0 && !c.semNovosDados
+}
+
+// SemNovosDados indica o marcador "SEM NOVOS DADOS"
+func (c Competencia) SemNovosDados() bool {
+	return c.semNovosDados
+}
+
+// HasMonth indica se a competência identifica um mês (não é vazia nem o marcador)
+func (c Competencia) HasMonth() bool {
+	return !c.IsZero() && !c.semNovosDados
+}
+
+// Compare ordena cronologicamente: vazia antes de qualquer mês e o marcador depois (mesma
+// ordem do MongoDB, onde null < "YYYY-MM" < "SEM NOVOS DADOS")
+func (c Competencia) Compare(other Competencia) int {
+	a, b := c.sortKey(), other.sortKey()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (c Competencia) sortKey() int {
+	switch {
+	case c.IsZero():
+		return -1
+	case c.semNovosDados:
+		return int(^uint(0) >> 1)
+	}
+	return c.Index()
+}
+
+// String formata no padrão "MM/YYYY"
+func (c Competencia) String() string {
+	if c.IsZero() {
+		return ""
+	}
+	if c.semNovosDados {
+		return SemNovosDadosTexto
+	}
+	return fmt.Sprintf("%02d/%04d", c.Mes, c.Ano)
+}
+
+// MarshalText serializa como "MM/YYYY" (usado também pelo encoding/json)
+func (c Competencia) MarshalText() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalText aceita os formatos de ParseCompetencia
+func (c *Competencia) UnmarshalText(text []byte) error {
+	parsed, err := ParseCompetencia(string(text))
+	if err != nil {
+		return err
+	}
+	*c = parsed
+	return nil
+}
+
+// storageString é a forma gravada no MongoDB ("YYYY-MM")
+func (c Competencia) storageString() string {
+	if c.semNovosDados {
+		return SemNovosDadosTexto
+	}
+	return fmt.Sprintf("%04d-%02d", c.Ano, c.Mes)
+}
+
+func (c Competencia) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if c.invalido.set() {
+		return c.invalido.value()
+	}
+	if c.IsZero() {
+		return bson.TypeNull, nil, nil
+	}
+	return bson.MarshalValue(c.storageString())
+}
+
+// UnmarshalBSONValue lê o formato atual e também os anteriores à normalização: texto em
+// qualquer formato aceito por ParseCompetencia e o subdocumento {ano, mes}. Um valor que não
+// pode ser interpretado não falha a leitura do documento: a competência fica vazia e guarda o
+// valor original (Invalido), que é regravado como estava até a normalização das datas.
+func (c *Competencia) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeNull, bson.TypeUndefined:
+		*c = Competencia{}
+		return nil
+	case bson.TypeString:
+		if err := c.UnmarshalText([]byte(raw.StringValue())); err == nil {
+			return nil
+		}
+	case bson.TypeEmbeddedDocument:
+		var doc struct {
+			Ano int `bson:"ano"`
+			Mes int `bson:"mes"`
+		}
+		if err := raw.Unmarshal(&doc); err == nil {
+			parsed := Competencia{Ano: doc.Ano, Mes: doc.Mes}
+			if parsed.Validate() == nil {
+				*c = parsed
+				return nil
+			}
+		}
+	}
+	*c = Competencia{invalido: newInvalidBSON(raw)}
+	return nil
+}
+
+// Invalido devolve o valor gravado que não pôde ser interpretado na leitura do MongoDB (vazio
+// quando a competência é válida)
+func (c Competencia) Invalido() string {
+	return c.invalido.String()
+}
@@ -0,0 +1,30 @@
+package model
+
+// DashboardSummary consolida as linhas de portal de uma entrega (ou de todas, sem Referencia)
+// para a tela de listagem: contagens, somas de volume, médias dos índices e os portais com
+// competência pulada ou defasagem acima da esperada
+type DashboardSummary struct {
+	Referencia             string            `json:"referencia"`
+	Total                  int               `json:"total"`
+	PorStatus              map[string]int    `json:"porStatus"`
+	PorEsfera              map[string]int    `json:"porEsfera"`
+	Enviar                 int               `json:"enviar"`
+	NaoEnviar              int               `json:"naoEnviar"`
+	SomaVolumeFonte        int64             `json:"somaVolumeFonte"`
+	SomaVolumetriaDados    int64             `json:"somaVolumetriaDados"`
+	SomaVolumetriaServicos int64             `json:"somaVolumetriaServicos"`
+	MediaIndiceDados       float64           `json:"mediaIndiceDados"`
+	MediaIndiceServicos    float64           `json:"mediaIndiceServicos"`
+	PulouCompetencia       []DashboardPortal `json:"pulouCompetencia"`
+	DefasagemNosDados      []DashboardPortal `json:"defasagemNosDados"`
+}
+
+// DashboardPortal identifica uma linha destacada no dashboard
+type DashboardPortal struct {
+	ID                    string      `json:"_id" bson:"_id"`
+	Portal                string      `json:"portal" bson:"portal"`
+	Esfera                string      `json:"esfera" bson:"esfera"`
+	MesAnoReferencia      Competencia `json:"mesAnoReferencia" bson:"mesAnoReferencia"`
+	CompetenciasFaltantes []string    `json:"competenciasFaltantes,omitempty" bson:"competenciasFaltantes,omitempty"`
+	DefasagemMeses        *int        `json:"defasagemMeses,omitempty" bson:"defasagemMeses,omitempty"`
+}
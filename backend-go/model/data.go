@@ -0,0 +1,192 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// Data é uma data de calendário, como a data de entrega ("10/11/2025"). Quando a planilha
+// informa apenas o mês de envio ("11/2025"), Dia fica 0 (precisão de mês).
+//
+// Em JSON é serializada como "dd/MM/yyyy" (ou "MM/yyyy"); no MongoDB, como "yyyy-MM-dd" (ou
+// "yyyy-MM"), que ordena cronologicamente. O valor zero (vazio) é gravado como null.
+type Data struct {
+	Ano int
+	Mes int
+	Dia int
+
+	invalido invalidBSON
+}
+
+var (
+	dataDiaMesAno = regexp.MustCompile(`^\s*(\d{1,2})[/.-](\d{1,2})[/.-](\d{2,4})\s*$`)
+	dataISO       = regexp.MustCompile(`^\s*(\d{4})-(\d{1,2})-(\d{1,2})(?:[T ].*)?$`)
+)
+
+// ParseData aceita "dd/MM/yyyy" (também com "-" ou "." e anos com 2 ou 3 dígitos, como os
+// formatos dd/MM/yy e dd/MM/yyy do importador), "yyyy-MM-dd" (com ou sem horário) e os
+// formatos de mês de ParseCompetencia ("11/2025"). Texto vazio resulta na data zero.
+func ParseData(s string) (Data, error) {
+	if strings.TrimSpace(s) == "" {
+		return Data{}, nil
+	}
+	var d Data
+	if m := dataDiaMesAno.FindStringSubmatch(s); m != nil {
+		d.Dia, _ = strconv.Atoi(m[1])
+		d.Mes, _ = strconv.Atoi(m[2])
+		d.Ano, _ = strconv.Atoi(m[3])
+		if len(m[3]) < 4 {
+			d.Ano += 2000
+		}
+	} else if m := dataISO.FindStringSubmatch(s); m != nil {
+		d.Ano, _ = strconv.Atoi(m[1])
+		d.Mes, _ = strconv.Atoi(m[2])
+		d.Dia, _ = strconv.Atoi(m[3])
+	} else if c, err := ParseCompetencia(s); err == nil && c.HasMonth() {
+		d = Data{Ano: c.Ano, Mes: c.Mes}
+	} else {
+		return Data{}, fmt.Errorf("data inválida: %q", s)
+	}
+	if err := d.Validate(); err != nil {
+		return Data{}, fmt.Errorf("%v: %q", err, s)
+	}
+	return d, nil
+}
+
+// DataFromTime converte um horário na data correspondente
+func DataFromTime(t time.Time) Data {
+	return Data{Ano: t.Year(), Mes: int(t.Month()), Dia: t.Day()}
+}
+
+// Validate confere se a data preenchida existe no calendário
+func (d Data) Validate() error {
+	if d.IsZero() {
+		return nil
+	}
+	if err := d.Competencia().Validate(); err != nil {
+		return fmt.Errorf("data inválida")
+	}
+	if d.Dia < 0 || d.Dia > 0 && d.time().Day() != d.Dia {
+		return fmt.Errorf("dia inválido na data")
+	}
+	return nil
+}
+
+func (d Data) IsZero() bool {
+	return d.Ano == 0 && d.Mes == 0 && d.Dia == 0
+}
+
+// Competencia é o mês/ano da data
+func (d Data) Competencia() Competencia {
+	if d.IsZero() {
+		return Competencia{}
+	}
+	return Competencia{Ano: d.Ano, Mes: d.Mes}
+}
+
+// Next é o primeiro valor posterior à data: o dia seguinte ou, com precisão de mês, o mês
+// seguinte (limite exclusivo nas consultas por intervalo)
+func (d Data) Next() Data {
+	if d.Dia == 0 {
+		c := d.Competencia().AddMonths(1)
+		return Data{Ano: c.Ano, Mes: c.Mes}
+	}
+	return DataFromTime(d.time().AddDate(0, 0, 1))
+}
+
+// Compare ordena cronologicamente; a data com precisão de mês vem antes dos dias do mês
+func (d Data) Compare(other Data) int {
+	a, b := d.sortKey(), other.sortKey()
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (d Data) sortKey() int {
+	return d.Ano*10000 + d.Mes*100 + d.Dia
+}
+
+func (d Data) time() time.Time {
+	return time.Date(d.Ano, time.Month(d.Mes), d.Dia, 0, 0, 0, 0, time.UTC)
+}
+
+// String formata como "dd/MM/yyyy" ("MM/yyyy" com precisão de mês)
+func (d Data) String() string {
+	switch {
+	case d.IsZero():
+		return ""
+	case d.Dia == 0:
+		return fmt.Sprintf("%02d/%04d", d.Mes, d.Ano)
+	}
+	return fmt.Sprintf("%02d/%02d/%04d", d.Dia, d.Mes, d.Ano)
+}
+
+// MarshalText serializa como "dd/MM/yyyy" (usado também pelo encoding/json)
+func (d Data) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText aceita os formatos de ParseData
+func (d *Data) UnmarshalText(text []byte) error {
+	parsed, err := ParseData(string(text))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// storageString é a forma gravada no MongoDB ("yyyy-MM-dd" ou "yyyy-MM")
+func (d Data) storageString() string {
+	if d.Dia == 0 {
+		return fmt.Sprintf("%04d-%02d", d.Ano, d.Mes)
+	}
+	return fmt.Sprintf("%04d-%02d-%02d", d.Ano, d.Mes, d.Dia)
+}
+
+func (d Data) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if d.invalido.set() {
+		return d.invalido.value()
+	}
+	if d.IsZero() {
+		return bson.TypeNull, nil, nil
+	}
+	return bson.MarshalValue(d.storageString())
+}
+
+// UnmarshalBSONValue lê o formato atual, textos anteriores à normalização ("dd/MM/yyyy",
+// "MM/yyyy"...) e datas BSON. Como em Competencia, um valor que não pode ser interpretado
+// deixa a data vazia e guarda o valor original (Invalido), sem falhar a leitura do documento.
+func (d *Data) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	raw := bson.RawValue{Type: t, Value: data}
+	switch t {
+	case bson.TypeNull, bson.TypeUndefined:
+		*d = Data{}
+		return nil
+	case bson.TypeString:
+		if err := d.UnmarshalText([]byte(raw.StringValue())); err == nil {
+			return nil
+		}
+	case bson.TypeDateTime:
+		*d = DataFromTime(raw.Time().UTC())
+		return nil
+	}
+	*d = Data{invalido: newInvalidBSON(raw)}
+	return nil
+}
+
+// Invalido devolve o valor gravado que não pôde ser interpretado na leitura do MongoDB (vazio
+// quando a data é válida)
+func (d Data) Invalido() string {
+	return d.invalido.String()
+}
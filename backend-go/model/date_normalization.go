@@ -0,0 +1,16 @@
+package model
+
+// DateNormalizationReport resume a migração que regrava competências e datas no formato
+// ordenável ("YYYY-MM" e "yyyy-MM-dd")
+type DateNormalizationReport struct {
+	Colecoes []DateNormalizationCollection `json:"colecoes"`
+}
+
+// DateNormalizationCollection é o resultado da migração em uma coleção. Valores que não puderam
+// ser interpretados são movidos para "valoresInvalidos.<campo>" e listados em Invalidos.
+type DateNormalizationCollection struct {
+	Colecao     string   `json:"colecao"`
+	Documentos  int      `json:"documentos"`
+	Atualizados int      `json:"atualizados"`
+	Invalidos   []string `json:"invalidos,omitempty"`
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Estados do ciclo de vida de uma entrega (Referencia)
+const (
+	DeliveryOpen     = "OPEN"
+	DeliveryInReview = "IN_REVIEW"
+	DeliveryClosed   = "CLOSED"
+	DeliverySent     = "SENT"
+)
+
+// DeliveryTransitions lista, para cada estado, os estados seguintes permitidos
+var DeliveryTransitions = map[string][]string{
+	DeliveryOpen:     {DeliveryInReview},
+	DeliveryInReview: {DeliveryOpen, DeliveryClosed},
+	DeliveryClosed:   {DeliveryInReview, DeliverySent},
+	DeliverySent:     {},
+}
+
+// Delivery é uma entrega ao cliente, identificada pela Referencia das linhas de portal
+// (ex.: "10-11-2024"). Com a entrega CLOSED ou SENT, as linhas ficam somente leitura.
+type Delivery struct {
+	Referencia   string               `json:"referencia" bson:"_id"`
+	DataEntrega  Data                 `json:"dataEntrega,omitempty" bson:"dataEntrega,omitempty"`
+	Status       string               `json:"status" bson:"status"`
+	Historico    []DeliveryTransition `json:"historico" bson:"historico"`
+	CriadoEm     time.Time            `json:"criadoEm" bson:"criadoEm"`
+	AtualizadoEm time.Time            `json:"atualizadoEm" bson:"atualizadoEm"`
+}
+
+// Locked indica se as linhas de portal da entrega não podem mais ser alteradas
+func (d Delivery) Locked() bool {
+	return d.Status == DeliveryClosed || d.Status == DeliverySent
+}
+
+// DeliveryTransition registra uma mudança de estado e quem a fez
+type DeliveryTransition struct {
+	De     string             `json:"de" bson:"de"`
+	Para   string             `json:"para" bson:"para"`
+	UserID primitive.ObjectID `json:"userId" bson:"userId"`
+	Em     time.Time          `json:"em" bson:"em"`
+}
+
+// DeliverySummary resume as linhas de portal de uma entrega
+type DeliverySummary struct {
+	Referencia string         `json:"referencia"`
+	Status     string         `json:"status"`
+	Total      int            `json:"total"`
+	PorStatus  map[string]int `json:"porStatus"`
+	Enviar     int            `json:"enviar"`
+	NaoEnviar  int            `json:"naoEnviar"`
+}
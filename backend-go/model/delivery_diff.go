@@ -0,0 +1,45 @@
+package model
+
+// DeliveryDiff compara as linhas de duas entregas (From → To), juntadas pelo nome do portal
+type DeliveryDiff struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+	// LimiarPercentual é a variação mínima (em %, sobre o valor de From) para um volume ser listado
+	LimiarPercentual float64                `json:"limiarPercentual"`
+	PortaisFrom      int                    `json:"portaisFrom"`
+	PortaisTo        int                    `json:"portaisTo"`
+	Novos            []DeliveryDiffPortal   `json:"novos"`
+	Removidos        []DeliveryDiffPortal   `json:"removidos"`
+	MudancasStatus   []DeliveryStatusChange `json:"mudancasStatus"`
+	VariacoesVolume  []DeliveryVolumeChange `json:"variacoesVolume"`
+}
+
+// DeliveryDiffPortal é um portal presente em apenas uma das entregas
+type DeliveryDiffPortal struct {
+	ID                 string      `json:"_id"`
+	Portal             string      `json:"portal"`
+	Esfera             string      `json:"esfera"`
+	MesAnoReferencia   Competencia `json:"mesAnoReferencia"`
+	Status             string      `json:"status"`
+	VolumetriaServicos int         `json:"volumetriaServicos"`
+}
+
+// DeliveryStatusChange registra a transição de status de um portal entre as entregas
+type DeliveryStatusChange struct {
+	Portal string `json:"portal"`
+	Esfera string `json:"esfera"`
+	De     string `json:"de"`
+	Para   string `json:"para"`
+}
+
+// DeliveryVolumeChange registra a variação de um campo de volume acima do limiar. Percentual é
+// nil quando o valor em From é zero.
+type DeliveryVolumeChange struct {
+	Portal     string   `json:"portal"`
+	Esfera     string   `json:"esfera"`
+	Campo      string   `json:"campo"`
+	De         int      `json:"de"`
+	Para       int      `json:"para"`
+	Delta      int      `json:"delta"`
+	Percentual *float64 `json:"percentual"`
+}
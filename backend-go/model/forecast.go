@@ -0,0 +1,30 @@
+package model
+
+// ForecastObservation é um ponto da série histórica usada no ajuste. Competências sem
+// entrega são interpoladas linearmente para manter a série mensal regular.
+type ForecastObservation struct {
+	Competencia  string  `json:"competencia"`
+	Value        float64 `json:"value"`
+	Interpolated bool    `json:"interpolated,omitempty"`
+}
+
+// ForecastPrediction é o valor previsto para uma competência futura com o intervalo de confiança
+type ForecastPrediction struct {
+	Competencia string  `json:"competencia"`
+	Value       float64 `json:"value"`
+	Lower       float64 `json:"lower"`
+	Upper       float64 `json:"upper"`
+}
+
+// Forecast é a resposta de GET /api/portals/{portal}/forecast
+type Forecast struct {
+	Portal          string                `json:"portal"`
+	Metric          string                `json:"metric"`
+	Method          string                `json:"method"` // "holt-winters" ou "linear-regression"
+	SeasonLength    int                   `json:"seasonLength,omitempty"`
+	Parameters      map[string]float64    `json:"parameters,omitempty"`
+	RMSE            float64               `json:"rmse"`
+	ConfidenceLevel float64               `json:"confidenceLevel"`
+	History         []ForecastObservation `json:"history"`
+	Predictions     []ForecastPrediction  `json:"predictions"`
+}
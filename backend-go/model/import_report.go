@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// ImportOptions controla a importação da planilha (equivalente às flags do script Node).
+type ImportOptions struct {
+	HeaderRow int    `json:"headerRow"` // linha do cabeçalho, 1-indexada (padrão 2)
+	DateFmt   string `json:"dateFmt"`   // dd/MM/yyyy (padrão), dd/MM/yy ou dd/MM/yyy
+	DryRun    bool   `json:"dryRun"`    // apenas gera o relatório, sem gravar
+}
+
+// ImportSheetReport descreve o mapeamento detectado e o resultado de uma aba.
+type ImportSheetReport struct {
+	Sheet         string             `json:"sheet"`
+	HeaderRow     int                `json:"headerRow"`
+	DetectedMap   map[string]*string `json:"detected_map"`
+	ProcessedRows int                `json:"processedRows"`
+	UniqueIDCount int                `json:"uniqueIdCount"`
+	Upserts       int                `json:"upserts"`
+	Warnings      []string           `json:"warnings,omitempty"`
+	Errors        []CSVRowError      `json:"errors,omitempty"`
+}
+
+// CSVRowError identifica uma linha do arquivo que não pôde ser interpretada.
+type CSVRowError struct {
+	Line  int    `json:"line"`
+	Error string `json:"error"`
+}
+
+type ImportTotals struct {
+	ProcessedRows int `json:"processedRows"`
+	Upserts       int `json:"upserts"`
+}
+
+// ImportReport é o relatório por aba no mesmo formato de scripts/last_import_map.json.
+type ImportReport struct {
+	File        string              `json:"file"`
+	HeaderRow   int                 `json:"headerRow"`
+	DryRun      bool                `json:"dryRun"`
+	Sheets      []ImportSheetReport `json:"sheets"`
+	Totals      ImportTotals        `json:"totals"`
+	GeneratedAt time.Time           `json:"generatedAt"`
+}
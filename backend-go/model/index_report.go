@@ -0,0 +1,34 @@
+package model
+
+// IndexReport resume a criação dos índices do MongoDB feita na inicialização (ou por
+// "ensure-indexes")
+type IndexReport struct {
+	Indices []IndexResult `json:"indices"`
+}
+
+// IndexResult é a situação de um índice. Um índice único com documentos em conflito não é
+// criado; os conflitos são listados para correção manual.
+type IndexResult struct {
+	Colecao   string          `json:"colecao"`
+	Nome      string          `json:"nome"`
+	Criado    bool            `json:"criado"`
+	Conflitos []IndexConflict `json:"conflitos,omitempty"`
+	Erro      string          `json:"erro,omitempty"`
+}
+
+// IndexConflict é um valor de chave repetido em documentos que o índice único recusaria
+type IndexConflict struct {
+	Chave      string   `json:"chave"`
+	Documentos int      `json:"documentos"`
+	IDs        []string `json:"ids"`
+}
+
+// HasProblems indica índices não criados por conflito ou erro
+func (r IndexReport) HasProblems() bool {
+	for _, idx := range r.Indices {
+		if !idx.Criado {
+			return true
+		}
+	}
+	return false
+}
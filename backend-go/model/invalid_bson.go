@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strconv"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// invalidBSON guarda um valor BSON que não pôde ser interpretado como competência ou data. Os
+// bytes ficam em uma string para que Competencia e Data continuem comparáveis com ==.
+type invalidBSON struct {
+	kind bsontype.Type
+	data string
+}
+
+func newInvalidBSON(raw bson.RawValue) invalidBSON {
+	return invalidBSON{kind: raw.Type, data: string(raw.Value)}
+}
+
+func (v invalidBSON) set() bool {
+	return v.kind != 0
+}
+
+func (v invalidBSON) value() (bsontype.Type, []byte, error) {
+	return v.kind, []byte(v.data), nil
+}
+
+// String mostra o texto ou o número gravado (ex.: o serial do Excel) e, para outros tipos, o
+// valor em JSON estendido
+func (v invalidBSON) String() string {
+	if !v.set() {
+		return ""
+	}
+	raw := bson.RawValue{Type: v.kind, Value: []byte(v.data)}
+	switch v.kind {
+	case bson.TypeString:
+		return raw.StringValue()
+	case bson.TypeInt32:
+		return strconv.FormatInt(int64(raw.Int32()), 10)
+	case bson.TypeInt64:
+		return strconv.FormatInt(raw.Int64(), 10)
+	case bson.TypeDouble:
+		return strconv.FormatFloat(raw.Double(), 'f', -1, 64)
+	}
+	return raw.String()
+}
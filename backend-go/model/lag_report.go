@@ -0,0 +1,25 @@
+package model
+
+// LagReportItem é a situação de defasagem de um portal na entrega mais recente considerada
+type LagReportItem struct {
+	PortalID          string      `json:"portalId"`
+	Portal            string      `json:"portal"`
+	Esfera            string      `json:"esfera"`
+	Referencia        string      `json:"referencia"`
+	MesAnoEnvio       Competencia `json:"mesAnoEnvio"`
+	MesAnoReferencia  Competencia `json:"mesAnoReferencia"`
+	DefasagemMeses    int         `json:"defasagemMeses"`
+	DefasagemEsperada int         `json:"defasagemEsperada"`
+	// Atraso é quanto a defasagem excede a esperada (negativo quando está dentro do esperado)
+	Atraso            int  `json:"atraso"`
+	DefasagemNosDados bool `json:"defasagemNosDados"`
+}
+
+// LagReport é a resposta de GET /api/portals/lag-report, ordenada do portal mais atrasado para o menos
+type LagReport struct {
+	Referencia string          `json:"referencia,omitempty"`
+	Esfera     string          `json:"esfera,omitempty"`
+	Items      []LagReportItem `json:"items"`
+	// SemDatas lista os portais sem mês de envio/competência válidos, que não puderam ser medidos
+	SemDatas []string `json:"semDatas,omitempty"`
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DeliveryManifest é o pacote enviado ao cliente: as linhas com Enviar=true de uma entrega.
+// O CSV gerado fica armazenado para novo download; Checksum é o SHA-256 (hex) desse CSV.
+type DeliveryManifest struct {
+	ID                      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Referencia              string             `json:"referencia" bson:"referencia"`
+	GeradoEm                time.Time          `json:"geradoEm" bson:"geradoEm"`
+	GeradoPor               primitive.ObjectID `json:"geradoPor" bson:"geradoPor"`
+	Itens                   []ManifestItem     `json:"itens" bson:"itens"`
+	TotalPortais            int                `json:"totalPortais" bson:"totalPortais"`
+	TotalVolumetriaServicos int                `json:"totalVolumetriaServicos" bson:"totalVolumetriaServicos"`
+	Checksum                string             `json:"checksum" bson:"checksum"`
+	CSV                     string             `json:"-" bson:"csv"`
+}
+
+// ManifestItem é uma linha do manifesto
+type ManifestItem struct {
+	PortalID           string `json:"portalId" bson:"portalId"`
+	Portal             string `json:"portal" bson:"portal"`
+	Competencia        string `json:"competencia" bson:"competencia"`
+	VolumetriaServicos int    `json:"volumetriaServicos" bson:"volumetriaServicos"`
+}
@@ -0,0 +1,21 @@
+package model
+
+import "time"
+
+// SchemaMigration registra, em schema_migrations, uma migração aplicada no ambiente
+type SchemaMigration struct {
+	Version    int       `json:"version" bson:"_id"`
+	Nome       string    `json:"nome" bson:"nome"`
+	AplicadaEm time.Time `json:"aplicadaEm" bson:"aplicadaEm"`
+}
+
+// MigrationStatus é a situação de uma migração conhecida pelo binário
+type MigrationStatus struct {
+	Version    int        `json:"version"`
+	Nome       string     `json:"nome"`
+	Aplicada   bool       `json:"aplicada"`
+	AplicadaEm *time.Time `json:"aplicadaEm,omitempty"`
+	Reversivel bool       `json:"reversivel"`
+	// SentidoUnico indica uma correção de dados que a reversão pula
+	SentidoUnico bool `json:"sentidoUnico"`
+}
@@ -1,39 +1,191 @@
 package model
 
+import "fmt"
+
 type Portal struct {
-	ID                             string  `json:"_id" bson:"_id,omitempty"`
-	Referencia                     string  `json:"referencia"`
-	DataEntrega                    string  `json:"dataEntrega" bson:"dataEntrega,omitempty"`
-	Portal                         string  `json:"portal"`
-	Esfera                         string  `json:"esfera"`
-	MesAnoEnvio                    string  `json:"mesAnoEnvio"`
-	MesAnoReferencia               string  `json:"mesAnoReferencia"`
-	VolumeFonte                    int     `json:"volumeFonte"`
-	VolumetriaDados                int     `json:"volumetriaDados"`
-	VolumetriaServicos             int     `json:"volumetriaServicos"`
-	IndiceDados                    float64 `json:"indiceDados"`
-	IndiceServicos                 float64 `json:"indiceServicos"`
-	VolumeCpfsUnicosDados          int     `json:"volumeCpfsUnicosDados"`
-	VolumeCpfsUnicosServicos       int     `json:"volumeCpfsUnicosServicos"`
-	MediaMovelCpfsUnicos           int     `json:"mediaMovelCpfsUnicos"`
-	UltimoMesEnviado               string  `json:"ultimoMesEnviado"`
-	UltimaReferencia               string  `json:"ultimaReferencia"`
-	UltimaVolumetriaEnviada        int     `json:"ultimaVolumetriaEnviada"`
-	MediaMovelUltimos12Meses       int     `json:"mediaMovelUltimos12Meses"`
-	Media                          int     `json:"media"`
-	Minimo                         int     `json:"minimo"`
-	MesCompetenciaMinimo           string  `json:"mesCompetenciaMinimo"`
-	Maximo                         int     `json:"maximo"`
-	MesCompetenciaMaximo           string  `json:"mesCompetenciaMaximo"`
-	PercentualVolumetriaUltima     float64 `json:"percentualVolumetriaUltima"`
-	PercentualVolumetriaMediaMovel float64 `json:"percentualVolumetriaMediaMovel"`
-	PercentualVolumetriaMedia      float64 `json:"percentualVolumetriaMedia"`
-	PercentualVolumetriaMinimo     float64 `json:"percentualVolumetriaMinimo"`
-	PercentualVolumetriaMaximo     float64 `json:"percentualVolumetriaMaximo"`
-	PulouCompetencia               bool    `json:"pulouCompetencia"`
-	DefasagemNosDados              bool    `json:"defasagemNosDados"`
-	NovosDados                     bool    `json:"novosDados"`
-	Status                         string  `json:"status"`
-	ObservacaoTimeDados            string  `json:"observacaoTimeDados"`
-	Enviar                         bool    `json:"enviar"`
+	ID                             string      `json:"_id" bson:"_id,omitempty"`
+	Referencia                     string      `json:"referencia" bson:"referencia"`
+	DataEntrega                    Data        `json:"dataEntrega" bson:"dataEntrega,omitempty"`
+	Portal                         string      `json:"portal" bson:"portal"`
+	Esfera                         string      `json:"esfera" bson:"esfera"`
+	MesAnoEnvio                    Competencia `json:"mesAnoEnvio" bson:"mesAnoEnvio"`
+	MesAnoReferencia               Competencia `json:"mesAnoReferencia" bson:"mesAnoReferencia"`
+	VolumeFonte                    int         `json:"volumeFonte" bson:"volumeFonte"`
+	VolumetriaDados                int         `json:"volumetriaDados" bson:"volumetriaDados"`
+	VolumetriaServicos             int         `json:"volumetriaServicos" bson:"volumetriaServicos"`
+	IndiceDados                    float64     `json:"indiceDados" bson:"indiceDados"`
+	IndiceServicos                 float64     `json:"indiceServicos" bson:"indiceServicos"`
+	VolumeCpfsUnicosDados          int         `json:"volumeCpfsUnicosDados" bson:"volumeCpfsUnicosDados"`
+	VolumeCpfsUnicosServicos       int         `json:"volumeCpfsUnicosServicos" bson:"volumeCpfsUnicosServicos"`
+	MediaMovelCpfsUnicos           int         `json:"mediaMovelCpfsUnicos" bson:"mediaMovelCpfsUnicos"`
+	UltimoMesEnviado               Competencia `json:"ultimoMesEnviado" bson:"ultimoMesEnviado"`
+	UltimaReferencia               string      `json:"ultimaReferencia" bson:"ultimaReferencia"`
+	UltimaVolumetriaEnviada        int         `json:"ultimaVolumetriaEnviada" bson:"ultimaVolumetriaEnviada"`
+	MediaMovelUltimos12Meses       int         `json:"mediaMovelUltimos12Meses" bson:"mediaMovelUltimos12Meses"`
+	Media                          int         `json:"media" bson:"media"`
+	Minimo                         int         `json:"minimo" bson:"minimo"`
+	MesCompetenciaMinimo           Competencia `json:"mesCompetenciaMinimo" bson:"mesCompetenciaMinimo"`
+	Maximo                         int         `json:"maximo" bson:"maximo"`
+	MesCompetenciaMaximo           Competencia `json:"mesCompetenciaMaximo" bson:"mesCompetenciaMaximo"`
+	PercentualVolumetriaUltima     float64     `json:"percentualVolumetriaUltima" bson:"percentualVolumetriaUltima"`
+	PercentualVolumetriaMediaMovel float64     `json:"percentualVolumetriaMediaMovel" bson:"percentualVolumetriaMediaMovel"`
+	PercentualVolumetriaMedia      float64     `json:"percentualVolumetriaMedia" bson:"percentualVolumetriaMedia"`
+	PercentualVolumetriaMinimo     float64     `json:"percentualVolumetriaMinimo" bson:"percentualVolumetriaMinimo"`
+	PercentualVolumetriaMaximo     float64     `json:"percentualVolumetriaMaximo" bson:"percentualVolumetriaMaximo"`
+	PulouCompetencia               bool        `json:"pulouCompetencia" bson:"pulouCompetencia"`
+	DefasagemNosDados              bool        `json:"defasagemNosDados" bson:"defasagemNosDados"`
+	NovosDados                     bool        `json:"novosDados" bson:"novosDados"`
+	Status                         string      `json:"status" bson:"status"`
+	ObservacaoTimeDados            string      `json:"observacaoTimeDados" bson:"observacaoTimeDados"`
+	Enviar                         bool        `json:"enviar" bson:"enviar"`
+
+	// Divergencias lista os campos derivados cujo valor importado diverge do recalculado
+	Divergencias []MetricDivergence `json:"divergencias,omitempty" bson:"divergencias,omitempty"`
+
+	// StatusMotivos explica a classificação automática. Com StatusManual, o Status foi definido por um
+	// editor e é mantido nas recalculações; StatusAutomatico guarda o que a classificação atribuiria.
+	StatusMotivos    []string `json:"statusMotivos,omitempty" bson:"statusMotivos,omitempty"`
+	StatusAutomatico string   `json:"statusAutomatico,omitempty" bson:"statusAutomatico,omitempty"`
+	StatusManual     bool     `json:"statusManual" bson:"statusManual"`
+	// CompetenciasFaltantes lista os meses ("MM/YYYY") entre a entrega anterior do portal e esta;
+	// quando não vazia, PulouCompetencia é verdadeiro
+	CompetenciasFaltantes []string `json:"competenciasFaltantes,omitempty" bson:"competenciasFaltantes,omitempty"`
+	// DefasagemMeses é a distância, em meses, entre o envio e a competência dos dados (nil quando
+	// não há datas suficientes); DefasagemEsperada é o limite da esfera usado em DefasagemNosDados
+	DefasagemMeses    *int `json:"defasagemMeses,omitempty" bson:"defasagemMeses,omitempty"`
+	DefasagemEsperada int  `json:"defasagemEsperada,omitempty" bson:"defasagemEsperada,omitempty"`
+	// RegrasVersao é a versão do conjunto de regras de validação usada na última avaliação
+	RegrasVersao int `json:"regrasVersao,omitempty" bson:"regrasVersao,omitempty"`
+	// ForaDoCatalogo indica que o nome do portal não consta no catálogo (portal_catalog) nem como alias
+	ForaDoCatalogo bool `json:"foraDoCatalogo,omitempty" bson:"foraDoCatalogo,omitempty"`
+	// Version é incrementada pelo repositório a cada gravação; é exposta como ETag no GET e
+	// conferida no If-Match do PUT (controle de concorrência otimista)
+	Version int `json:"version" bson:"version"`
+
+	// UltimoComentario é o comentário não resolvido mais recente da linha (próprio ou herdado),
+	// preenchido apenas na listagem
+	UltimoComentario *PortalComment `json:"ultimoComentario,omitempty" bson:"-"`
+	// AlteracaoPendente é a solicitação de alteração de "enviar" aguardando aprovação, preenchida
+	// na listagem e na consulta por id
+	AlteracaoPendente *ChangeRequest `json:"alteracaoPendente,omitempty" bson:"-"`
+}
+
+// Valores permitidos para Portal.Status
+const (
+	StatusOK      = "OK"
+	StatusWarning = "WARNING"
+	StatusError   = "ERROR"
+)
+
+// MetricDivergence registra um campo derivado cujo valor importado difere do calculado além da tolerância.
+type MetricDivergence struct {
+	Field    string  `json:"field" bson:"field"`
+	Imported float64 `json:"imported" bson:"imported"`
+	Computed float64 `json:"computed" bson:"computed"`
+}
+
+// FieldValue devolve o valor do campo identificado pelo nome JSON/BSON (ex.: "volumetriaServicos").
+// Inteiros são devolvidos como int, decimais como float64, flags como bool e os demais como string
+// (competências e datas no formato de exibição, ex.: "08/2024").
+func (p *Portal) FieldValue(field string) (interface{}, bool) {
+	switch field {
+	case "_id":
+		return p.ID, true
+	case "referencia":
+		return p.Referencia, true
+	case "dataEntrega":
+		return p.DataEntrega.String(), true
+	case "portal":
+		return p.Portal, true
+	case "esfera":
+		return p.Esfera, true
+	case "mesAnoEnvio":
+		return p.MesAnoEnvio.String(), true
+	case "mesAnoReferencia":
+		return p.MesAnoReferencia.String(), true
+	case "volumeFonte":
+		return p.VolumeFonte, true
+	case "volumetriaDados":
+		return p.VolumetriaDados, true
+	case "volumetriaServicos":
+		return p.VolumetriaServicos, true
+	case "indiceDados":
+		return p.IndiceDados, true
+	case "indiceServicos":
+		return p.IndiceServicos, true
+	case "volumeCpfsUnicosDados":
+		return p.VolumeCpfsUnicosDados, true
+	case "volumeCpfsUnicosServicos":
+		return p.VolumeCpfsUnicosServicos, true
+	case "mediaMovelCpfsUnicos":
+		return p.MediaMovelCpfsUnicos, true
+	case "ultimoMesEnviado":
+		return p.UltimoMesEnviado.String(), true
+	case "ultimaReferencia":
+		return p.UltimaReferencia, true
+	case "ultimaVolumetriaEnviada":
+		return p.UltimaVolumetriaEnviada, true
+	case "mediaMovelUltimos12Meses":
+		return p.MediaMovelUltimos12Meses, true
+	case "media":
+		return p.Media, true
+	case "minimo":
+		return p.Minimo, true
+	case "mesCompetenciaMinimo":
+		return p.MesCompetenciaMinimo.String(), true
+	case "maximo":
+		return p.Maximo, true
+	case "mesCompetenciaMaximo":
+		return p.MesCompetenciaMaximo.String(), true
+	case "percentualVolumetriaUltima":
+		return p.PercentualVolumetriaUltima, true
+	case "percentualVolumetriaMediaMovel":
+		return p.PercentualVolumetriaMediaMovel, true
+	case "percentualVolumetriaMedia":
+		return p.PercentualVolumetriaMedia, true
+	case "percentualVolumetriaMinimo":
+		return p.PercentualVolumetriaMinimo, true
+	case "percentualVolumetriaMaximo":
+		return p.PercentualVolumetriaMaximo, true
+	case "pulouCompetencia":
+		return p.PulouCompetencia, true
+	case "defasagemNosDados":
+		return p.DefasagemNosDados, true
+	case "novosDados":
+		return p.NovosDados, true
+	case "status":
+		return p.Status, true
+	case "observacaoTimeDados":
+		return p.ObservacaoTimeDados, true
+	case "enviar":
+		return p.Enviar, true
+	}
+	return nil, false
+}
+
+// Validate confere as competências e a data de entrega do portal: valores preenchidos precisam
+// existir no calendário e o envio não pode ser anterior à competência dos dados.
+func (p *Portal) Validate() error {
+	competencias := []struct {
+		campo string
+		valor Competencia
+	}{
+		{"mesAnoEnvio", p.MesAnoEnvio},
+		{"mesAnoReferencia", p.MesAnoReferencia},
+		{"ultimoMesEnviado", p.UltimoMesEnviado},
+		{"mesCompetenciaMinimo", p.MesCompetenciaMinimo},
+		{"mesCompetenciaMaximo", p.MesCompetenciaMaximo},
+	}
+	for _, c := range competencias {
+		if err := c.valor.Validate(); err != nil {
+			return fmt.Errorf("%s: %v", c.campo, err)
+		}
+	}
+	if err := p.DataEntrega.Validate(); err != nil {
+		return fmt.Errorf("dataEntrega: %v", err)
+	}
+	if p.MesAnoEnvio.HasMonth() && p.MesAnoReferencia.HasMonth() && p.MesAnoEnvio.Before(p.MesAnoReferencia) {
+		return fmt.Errorf("mesAnoEnvio (%s) anterior a mesAnoReferencia (%s)", p.MesAnoEnvio, p.MesAnoReferencia)
+	}
+	return nil
 }
@@ -0,0 +1,26 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PortalCatalogEntry é o cadastro mestre de um portal (coleção portal_catalog). Portal é o nome
+// canônico gravado nas linhas de portals; Aliases são as outras grafias do mesmo bot encontradas
+// nas colunas "Bot"/"Portal" da planilha.
+type PortalCatalogEntry struct {
+	ID         primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Portal     string             `json:"portal" bson:"portal"`
+	Esfera     string             `json:"esfera" bson:"esfera"`
+	UF         string             `json:"uf" bson:"uf"`
+	Municipio  string             `json:"municipio,omitempty" bson:"municipio,omitempty"`
+	CodigoIBGE string             `json:"codigoIbge,omitempty" bson:"codigoIbge,omitempty"`
+	Equipe     string             `json:"equipe,omitempty" bson:"equipe,omitempty"`
+	Ativo      bool               `json:"ativo" bson:"ativo"`
+	Aliases    []string           `json:"aliases" bson:"aliases"`
+	// Chaves são o nome canônico e os aliases normalizados, usados na resolução de nomes
+	Chaves       []string  `json:"-" bson:"chaves"`
+	CriadoEm     time.Time `json:"criadoEm" bson:"criadoEm"`
+	AtualizadoEm time.Time `json:"atualizadoEm" bson:"atualizadoEm"`
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PortalChange registra uma edição de uma linha de portal: quem fez, quando e os valores
+// de cada campo alterado (incluindo os derivados recalculados pela edição)
+type PortalChange struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	PortalID   string             `json:"portalId" bson:"portalId"`
+	Portal     string             `json:"portal" bson:"portal"`
+	UserID     primitive.ObjectID `json:"userId" bson:"userId"`
+	Em         time.Time          `json:"em" bson:"em"`
+	Alteracoes []FieldChange      `json:"alteracoes" bson:"alteracoes"`
+}
+
+// FieldChange é o valor de um campo antes e depois da edição
+type FieldChange struct {
+	Campo  string      `json:"campo" bson:"campo"`
+	Antes  interface{} `json:"antes" bson:"antes"`
+	Depois interface{} `json:"depois" bson:"depois"`
+}
+
+// PortalChangeQuery filtra o histórico de alterações de uma linha; campos vazios não filtram
+type PortalChangeQuery struct {
+	PortalID string
+	UserID   primitive.ObjectID
+	De       time.Time
+	Ate      time.Time
+}
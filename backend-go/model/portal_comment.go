@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PortalComment é um comentário de discussão sobre uma linha de portal. Enquanto não resolvido,
+// também aparece nas linhas do mesmo portal com competência posterior (Herdado).
+type PortalComment struct {
+	ID           primitive.ObjectID  `json:"id" bson:"_id,omitempty"`
+	PortalID     string              `json:"portalId" bson:"portalId"`
+	Portal       string              `json:"portal" bson:"portal"`
+	Competencia  Competencia         `json:"competencia" bson:"competencia"`
+	AutorID      primitive.ObjectID  `json:"autorId" bson:"autorId"`
+	AutorNome    string              `json:"autorNome" bson:"autorNome"`
+	Texto        string              `json:"texto" bson:"texto"`
+	Resolvido    bool                `json:"resolvido" bson:"resolvido"`
+	ResolvidoPor *primitive.ObjectID `json:"resolvidoPor,omitempty" bson:"resolvidoPor,omitempty"`
+	ResolvidoEm  *time.Time          `json:"resolvidoEm,omitempty" bson:"resolvidoEm,omitempty"`
+	CriadoEm     time.Time           `json:"criadoEm" bson:"criadoEm"`
+	EditadoEm    *time.Time          `json:"editadoEm,omitempty" bson:"editadoEm,omitempty"`
+	// Herdado indica, na listagem de uma linha, que o comentário veio de uma competência anterior
+	Herdado bool `json:"herdado" bson:"-"`
+}
+
+// PortalCommentQuery filtra comentários pelos nomes de portal; SomenteAbertos ignora os resolvidos
+type PortalCommentQuery struct {
+	Portais        []string
+	SomenteAbertos bool
+}
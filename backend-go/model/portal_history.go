@@ -0,0 +1,57 @@
+package model
+
+import "time"
+
+// PortalIdentity é a identidade de um portal de transparência, independente das entregas mensais.
+// Os campos "Ultima*" são derivados da entrega de competência mais recente.
+type PortalIdentity struct {
+	Portal                  string      `json:"portal" bson:"_id"`
+	Esfera                  string      `json:"esfera" bson:"esfera"`
+	PrimeiraCompetencia     Competencia `json:"primeiraCompetencia" bson:"primeiraCompetencia"`
+	UltimaReferencia        string      `json:"ultimaReferencia" bson:"ultimaReferencia"`
+	UltimoMesEnviado        Competencia `json:"ultimoMesEnviado" bson:"ultimoMesEnviado"`
+	UltimaVolumetriaEnviada int         `json:"ultimaVolumetriaEnviada" bson:"ultimaVolumetriaEnviada"`
+	TotalEntregas           int         `json:"totalEntregas" bson:"totalEntregas"`
+	AtualizadoEm            time.Time   `json:"atualizadoEm" bson:"atualizadoEm"`
+}
+
+// Entrega é a entrega mensal de um portal, única por (portal, competência). Quando a mesma
+// competência é reenviada, prevalece a linha com o Mês/Ano de Envio mais recente.
+type Entrega struct {
+	ID                       string      `json:"_id" bson:"_id"` // "<portal>|MM/YYYY"
+	Portal                   string      `json:"portal" bson:"portal"`
+	Competencia              Competencia `json:"competencia" bson:"competencia"`
+	MesAnoReferencia         Competencia `json:"mesAnoReferencia" bson:"mesAnoReferencia"`
+	MesAnoEnvio              Competencia `json:"mesAnoEnvio" bson:"mesAnoEnvio"`
+	Referencia               string      `json:"referencia" bson:"referencia"`
+	DataEntrega              Data        `json:"dataEntrega" bson:"dataEntrega"`
+	PortalRowID              string      `json:"portalRowId" bson:"portalRowId"` // _id da linha em portals
+	VolumeFonte              int         `json:"volumeFonte" bson:"volumeFonte"`
+	VolumetriaDados          int         `json:"volumetriaDados" bson:"volumetriaDados"`
+	VolumetriaServicos       int         `json:"volumetriaServicos" bson:"volumetriaServicos"`
+	VolumeCpfsUnicosDados    int         `json:"volumeCpfsUnicosDados" bson:"volumeCpfsUnicosDados"`
+	VolumeCpfsUnicosServicos int         `json:"volumeCpfsUnicosServicos" bson:"volumeCpfsUnicosServicos"`
+	IndiceDados              float64     `json:"indiceDados" bson:"indiceDados"`
+	IndiceServicos           float64     `json:"indiceServicos" bson:"indiceServicos"`
+	Status                   string      `json:"status" bson:"status"`
+	Enviar                   bool        `json:"enviar" bson:"enviar"`
+}
+
+// EntregaID monta a chave (portal, competência) da entrega
+func EntregaID(portal string, competencia Competencia) string {
+	return portal + "|" + competencia.String()
+}
+
+// PortalHistory é a resposta de GET /api/portals/{portal}/history
+type PortalHistory struct {
+	Portal   PortalIdentity `json:"portal"`
+	Entregas []Entrega      `json:"entregas"`
+}
+
+// HistoryRebuildReport resume a migração que constrói portal_identities/entregas a partir de portals
+type HistoryRebuildReport struct {
+	ProcessedRows int      `json:"processedRows"`
+	Entregas      int      `json:"entregas"`
+	Portais       int      `json:"portais"`
+	Skipped       []string `json:"skipped,omitempty"`
+}
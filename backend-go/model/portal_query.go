@@ -0,0 +1,66 @@
+package model
+
+// SortField descreve um critério de ordenação da listagem de portais.
+// Field usa o mesmo nome do campo JSON/BSON (ex.: "mesAnoReferencia").
+type SortField struct {
+	Field string `json:"field"`
+	Desc  bool   `json:"desc"`
+}
+
+// PortalQuery reúne filtros, ordenação e paginação aplicados no servidor
+// à listagem de portais. Campos vazios (ou nil) não filtram. Os intervalos (De/Ate) são
+// inclusivos; um limite DataEntregaAte com precisão de mês inclui o mês inteiro.
+type PortalQuery struct {
+	Portal              string      `json:"portal,omitempty"` // busca parcial, sem diferenciar maiúsculas
+	Esfera              string      `json:"esfera,omitempty"`
+	Status              string      `json:"status,omitempty"`
+	Referencia          string      `json:"referencia,omitempty"`
+	DataEntrega         Data        `json:"dataEntrega,omitempty"`
+	DataEntregaDe       Data        `json:"dataEntregaFrom,omitempty"`
+	DataEntregaAte      Data        `json:"dataEntregaTo,omitempty"`
+	MesAnoReferencia    Competencia `json:"mesAnoReferencia,omitempty"`
+	MesAnoReferenciaDe  Competencia `json:"mesAnoReferenciaFrom,omitempty"`
+	MesAnoReferenciaAte Competencia `json:"mesAnoReferenciaTo,omitempty"`
+	Enviar              *bool       `json:"enviar,omitempty"`
+	IDs                 []string    `json:"ids,omitempty"` // restringe aos _id informados
+	Sort                []SortField `json:"sort,omitempty"`
+	Limit               int         `json:"limit,omitempty"` // 0 = sem limite
+	Offset              int         `json:"offset,omitempty"`
+	// PortalExato compara Portal por igualdade: atualizações em massa não podem casar parcialmente
+	PortalExato bool `json:"-"`
+}
+
+// PortalPage é o resultado paginado de uma PortalQuery.
+// Total considera todos os registros que atendem aos filtros, independente da página.
+type PortalPage struct {
+	Items  []Portal `json:"items"`
+	Total  int64    `json:"total"`
+	Limit  int      `json:"limit"`
+	Offset int      `json:"offset"`
+}
+
+// PortalSortableFields lista os campos aceitos em PortalQuery.Sort.
+var PortalSortableFields = map[string]bool{
+	"_id": true, "referencia": true, "dataEntrega": true, "portal": true, "esfera": true,
+	"mesAnoEnvio": true, "mesAnoReferencia": true, "ultimoMesEnviado": true, "volumeFonte": true, "volumetriaDados": true,
+	"volumetriaServicos": true, "indiceDados": true, "indiceServicos": true,
+	"percentualVolumetriaMediaMovel": true, "status": true, "enviar": true,
+}
+
+// HasFilter indica se a consulta restringe as linhas (usado para impedir atualizações em massa
+// sem seleção)
+func (q PortalQuery) HasFilter() bool {
+	return q.Portal != "" || q.Esfera != "" || q.Status != "" || q.Referencia != "" ||
+		!q.DataEntrega.IsZero() || !q.DataEntregaDe.IsZero() || !q.DataEntregaAte.IsZero() ||
+		!q.MesAnoReferencia.IsZero() || !q.MesAnoReferenciaDe.IsZero() || !q.MesAnoReferenciaAte.IsZero() ||
+		q.Enviar != nil || len(q.IDs) > 0
+}
+
+// PortalBulkUpdateResult resume uma atualização em massa (PATCH /portals)
+type PortalBulkUpdateResult struct {
+	DryRun   bool  `json:"dryRun"`
+	Affected int64 `json:"affected"`
+	// ChangeRequests são as solicitações de alteração de "enviar" criadas (ou, com dryRun, que
+	// seriam criadas) quando o editor precisa de aprovação
+	ChangeRequests []ChangeRequest `json:"changeRequests,omitempty"`
+}
@@ -0,0 +1,189 @@
+package model
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// PortalSearchResult é o resultado de GET /portals/search, do mais relevante para o menos relevante
+type PortalSearchResult struct {
+	Query string            `json:"q"`
+	Total int               `json:"total"`
+	Items []PortalSearchHit `json:"items"`
+}
+
+// PortalSearchHit é uma linha de portal encontrada pela busca, com a relevância (Score) e os
+// trechos em que os termos aparecem
+type PortalSearchHit struct {
+	Portal  Portal          `json:"portal"`
+	Score   float64         `json:"score"`
+	Trechos []SearchSnippet `json:"trechos"`
+}
+
+// SearchSnippet é um trecho de um campo (ou comentário) com os termos encontrados entre
+// <mark> e </mark>; o restante do texto vem com escape de HTML
+type SearchSnippet struct {
+	Campo  string `json:"campo"`
+	Trecho string `json:"trecho"`
+}
+
+// PortalCommentSearchHit é um comentário encontrado pela busca
+type PortalCommentSearchHit struct {
+	Comment PortalComment
+	Score   float64
+}
+
+// SearchField é um campo de texto de um documento e o seu peso na relevância
+type SearchField struct {
+	Text   string
+	Weight float64
+}
+
+// SearchQuery é a busca interpretada como no $text do MongoDB: basta um dos termos soltos,
+// frases entre aspas são obrigatórias e termos precedidos de "-" excluem o documento.
+// A comparação ignora maiúsculas e acentos, e um termo casa com as palavras que começam por ele
+// (aproximação do stemming do índice de texto).
+type SearchQuery struct {
+	Terms    []string
+	Phrases  []string
+	Excluded []string
+}
+
+var searchPhrase = regexp.MustCompile(`"([^"]*)"`)
+
+func ParseSearchQuery(q string) SearchQuery {
+	var query SearchQuery
+	for _, m := range searchPhrase.FindAllStringSubmatch(q, -1) {
+		if phrase := foldSearchText(strings.TrimSpace(m[1])); phrase != "" {
+			query.Phrases = append(query.Phrases, phrase)
+		}
+	}
+	for _, word := range strings.Fields(searchPhrase.ReplaceAllString(q, " ")) {
+		if strings.HasPrefix(word, "-") {
+			if term := foldSearchText(strings.TrimLeft(word, "-")); term != "" {
+				query.Excluded = append(query.Excluded, term)
+			}
+			continue
+		}
+		if term := foldSearchText(word); term != "" {
+			query.Terms = append(query.Terms, term)
+		}
+	}
+	return query
+}
+
+// IsEmpty indica uma busca sem termos nem frases (apenas exclusões não encontram nada)
+func (q SearchQuery) IsEmpty() bool {
+	return len(q.Terms) == 0 && len(q.Phrases) == 0
+}
+
+// Score calcula a relevância do documento: a soma, por campo, do peso vezes as ocorrências dos
+// termos e frases. É zero quando o documento não atende à busca.
+func (q SearchQuery) Score(fields []SearchField) float64 {
+	if q.IsEmpty() {
+		return 0
+	}
+	all := make([]string, len(fields))
+	for i, f := range fields {
+		all[i] = foldSearchText(f.Text)
+	}
+	joined := " " + strings.Join(all, " \n ")
+	for _, term := range q.Excluded {
+		if len(wordOccurrences([]rune(joined), []rune(term))) > 0 {
+			return 0
+		}
+	}
+	for _, phrase := range q.Phrases {
+		if len(wordOccurrences([]rune(joined), []rune(phrase))) == 0 {
+			return 0
+		}
+	}
+	score := 0.0
+	for _, f := range fields {
+		score += f.Weight * float64(len(q.Spans(f.Text)))
+	}
+	return score
+}
+
+// Spans devolve as posições (em runas do texto original, [início, fim)) das palavras e frases
+// que casam com a busca, em ordem e sem sobreposição
+func (q SearchQuery) Spans(text string) [][2]int {
+	original := []rune(text)
+	folded, index := foldRunes(original)
+	spans := [][2]int{}
+	add := func(pattern string, wholeWord bool) {
+		for _, start := range wordOccurrences(folded, []rune(pattern)) {
+			end := start + len([]rune(pattern))
+			if wholeWord {
+				for end < len(folded) && isSearchWordRune(folded[end]) {
+					end++
+				}
+			}
+			spans = append(spans, [2]int{index[start], index[end-1] + 1})
+		}
+	}
+	for _, phrase := range q.Phrases {
+		add(phrase, false)
+	}
+	for _, term := range q.Terms {
+		add(term, true)
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+	merged := [][2]int{}
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s[0] < merged[n-1][1] {
+			if s[1] > merged[n-1][1] {
+				merged[n-1][1] = s[1]
+			}
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// wordOccurrences devolve os índices em que pattern aparece no início de uma palavra
+func wordOccurrences(text, pattern []rune) []int {
+	found := []int{}
+	if len(pattern) == 0 {
+		return found
+	}
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		if i > 0 && isSearchWordRune(text[i-1]) {
+			continue
+		}
+		if string(text[i:i+len(pattern)]) == string(pattern) {
+			found = append(found, i)
+		}
+	}
+	return found
+}
+
+func isSearchWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func foldSearchText(s string) string {
+	folded, _ := foldRunes([]rune(s))
+	return string(folded)
+}
+
+// foldRunes remove acentos e maiúsculas; index[i] é a posição, no texto original, da runa i
+func foldRunes(original []rune) ([]rune, []int) {
+	folded := make([]rune, 0, len(original))
+	index := make([]int, 0, len(original))
+	for i, r := range original {
+		for _, d := range norm.NFD.String(string(r)) {
+			if unicode.Is(unicode.Mn, d) {
+				continue
+			}
+			folded = append(folded, unicode.ToLower(d))
+			index = append(index, i)
+		}
+	}
+	return folded, index
+}
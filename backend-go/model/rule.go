@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ValidationRule é uma regra de validação configurada por admins. Expression é avaliada sobre os
+// campos do portal (nomes JSON); quando verdadeira, o portal recebe Severity e Message como motivo.
+// Esfera e Portal, quando preenchidos, restringem a regra a esse escopo.
+type ValidationRule struct {
+	ID           primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Name         string             `json:"name" bson:"name"`
+	Expression   string             `json:"expression" bson:"expression"`
+	Severity     string             `json:"severity" bson:"severity"` // WARNING ou ERROR
+	Esfera       string             `json:"esfera,omitempty" bson:"esfera,omitempty"`
+	Portal       string             `json:"portal,omitempty" bson:"portal,omitempty"`
+	Message      string             `json:"message" bson:"message"`
+	Active       bool               `json:"active" bson:"active"`
+	CriadoEm     time.Time          `json:"criadoEm" bson:"criadoEm"`
+	AtualizadoEm time.Time          `json:"atualizadoEm" bson:"atualizadoEm"`
+}
+
+// RuleSet é o conjunto de regras ativas numa versão. Cada alteração em /api/admin/rules gera
+// uma nova versão, e os portais avaliados guardam a versão usada em RegrasVersao.
+type RuleSet struct {
+	Version   int                `json:"version" bson:"_id"`
+	Rules     []ValidationRule   `json:"rules" bson:"rules"`
+	CriadoEm  time.Time          `json:"criadoEm" bson:"criadoEm"`
+	CriadoPor primitive.ObjectID `json:"criadoPor,omitempty" bson:"criadoPor,omitempty"`
+}
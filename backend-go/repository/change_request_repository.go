@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ChangeRequestRepository persiste as solicitações de alteração (coleção change_requests)
+type ChangeRequestRepository interface {
+	InsertChangeRequest(request model.ChangeRequest) (model.ChangeRequest, error)
+	// GetChangeRequest retorna mongo.ErrNoDocuments quando a solicitação não existe
+	GetChangeRequest(id primitive.ObjectID) (model.ChangeRequest, error)
+	// UpdateChangeRequestIfStatus grava a solicitação apenas se o status gravado ainda for status
+	// (compare-and-set); caso contrário retorna mongo.ErrNoDocuments
+	UpdateChangeRequestIfStatus(request model.ChangeRequest, status string) error
+	// FindChangeRequests retorna as solicitações da mais recente para a mais antiga
+	FindChangeRequests(query model.ChangeRequestQuery) ([]model.ChangeRequest, error)
+}
+
+type changeRequestRepository struct {
+	collection *mongo.Collection
+}
+
+func NewChangeRequestRepositoryDB(db *mongo.Database) ChangeRequestRepository {
+	return &changeRequestRepository{collection: db.Collection("change_requests")}
+}
+
+func (r *changeRequestRepository) InsertChangeRequest(request model.ChangeRequest) (model.ChangeRequest, error) {
+	if request.ID.IsZero() {
+		request.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(context.Background(), request)
+	return request, err
+}
+
+func (r *changeRequestRepository) GetChangeRequest(id primitive.ObjectID) (model.ChangeRequest, error) {
+	var request model.ChangeRequest
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&request)
+	return request, err
+}
+
+func (r *changeRequestRepository) UpdateChangeRequestIfStatus(request model.ChangeRequest, status string) error {
+	filter := bson.M{"_id": request.ID, "status": status}
+	result, err := r.collection.ReplaceOne(context.Background(), filter, request)
+	if err == nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *changeRequestRepository) FindChangeRequests(query model.ChangeRequestQuery) ([]model.ChangeRequest, error) {
+	filter := bson.M{}
+	if query.Status != "" {
+		filter["status"] = query.Status
+	}
+	if len(query.PortalIDs) > 0 {
+		filter["portalId"] = bson.M{"$in": query.PortalIDs}
+	}
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "solicitadoEm", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	requests := []model.ChangeRequest{}
+	err = cursor.All(ctx, &requests)
+	return requests, err
+}
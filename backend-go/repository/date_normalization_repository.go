@@ -0,0 +1,194 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"github.com/xuri/excelize/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DateNormalizationRepository regrava, diretamente nos documentos, competências e datas gravadas
+// antes dos tipos model.Competencia/model.Data ("8/2024", "10/11/2024", {ano, mes}, seriais do
+// Excel como 45962...)
+type DateNormalizationRepository interface {
+	NormalizeDates() (model.DateNormalizationReport, error)
+}
+
+// dateValue é um campo com codificação BSON própria (model.Competencia ou model.Data)
+type dateValue interface {
+	MarshalBSONValue() (bsontype.Type, []byte, error)
+	UnmarshalBSONValue(t bsontype.Type, data []byte) error
+	// Invalido é o valor que a leitura não conseguiu interpretar (vazio quando válido)
+	Invalido() string
+}
+
+func competenciaValue() dateValue { return &model.Competencia{} }
+func dataValue() dateValue        { return &model.Data{} }
+
+// dateFields lista, por coleção, os campos de competência/data e o tipo de cada um
+var dateFields = []struct {
+	collection string
+	fields     map[string]func() dateValue
+}{
+	{"portals", map[string]func() dateValue{
+		"dataEntrega":          dataValue,
+		"mesAnoEnvio":          competenciaValue,
+		"mesAnoReferencia":     competenciaValue,
+		"ultimoMesEnviado":     competenciaValue,
+		"mesCompetenciaMinimo": competenciaValue,
+		"mesCompetenciaMaximo": competenciaValue,
+	}},
+	{"entregas", map[string]func() dateValue{
+		"competencia":      competenciaValue,
+		"mesAnoReferencia": competenciaValue,
+		"mesAnoEnvio":      competenciaValue,
+		"dataEntrega":      dataValue,
+	}},
+	{"portal_identities", map[string]func() dateValue{
+		"primeiraCompetencia": competenciaValue,
+		"ultimoMesEnviado":    competenciaValue,
+	}},
+	{"portal_comments", map[string]func() dateValue{
+		"competencia": competenciaValue,
+	}},
+	{"deliveries", map[string]func() dateValue{
+		"dataEntrega": dataValue,
+	}},
+}
+
+type dateNormalizationRepository struct {
+	db *mongo.Database
+}
+
+func NewDateNormalizationRepositoryDB(db *mongo.Database) DateNormalizationRepository {
+	return &dateNormalizationRepository{db: db}
+}
+
+func (r *dateNormalizationRepository) NormalizeDates() (model.DateNormalizationReport, error) {
+	report := model.DateNormalizationReport{}
+	for _, target := range dateFields {
+		result, err := r.normalizeCollection(target.collection, target.fields)
+		report.Colecoes = append(report.Colecoes, result)
+		if err != nil {
+			return report, fmt.Errorf("%s: %w", target.collection, err)
+		}
+	}
+	return report, nil
+}
+
+func (r *dateNormalizationRepository) normalizeCollection(name string, fields map[string]func() dateValue) (model.DateNormalizationCollection, error) {
+	ctx := context.Background()
+	result := model.DateNormalizationCollection{Colecao: name}
+	collection := r.db.Collection(name)
+
+	projection := bson.M{"_id": 1}
+	for field := range fields {
+		projection[field] = 1
+	}
+	cursor, err := collection.Find(ctx, bson.M{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return result, err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		result.Documentos++
+		doc := cursor.Current
+		set, unset := bson.M{}, bson.M{}
+		for field, newValue := range fields {
+			raw, err := doc.LookupErr(field)
+			if err != nil {
+				continue
+			}
+			value := newValue()
+			if serial, ok := excelSerial(raw); ok {
+				err = setFromExcelSerial(value, serial)
+			} else {
+				err = value.UnmarshalBSONValue(raw.Type, raw.Value)
+			}
+			if err != nil || value.Invalido() != "" {
+				// Valor não interpretável: preservado à parte para correção manual
+				set["valoresInvalidos."+field] = raw
+				unset[field] = ""
+				result.Invalidos = append(result.Invalidos, fmt.Sprintf("%s: %s = %s", doc.Lookup("_id"), field, raw))
+				continue
+			}
+			t, data, err := value.MarshalBSONValue()
+			if err != nil {
+				return result, err
+			}
+			if t != raw.Type || !bytes.Equal(data, raw.Value) {
+				set[field] = bson.RawValue{Type: t, Value: data}
+			}
+		}
+		if len(set) == 0 && len(unset) == 0 {
+			continue
+		}
+		update := bson.M{}
+		if len(set) > 0 {
+			update["$set"] = set
+		}
+		if len(unset) > 0 {
+			update["$unset"] = unset
+		}
+		if _, err := collection.UpdateOne(ctx, bson.M{"_id": doc.Lookup("_id")}, update); err != nil {
+			return result, err
+		}
+		result.Atualizados++
+	}
+	return result, cursor.Err()
+}
+
+// excelSerial reconhece datas gravadas como número serial do Excel (ex.: 45962), formato comum das
+// importações de planilha, seja como número ou como texto só com dígitos
+func excelSerial(raw bson.RawValue) (float64, bool) {
+	var serial float64
+	switch raw.Type {
+	case bson.TypeDouble:
+		serial = raw.Double()
+	case bson.TypeInt32:
+		serial = float64(raw.Int32())
+	case bson.TypeInt64:
+		serial = float64(raw.Int64())
+	case bson.TypeString:
+		text := strings.TrimSpace(raw.StringValue())
+		if !excelSerialText.MatchString(text) {
+			return 0, false
+		}
+		serial, _ = strconv.ParseFloat(text, 64)
+	default:
+		return 0, false
+	}
+	// Até 31/12/9999, o maior serial aceito pelo Excel
+	return serial, serial >= 1 && serial < 2958466
+}
+
+var excelSerialText = regexp.MustCompile(`^\d+(\.\d+)?$`)
+
+// setFromExcelSerial converte o serial com a mesma base do importador (30/12/1899, excelize)
+func setFromExcelSerial(value dateValue, serial float64) error {
+	t, err := excelize.ExcelDateToTime(serial, false)
+	if err != nil {
+		return err
+	}
+	data := model.DataFromTime(t)
+	switch v := value.(type) {
+	case *model.Competencia:
+		*v = data.Competencia()
+	case *model.Data:
+		*v = data
+	default:
+		return fmt.Errorf("tipo de data não suportado: %T", value)
+	}
+	return nil
+}
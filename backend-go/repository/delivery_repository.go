@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// DeliveryRepository persiste as entregas (coleção deliveries), chaveadas pela Referencia
+type DeliveryRepository interface {
+	// GetDelivery retorna mongo.ErrNoDocuments quando a entrega não existe
+	GetDelivery(referencia string) (model.Delivery, error)
+	ListDeliveries() ([]model.Delivery, error)
+	// InsertDeliveryIfMissing grava a entrega apenas se ainda não existir; created indica se foi criada
+	InsertDeliveryIfMissing(delivery model.Delivery) (created bool, err error)
+	UpdateDelivery(delivery model.Delivery) error
+}
+
+type deliveryRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDeliveryRepositoryDB(db *mongo.Database) DeliveryRepository {
+	return &deliveryRepository{collection: db.Collection("deliveries")}
+}
+
+func (r *deliveryRepository) GetDelivery(referencia string) (model.Delivery, error) {
+	var delivery model.Delivery
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": referencia}).Decode(&delivery)
+	return delivery, err
+}
+
+func (r *deliveryRepository) ListDeliveries() ([]model.Delivery, error) {
+	ctx := context.Background()
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "criadoEm", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	deliveries := []model.Delivery{}
+	err = cursor.All(ctx, &deliveries)
+	return deliveries, err
+}
+
+func (r *deliveryRepository) InsertDeliveryIfMissing(delivery model.Delivery) (bool, error) {
+	filter := bson.M{"_id": delivery.Referencia}
+	update := bson.M{"$setOnInsert": delivery}
+	result, err := r.collection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, err
+	}
+	return result.UpsertedCount > 0, nil
+}
+
+func (r *deliveryRepository) UpdateDelivery(delivery model.Delivery) error {
+	result, err := r.collection.ReplaceOne(context.Background(), bson.M{"_id": delivery.Referencia}, delivery)
+	if err == nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
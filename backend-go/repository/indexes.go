@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Pesos dos campos nos índices de texto; os repositórios mock usam os mesmos pesos na relevância
+var (
+	portalTextWeights  = bson.D{{Key: "portal", Value: 10}, {Key: "observacaoTimeDados", Value: 5}, {Key: "status", Value: 1}, {Key: "esfera", Value: 1}}
+	commentTextWeights = bson.D{{Key: "texto", Value: 1}}
+)
+
+// maxIndexConflicts limita quantos valores repetidos são listados por índice
+const maxIndexConflicts = 20
+
+// indexSpec descreve um índice mantido pelo backend. Em índices únicos, filter delimita os
+// documentos cobertos (o mesmo do partialFilterExpression, ou os que têm o campo, se sparse).
+type indexSpec struct {
+	collection string
+	keys       bson.D
+	options    *options.IndexOptions
+	unique     bool
+	filter     bson.M
+}
+
+func textIndexSpec(collection, name string, weights bson.D) indexSpec {
+	keys := bson.D{}
+	for _, w := range weights {
+		keys = append(keys, bson.E{Key: w.Key, Value: "text"})
+	}
+	return indexSpec{
+		collection: collection,
+		keys:       keys,
+		options:    options.Index().SetName(name).SetWeights(weights).SetDefaultLanguage("portuguese"),
+	}
+}
+
+// indexSpecs lista os índices das consultas do backend: sessões por token, usuários por
+// username/email/googleId, linhas de portal pela chave da entrega e os índices de texto da busca
+func indexSpecs() []indexSpec {
+	// Usuários do Google são gravados sem username (""), que não pode contar como repetido
+	withUsername := bson.M{"username": bson.M{"$gt": ""}}
+	return []indexSpec{
+		{
+			collection: "users",
+			keys:       bson.D{{Key: "username", Value: 1}},
+			options:    options.Index().SetName("users_username_unique").SetUnique(true).SetPartialFilterExpression(withUsername),
+			unique:     true,
+			filter:     withUsername,
+		},
+		{
+			collection: "users",
+			keys:       bson.D{{Key: "email", Value: 1}},
+			options:    options.Index().SetName("users_email_unique").SetUnique(true),
+			unique:     true,
+			filter:     bson.M{},
+		},
+		{
+			collection: "users",
+			keys:       bson.D{{Key: "googleId", Value: 1}},
+			options:    options.Index().SetName("users_googleId_unique").SetUnique(true).SetSparse(true),
+			unique:     true,
+			filter:     bson.M{"googleId": bson.M{"$exists": true}},
+		},
+		{
+			collection: "sessions",
+			keys:       bson.D{{Key: "token", Value: 1}},
+			options:    options.Index().SetName("sessions_token_unique").SetUnique(true),
+			unique:     true,
+			filter:     bson.M{},
+		},
+		{
+			// O MongoDB remove as sessões assim que expiresAt passa
+			collection: "sessions",
+			keys:       bson.D{{Key: "expiresAt", Value: 1}},
+			options:    options.Index().SetName("sessions_expiresAt_ttl").SetExpireAfterSeconds(0),
+		},
+		{
+			collection: "portals",
+			keys:       bson.D{{Key: "portal", Value: 1}, {Key: "referencia", Value: 1}, {Key: "mesAnoReferencia", Value: 1}},
+			options:    options.Index().SetName("portals_portal_referencia_competencia_unique").SetUnique(true),
+			unique:     true,
+			filter:     bson.M{},
+		},
+		textIndexSpec("portals", "portals_text", portalTextWeights),
+		textIndexSpec("portal_comments", "portal_comments_text", commentTextWeights),
+	}
+}
+
+// EnsureIndexes cria os índices do backend. É idempotente: recriar um índice igual não tem efeito.
+// Um índice único só é criado quando os documentos existentes não o violam; os valores repetidos
+// vão para o relatório. Erros de um índice (ex.: outro índice com o mesmo nome) também ficam no
+// relatório e não impedem os demais.
+func EnsureIndexes(db *mongo.Database) (model.IndexReport, error) {
+	report := model.IndexReport{Indices: []model.IndexResult{}}
+	for _, spec := range indexSpecs() {
+		collection := db.Collection(spec.collection)
+		result := model.IndexResult{Colecao: spec.collection, Nome: *spec.options.Name}
+		if spec.unique {
+			conflicts, err := findIndexConflicts(collection, spec)
+			if err != nil {
+				return report, fmt.Errorf("verificando %s.%s: %w", spec.collection, result.Nome, err)
+			}
+			if len(conflicts) > 0 {
+				result.Conflitos = conflicts
+				report.Indices = append(report.Indices, result)
+				continue
+			}
+		}
+		_, err := collection.Indexes().CreateOne(context.Background(), mongo.IndexModel{Keys: spec.keys, Options: spec.options})
+		if err != nil {
+			result.Erro = err.Error()
+		} else {
+			result.Criado = true
+		}
+		report.Indices = append(report.Indices, result)
+	}
+	return report, nil
+}
+
+// findIndexConflicts agrupa os documentos cobertos pelo índice pela chave e devolve as chaves
+// repetidas
+func findIndexConflicts(collection *mongo.Collection, spec indexSpec) ([]model.IndexConflict, error) {
+	group := bson.M{}
+	for _, k := range spec.keys {
+		group[k.Key] = "$" + k.Key
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: spec.filter}},
+		{{Key: "$group", Value: bson.M{"_id": group, "ids": bson.M{"$push": "$_id"}, "documentos": bson.M{"$sum": 1}}}},
+		{{Key: "$match", Value: bson.M{"documentos": bson.M{"$gt": 1}}}},
+		{{Key: "$sort", Value: bson.M{"documentos": -1}}},
+		{{Key: "$limit", Value: maxIndexConflicts}},
+	}
+	ctx := context.Background()
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	var groups []struct {
+		Key        bson.M        `bson:"_id"`
+		IDs        []interface{} `bson:"ids"`
+		Documentos int           `bson:"documentos"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+	conflicts := make([]model.IndexConflict, 0, len(groups))
+	for _, g := range groups {
+		parts := make([]string, 0, len(spec.keys))
+		for _, k := range spec.keys {
+			parts = append(parts, fmt.Sprintf("%s=%v", k.Key, g.Key[k.Key]))
+		}
+		ids := make([]string, 0, len(g.IDs))
+		for _, id := range g.IDs {
+			if oid, ok := id.(primitive.ObjectID); ok {
+				ids = append(ids, oid.Hex())
+				continue
+			}
+			ids = append(ids, fmt.Sprint(id))
+		}
+		conflicts = append(conflicts, model.IndexConflict{Chave: strings.Join(parts, ", "), Documentos: g.Documentos, IDs: ids})
+	}
+	return conflicts, nil
+}
+
+// searchFields monta os campos do documento com os pesos do índice, para a relevância em memória
+func searchFields(weights bson.D, value func(field string) string) []model.SearchField {
+	fields := make([]model.SearchField, 0, len(weights))
+	for _, w := range weights {
+		fields = append(fields, model.SearchField{Text: value(w.Key), Weight: float64(w.Value.(int))})
+	}
+	return fields
+}
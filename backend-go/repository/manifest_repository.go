@@ -0,0 +1,55 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ManifestRepository armazena os manifestos gerados (coleção delivery_manifests)
+type ManifestRepository interface {
+	InsertManifest(manifest model.DeliveryManifest) (model.DeliveryManifest, error)
+	// GetManifest retorna mongo.ErrNoDocuments quando o manifesto não existe
+	GetManifest(id primitive.ObjectID) (model.DeliveryManifest, error)
+	// ListManifests retorna os manifestos da referência, do mais recente para o mais antigo
+	ListManifests(referencia string) ([]model.DeliveryManifest, error)
+}
+
+type manifestRepository struct {
+	collection *mongo.Collection
+}
+
+func NewManifestRepositoryDB(db *mongo.Database) ManifestRepository {
+	return &manifestRepository{collection: db.Collection("delivery_manifests")}
+}
+
+func (r *manifestRepository) InsertManifest(manifest model.DeliveryManifest) (model.DeliveryManifest, error) {
+	if manifest.ID.IsZero() {
+		manifest.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(context.Background(), manifest)
+	return manifest, err
+}
+
+func (r *manifestRepository) GetManifest(id primitive.ObjectID) (model.DeliveryManifest, error) {
+	var manifest model.DeliveryManifest
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&manifest)
+	return manifest, err
+}
+
+func (r *manifestRepository) ListManifests(referencia string) ([]model.DeliveryManifest, error) {
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "geradoEm", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.Find(ctx, bson.M{"referencia": referencia}, opts)
+	if err != nil {
+		return nil, err
+	}
+	manifests := []model.DeliveryManifest{}
+	err = cursor.All(ctx, &manifests)
+	return manifests, err
+}
@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MigrationRepository guarda em schema_migrations as migrações já aplicadas. O _id é a versão:
+// dois processos aplicando a mesma migração falham no segundo registro.
+type MigrationRepository interface {
+	ListApplied() ([]model.SchemaMigration, error)
+	MarkApplied(migration model.SchemaMigration) error
+	// UnmarkApplied remove o registro da versão (mongo.ErrNoDocuments quando não há)
+	UnmarkApplied(version int) error
+}
+
+type migrationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMigrationRepositoryDB(db *mongo.Database) MigrationRepository {
+	return &migrationRepository{collection: db.Collection("schema_migrations")}
+}
+
+func (r *migrationRepository) ListApplied() ([]model.SchemaMigration, error) {
+	ctx := context.Background()
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	applied := []model.SchemaMigration{}
+	if err := cursor.All(ctx, &applied); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+func (r *migrationRepository) MarkApplied(migration model.SchemaMigration) error {
+	_, err := r.collection.InsertOne(context.Background(), migration)
+	return err
+}
+
+func (r *migrationRepository) UnmarkApplied(version int) error {
+	res, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": version})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
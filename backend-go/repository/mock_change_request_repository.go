@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockChangeRequestRepository mantém as solicitações em memória (DATA_SOURCE=mock e testes)
+type mockChangeRequestRepository struct {
+	requests []model.ChangeRequest
+}
+
+func NewMockChangeRequestRepository() ChangeRequestRepository {
+	return &mockChangeRequestRepository{}
+}
+
+func (r *mockChangeRequestRepository) InsertChangeRequest(request model.ChangeRequest) (model.ChangeRequest, error) {
+	if request.ID.IsZero() {
+		request.ID = primitive.NewObjectID()
+	}
+	r.requests = append(r.requests, request)
+	return request, nil
+}
+
+func (r *mockChangeRequestRepository) GetChangeRequest(id primitive.ObjectID) (model.ChangeRequest, error) {
+	for _, req := range r.requests {
+		if req.ID == id {
+			return req, nil
+		}
+	}
+	return model.ChangeRequest{}, mongo.ErrNoDocuments
+}
+
+func (r *mockChangeRequestRepository) UpdateChangeRequestIfStatus(request model.ChangeRequest, status string) error {
+	for i, req := range r.requests {
+		if req.ID == request.ID && req.Status == status {
+			r.requests[i] = request
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *mockChangeRequestRepository) FindChangeRequests(query model.ChangeRequestQuery) ([]model.ChangeRequest, error) {
+	portalIDs := map[string]bool{}
+	for _, id := range query.PortalIDs {
+		portalIDs[id] = true
+	}
+	requests := []model.ChangeRequest{}
+	// Do último inserido para o primeiro: no empate de horário, a solicitação mais recente vem antes
+	for i := len(r.requests) - 1; i >= 0; i-- {
+		req := r.requests[i]
+		if query.Status != "" && req.Status != query.Status {
+			continue
+		}
+		if len(portalIDs) > 0 && !portalIDs[req.PortalID] {
+			continue
+		}
+		requests = append(requests, req)
+	}
+	sort.SliceStable(requests, func(i, j int) bool { return requests[i].SolicitadoEm.After(requests[j].SolicitadoEm) })
+	return requests, nil
+}
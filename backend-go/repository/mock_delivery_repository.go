@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockDeliveryRepository mantém as entregas em memória (DATA_SOURCE=mock e testes)
+type mockDeliveryRepository struct {
+	deliveries map[string]model.Delivery
+}
+
+func NewMockDeliveryRepository() DeliveryRepository {
+	return &mockDeliveryRepository{deliveries: map[string]model.Delivery{}}
+}
+
+func (r *mockDeliveryRepository) GetDelivery(referencia string) (model.Delivery, error) {
+	delivery, ok := r.deliveries[referencia]
+	if !ok {
+		return model.Delivery{}, mongo.ErrNoDocuments
+	}
+	return delivery, nil
+}
+
+func (r *mockDeliveryRepository) ListDeliveries() ([]model.Delivery, error) {
+	deliveries := make([]model.Delivery, 0, len(r.deliveries))
+	for _, d := range r.deliveries {
+		deliveries = append(deliveries, d)
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].CriadoEm.After(deliveries[j].CriadoEm) })
+	return deliveries, nil
+}
+
+func (r *mockDeliveryRepository) InsertDeliveryIfMissing(delivery model.Delivery) (bool, error) {
+	if _, ok := r.deliveries[delivery.Referencia]; ok {
+		return false, nil
+	}
+	r.deliveries[delivery.Referencia] = delivery
+	return true, nil
+}
+
+func (r *mockDeliveryRepository) UpdateDelivery(delivery model.Delivery) error {
+	if _, ok := r.deliveries[delivery.Referencia]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	r.deliveries[delivery.Referencia] = delivery
+	return nil
+}
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockManifestRepository mantém os manifestos em memória (DATA_SOURCE=mock e testes)
+type mockManifestRepository struct {
+	manifests []model.DeliveryManifest
+}
+
+func NewMockManifestRepository() ManifestRepository {
+	return &mockManifestRepository{}
+}
+
+func (r *mockManifestRepository) InsertManifest(manifest model.DeliveryManifest) (model.DeliveryManifest, error) {
+	if manifest.ID.IsZero() {
+		manifest.ID = primitive.NewObjectID()
+	}
+	r.manifests = append(r.manifests, manifest)
+	return manifest, nil
+}
+
+func (r *mockManifestRepository) GetManifest(id primitive.ObjectID) (model.DeliveryManifest, error) {
+	for _, m := range r.manifests {
+		if m.ID == id {
+			return m, nil
+		}
+	}
+	return model.DeliveryManifest{}, mongo.ErrNoDocuments
+}
+
+func (r *mockManifestRepository) ListManifests(referencia string) ([]model.DeliveryManifest, error) {
+	manifests := []model.DeliveryManifest{}
+	// Percorre do último inserido para o primeiro: no empate de geradoEm, o mais recente vem antes
+	for i := len(r.manifests) - 1; i >= 0; i-- {
+		if r.manifests[i].Referencia == referencia {
+			manifests = append(manifests, r.manifests[i])
+		}
+	}
+	sort.SliceStable(manifests, func(i, j int) bool { return manifests[i].GeradoEm.After(manifests[j].GeradoEm) })
+	return manifests, nil
+}
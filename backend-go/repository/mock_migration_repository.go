@@ -0,0 +1,45 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockMigrationRepository mantém schema_migrations em memória (DATA_SOURCE=mock e testes)
+type mockMigrationRepository struct {
+	applied []model.SchemaMigration
+}
+
+func NewMockMigrationRepository() MigrationRepository {
+	return &mockMigrationRepository{}
+}
+
+func (r *mockMigrationRepository) ListApplied() ([]model.SchemaMigration, error) {
+	applied := append([]model.SchemaMigration{}, r.applied...)
+	sort.Slice(applied, func(i, j int) bool { return applied[i].Version < applied[j].Version })
+	return applied, nil
+}
+
+func (r *mockMigrationRepository) MarkApplied(migration model.SchemaMigration) error {
+	for _, m := range r.applied {
+		if m.Version == migration.Version {
+			return fmt.Errorf("migração %d já registrada", migration.Version)
+		}
+	}
+	r.applied = append(r.applied, migration)
+	return nil
+}
+
+func (r *mockMigrationRepository) UnmarkApplied(version int) error {
+	for i, m := range r.applied {
+		if m.Version == version {
+			r.applied = append(r.applied[:i], r.applied[i+1:]...)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockPortalCatalogRepository mantém o catálogo de portais em memória (DATA_SOURCE=mock e testes)
+type mockPortalCatalogRepository struct {
+	entries []model.PortalCatalogEntry
+}
+
+func NewMockPortalCatalogRepository() PortalCatalogRepository {
+	return &mockPortalCatalogRepository{}
+}
+
+func (r *mockPortalCatalogRepository) ListEntries() ([]model.PortalCatalogEntry, error) {
+	entries := append([]model.PortalCatalogEntry{}, r.entries...)
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Portal < entries[j].Portal })
+	return entries, nil
+}
+
+func (r *mockPortalCatalogRepository) GetEntry(id primitive.ObjectID) (model.PortalCatalogEntry, error) {
+	for _, entry := range r.entries {
+		if entry.ID == id {
+			return entry, nil
+		}
+	}
+	return model.PortalCatalogEntry{}, mongo.ErrNoDocuments
+}
+
+func (r *mockPortalCatalogRepository) FindByKey(key string) (model.PortalCatalogEntry, error) {
+	for _, entry := range r.entries {
+		for _, k := range entry.Chaves {
+			if k == key {
+				return entry, nil
+			}
+		}
+	}
+	return model.PortalCatalogEntry{}, mongo.ErrNoDocuments
+}
+
+func (r *mockPortalCatalogRepository) CountEntries() (int64, error) {
+	return int64(len(r.entries)), nil
+}
+
+func (r *mockPortalCatalogRepository) InsertEntry(entry model.PortalCatalogEntry) (model.PortalCatalogEntry, error) {
+	entry.ID = primitive.NewObjectID()
+	r.entries = append(r.entries, entry)
+	return entry, nil
+}
+
+func (r *mockPortalCatalogRepository) UpdateEntry(entry model.PortalCatalogEntry) error {
+	for i := range r.entries {
+		if r.entries[i].ID == entry.ID {
+			r.entries[i] = entry
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *mockPortalCatalogRepository) DeleteEntry(id primitive.ObjectID) error {
+	for i := range r.entries {
+		if r.entries[i].ID == id {
+			r.entries = append(r.entries[:i], r.entries[i+1:]...)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
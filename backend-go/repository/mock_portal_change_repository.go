@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// mockPortalChangeRepository mantém o histórico de edições em memória (DATA_SOURCE=mock e testes)
+type mockPortalChangeRepository struct {
+	changes []model.PortalChange
+}
+
+func NewMockPortalChangeRepository() PortalChangeRepository {
+	return &mockPortalChangeRepository{}
+}
+
+func (r *mockPortalChangeRepository) InsertChange(change model.PortalChange) (model.PortalChange, error) {
+	if change.ID.IsZero() {
+		change.ID = primitive.NewObjectID()
+	}
+	r.changes = append(r.changes, change)
+	return change, nil
+}
+
+func (r *mockPortalChangeRepository) FindChanges(query model.PortalChangeQuery) ([]model.PortalChange, error) {
+	changes := []model.PortalChange{}
+	// Do último inserido para o primeiro: no empate de horário, a alteração mais recente vem antes
+	for i := len(r.changes) - 1; i >= 0; i-- {
+		c := r.changes[i]
+		if query.PortalID != "" && c.PortalID != query.PortalID {
+			continue
+		}
+		if !query.UserID.IsZero() && c.UserID != query.UserID {
+			continue
+		}
+		if !query.De.IsZero() && c.Em.Before(query.De) {
+			continue
+		}
+		if !query.Ate.IsZero() && c.Em.After(query.Ate) {
+			continue
+		}
+		changes = append(changes, c)
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Em.After(changes[j].Em) })
+	return changes, nil
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockPortalCommentRepository mantém os comentários em memória, na ordem de criação
+// (DATA_SOURCE=mock e testes)
+type mockPortalCommentRepository struct {
+	comments []model.PortalComment
+}
+
+func NewMockPortalCommentRepository() PortalCommentRepository {
+	return &mockPortalCommentRepository{}
+}
+
+func (r *mockPortalCommentRepository) InsertComment(comment model.PortalComment) (model.PortalComment, error) {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	r.comments = append(r.comments, comment)
+	return comment, nil
+}
+
+func (r *mockPortalCommentRepository) GetComment(id primitive.ObjectID) (model.PortalComment, error) {
+	for _, c := range r.comments {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return model.PortalComment{}, mongo.ErrNoDocuments
+}
+
+func (r *mockPortalCommentRepository) UpdateComment(comment model.PortalComment) error {
+	for i, c := range r.comments {
+		if c.ID == comment.ID {
+			r.comments[i] = comment
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *mockPortalCommentRepository) DeleteComment(id primitive.ObjectID) error {
+	for i, c := range r.comments {
+		if c.ID == id {
+			r.comments = append(r.comments[:i], r.comments[i+1:]...)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *mockPortalCommentRepository) FindComments(query model.PortalCommentQuery) ([]model.PortalComment, error) {
+	portais := map[string]bool{}
+	for _, p := range query.Portais {
+		portais[p] = true
+	}
+	comments := []model.PortalComment{}
+	for _, c := range r.comments {
+		if !portais[c.Portal] || (query.SomenteAbertos && c.Resolvido) {
+			continue
+		}
+		comments = append(comments, c)
+	}
+	return comments, nil
+}
+
+// SearchComments reproduz em memória a busca textual no texto dos comentários
+func (r *mockPortalCommentRepository) SearchComments(q string, limit int) ([]model.PortalCommentSearchHit, error) {
+	query := model.ParseSearchQuery(q)
+	hits := []model.PortalCommentSearchHit{}
+	for _, c := range r.comments {
+		fields := searchFields(commentTextWeights, func(string) string { return c.Texto })
+		if score := query.Score(fields); score > 0 {
+			hits = append(hits, model.PortalCommentSearchHit{Comment: c, Score: score})
+		}
+	}
+	sort.SliceStable(hits, func(i, j int) bool { return hits[i].Score > hits[j].Score })
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockPortalHistoryRepository mantém identidades e entregas em memória (DATA_SOURCE=mock e testes).
+// Registros inexistentes retornam mongo.ErrNoDocuments, como a implementação MongoDB.
+type mockPortalHistoryRepository struct {
+	identities map[string]model.PortalIdentity
+	entregas   map[string]model.Entrega
+}
+
+func NewMockPortalHistoryRepository() PortalHistoryRepository {
+	return &mockPortalHistoryRepository{
+		identities: map[string]model.PortalIdentity{},
+		entregas:   map[string]model.Entrega{},
+	}
+}
+
+func (r *mockPortalHistoryRepository) UpsertIdentity(identity model.PortalIdentity) error {
+	r.identities[identity.Portal] = identity
+	return nil
+}
+
+func (r *mockPortalHistoryRepository) GetIdentity(portal string) (model.PortalIdentity, error) {
+	identity, ok := r.identities[portal]
+	if !ok {
+		return model.PortalIdentity{}, mongo.ErrNoDocuments
+	}
+	return identity, nil
+}
+
+func (r *mockPortalHistoryRepository) ListIdentities() ([]model.PortalIdentity, error) {
+	identities := make([]model.PortalIdentity, 0, len(r.identities))
+	for _, identity := range r.identities {
+		identities = append(identities, identity)
+	}
+	sort.Slice(identities, func(i, j int) bool { return identities[i].Portal < identities[j].Portal })
+	return identities, nil
+}
+
+func (r *mockPortalHistoryRepository) UpsertEntrega(entrega model.Entrega) error {
+	r.entregas[entrega.ID] = entrega
+	return nil
+}
+
+func (r *mockPortalHistoryRepository) GetEntrega(id string) (model.Entrega, error) {
+	entrega, ok := r.entregas[id]
+	if !ok {
+		return model.Entrega{}, mongo.ErrNoDocuments
+	}
+	return entrega, nil
+}
+
+func (r *mockPortalHistoryRepository) GetEntregas(portal string) ([]model.Entrega, error) {
+	entregas := []model.Entrega{}
+	for _, e := range r.entregas {
+		if e.Portal == portal {
+			entregas = append(entregas, e)
+		}
+	}
+	sort.Slice(entregas, func(i, j int) bool { return entregas[i].Competencia.Before(entregas[j].Competencia) })
+	return entregas, nil
+}
+
+func (r *mockPortalHistoryRepository) Clear() error {
+	r.identities = map[string]model.PortalIdentity{}
+	r.entregas = map[string]model.Entrega{}
+	return nil
+}
@@ -2,8 +2,11 @@ package repository
 
 import (
     "fmt"
+    "sort"
+    "strings"
     "solid_react_golang_mongo_project/backend-go/model"
     "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
 )
 
 type mockPortalRepository struct {
@@ -18,8 +21,8 @@ func NewMockPortalRepository() PortalRepository {
 				Referencia:                      "10-11-2024",
 				Portal:                          "transparencia_al",
 				Esfera:                          "ESTADUAL",
-				MesAnoEnvio:                     "11/2024",
-				MesAnoReferencia:                "08/2024",
+				MesAnoEnvio:                     model.Competencia{Ano: 2024, Mes: 11},
+				MesAnoReferencia:                model.Competencia{Ano: 2024, Mes: 8},
 				VolumeFonte:                     70655,
 				VolumetriaDados:                 67702,
 				VolumetriaServicos:              67701,
@@ -28,15 +31,15 @@ func NewMockPortalRepository() PortalRepository {
 				VolumeCpfsUnicosDados:           1200,
 				VolumeCpfsUnicosServicos:        1150,
 				MediaMovelCpfsUnicos:            1000,
-				UltimoMesEnviado:                "10/2024",
+				UltimoMesEnviado:                model.Competencia{Ano: 2024, Mes: 10},
 				UltimaReferencia:                "08/2024",
 				UltimaVolumetriaEnviada:         69000,
 				MediaMovelUltimos12Meses:        67000,
 				Media:                           68000,
 				Minimo:                          65000,
-				MesCompetenciaMinimo:            "06/2024",
+				MesCompetenciaMinimo:            model.Competencia{Ano: 2024, Mes: 6},
 				Maximo:                          71000,
-				MesCompetenciaMaximo:            "07/2024",
+				MesCompetenciaMaximo:            model.Competencia{Ano: 2024, Mes: 7},
 				PercentualVolumetriaUltima:      102.5,
 				PercentualVolumetriaMediaMovel:  98.5,
 				PercentualVolumetriaMedia:       99.5,
@@ -54,8 +57,8 @@ func NewMockPortalRepository() PortalRepository {
 				Referencia:                      "10-11-2024",
 				Portal:                          "transparencia_sp",
 				Esfera:                          "MUNICIPAL",
-				MesAnoEnvio:                     "11/2024",
-				MesAnoReferencia:                "09/2024",
+				MesAnoEnvio:                     model.Competencia{Ano: 2024, Mes: 11},
+				MesAnoReferencia:                model.Competencia{Ano: 2024, Mes: 9},
 				VolumeFonte:                     80000,
 				VolumetriaDados:                 78000,
 				VolumetriaServicos:              75000,
@@ -64,15 +67,15 @@ func NewMockPortalRepository() PortalRepository {
 				VolumeCpfsUnicosDados:           1300,
 				VolumeCpfsUnicosServicos:        1200,
 				MediaMovelCpfsUnicos:            1050,
-				UltimoMesEnviado:                "10/2024",
+				UltimoMesEnviado:                model.Competencia{Ano: 2024, Mes: 10},
 				UltimaReferencia:                "09/2024",
 				UltimaVolumetriaEnviada:         75000,
 				MediaMovelUltimos12Meses:        72000,
 				Media:                           74000,
 				Minimo:                          70000,
-				MesCompetenciaMinimo:            "05/2024",
+				MesCompetenciaMinimo:            model.Competencia{Ano: 2024, Mes: 5},
 				Maximo:                          78000,
-				MesCompetenciaMaximo:            "06/2024",
+				MesCompetenciaMaximo:            model.Competencia{Ano: 2024, Mes: 6},
 				PercentualVolumetriaUltima:      101.0,
 				PercentualVolumetriaMediaMovel:  98.0,
 				PercentualVolumetriaMedia:       99.0,
@@ -90,8 +93,8 @@ func NewMockPortalRepository() PortalRepository {
 				Referencia:                      "10-11-2024",
 				Portal:                          "transparencia_rj",
 				Esfera:                          "ESTADUAL",
-				MesAnoEnvio:                     "11/2024",
-				MesAnoReferencia:                "08/2024",
+				MesAnoEnvio:                     model.Competencia{Ano: 2024, Mes: 11},
+				MesAnoReferencia:                model.Competencia{Ano: 2024, Mes: 8},
 				VolumeFonte:                     76000,
 				VolumetriaDados:                 74000,
 				VolumetriaServicos:              72000,
@@ -100,15 +103,15 @@ func NewMockPortalRepository() PortalRepository {
 				VolumeCpfsUnicosDados:           1250,
 				VolumeCpfsUnicosServicos:        1180,
 				MediaMovelCpfsUnicos:            1100,
-				UltimoMesEnviado:                "09/2024",
+				UltimoMesEnviado:                model.Competencia{Ano: 2024, Mes: 9},
 				UltimaReferencia:                "08/2024",
 				UltimaVolumetriaEnviada:         74000,
 				MediaMovelUltimos12Meses:        70000,
 				Media:                           71000,
 				Minimo:                          69000,
-				MesCompetenciaMinimo:            "06/2024",
+				MesCompetenciaMinimo:            model.Competencia{Ano: 2024, Mes: 6},
 				Maximo:                          76000,
-				MesCompetenciaMaximo:            "07/2024",
+				MesCompetenciaMaximo:            model.Competencia{Ano: 2024, Mes: 7},
 				PercentualVolumetriaUltima:      103.0,
 				PercentualVolumetriaMediaMovel:  97.5,
 				PercentualVolumetriaMedia:       99.3,
@@ -126,8 +129,8 @@ func NewMockPortalRepository() PortalRepository {
 				Referencia:                      "10-11-2024",
 				Portal:                          "transparencia_mg",
 				Esfera:                          "MUNICIPAL",
-				MesAnoEnvio:                     "10/2024",
-				MesAnoReferencia:                "08/2024",
+				MesAnoEnvio:                     model.Competencia{Ano: 2024, Mes: 10},
+				MesAnoReferencia:                model.Competencia{Ano: 2024, Mes: 8},
 				VolumeFonte:                     72000,
 				VolumetriaDados:                 71000,
 				VolumetriaServicos:              70000,
@@ -136,15 +139,15 @@ func NewMockPortalRepository() PortalRepository {
 				VolumeCpfsUnicosDados:           1150,
 				VolumeCpfsUnicosServicos:        1120,
 				MediaMovelCpfsUnicos:            1090,
-				UltimoMesEnviado:                "08/2024",
+				UltimoMesEnviado:                model.Competencia{Ano: 2024, Mes: 8},
 				UltimaReferencia:                "08/2024",
 				UltimaVolumetriaEnviada:         71000,
 				MediaMovelUltimos12Meses:        69000,
 				Media:                           70000,
 				Minimo:                          68000,
-				MesCompetenciaMinimo:            "05/2024",
+				MesCompetenciaMinimo:            model.Competencia{Ano: 2024, Mes: 5},
 				Maximo:                          73000,
-				MesCompetenciaMaximo:            "06/2024",
+				MesCompetenciaMaximo:            model.Competencia{Ano: 2024, Mes: 6},
 				PercentualVolumetriaUltima:      100.5,
 				PercentualVolumetriaMediaMovel:  99.2,
 				PercentualVolumetriaMedia:       98.8,
@@ -162,8 +165,8 @@ func NewMockPortalRepository() PortalRepository {
 				Referencia:                      "10-11-2024",
 				Portal:                          "transparencia_pr",
 				Esfera:                          "ESTADUAL",
-				MesAnoEnvio:                     "11/2024",
-				MesAnoReferencia:                "09/2024",
+				MesAnoEnvio:                     model.Competencia{Ano: 2024, Mes: 11},
+				MesAnoReferencia:                model.Competencia{Ano: 2024, Mes: 9},
 				VolumeFonte:                     85000,
 				VolumetriaDados:                 82000,
 				VolumetriaServicos:              80000,
@@ -172,15 +175,15 @@ func NewMockPortalRepository() PortalRepository {
 				VolumeCpfsUnicosDados:           1400,
 				VolumeCpfsUnicosServicos:        1350,
 				MediaMovelCpfsUnicos:            1200,
-				UltimoMesEnviado:                "10/2024",
+				UltimoMesEnviado:                model.Competencia{Ano: 2024, Mes: 10},
 				UltimaReferencia:                "09/2024",
 				UltimaVolumetriaEnviada:         80000,
 				MediaMovelUltimos12Meses:        78000,
 				Media:                           79000,
 				Minimo:                          75000,
-				MesCompetenciaMinimo:            "04/2024",
+				MesCompetenciaMinimo:            model.Competencia{Ano: 2024, Mes: 4},
 				Maximo:                          85000,
-				MesCompetenciaMaximo:            "08/2024",
+				MesCompetenciaMaximo:            model.Competencia{Ano: 2024, Mes: 8},
 				PercentualVolumetriaUltima:      102.5,
 				PercentualVolumetriaMediaMovel:  105.1,
 				PercentualVolumetriaMedia:       103.8,
@@ -202,17 +205,217 @@ func (r *mockPortalRepository) InsertPortal(portal model.Portal) error {
 	return nil
 }
 
+func (r *mockPortalRepository) UpsertPortal(portal model.Portal) error {
+    for i, p := range r.portals {
+        if p.ID == portal.ID {
+            portal.Version = p.Version + 1
+            r.portals[i] = portal
+            return nil
+        }
+    }
+    portal.Version = 1
+    r.portals = append(r.portals, portal)
+    return nil
+}
+
+// UpsertPortalIfVersion reproduz a gravação condicional do MongoDB: sem o portal na versão esperada,
+// retorna ErrVersionConflict
+func (r *mockPortalRepository) UpsertPortalIfVersion(portal model.Portal, version int) error {
+    for i, p := range r.portals {
+        if p.ID == portal.ID {
+            if p.Version != version {
+                return ErrVersionConflict
+            }
+            portal.Version = version + 1
+            r.portals[i] = portal
+            return nil
+        }
+    }
+    return ErrVersionConflict
+}
+
 func (r *mockPortalRepository) GetAllPortals() ([]model.Portal, error) {
     return r.portals, nil
 }
 
+// FindPortals reproduz em memória a mesma semântica de filtros/ordenação/paginação do MongoDB
+func (r *mockPortalRepository) FindPortals(query model.PortalQuery) (model.PortalPage, error) {
+    matched := []model.Portal{}
+    for _, p := range r.portals {
+        if mockPortalMatches(p, query) {
+            matched = append(matched, p)
+        }
+    }
+
+    // Como no MongoDB, o _id desempata (e ordena as consultas sem ordenação)
+    sortFields := withIDTieBreaker(query.Sort)
+    sort.SliceStable(matched, func(i, j int) bool {
+        for _, s := range sortFields {
+            c := compareSortValues(portalSortValue(matched[i], s.Field), portalSortValue(matched[j], s.Field))
+            if c == 0 {
+                continue
+            }
+            if s.Desc {
+                return c > 0
+            }
+            return c < 0
+        }
+        return false
+    })
+
+    total := int64(len(matched))
+    start := query.Offset
+    if start > len(matched) {
+        start = len(matched)
+    }
+    end := len(matched)
+    if query.Limit > 0 && start+query.Limit < end {
+        end = start + query.Limit
+    }
+    return model.PortalPage{Items: matched[start:end], Total: total, Limit: query.Limit, Offset: query.Offset}, nil
+}
+
+func mockPortalMatches(p model.Portal, query model.PortalQuery) bool {
+    if query.Portal != "" && query.PortalExato && p.Portal != query.Portal {
+        return false
+    }
+    if query.Portal != "" && !strings.Contains(strings.ToLower(p.Portal), strings.ToLower(query.Portal)) {
+        return false
+    }
+    if query.Esfera != "" && p.Esfera != query.Esfera {
+        return false
+    }
+    if query.Status != "" && p.Status != query.Status {
+        return false
+    }
+    if query.Referencia != "" && p.Referencia != query.Referencia {
+        return false
+    }
+    if !query.DataEntrega.IsZero() && p.DataEntrega != query.DataEntrega {
+        return false
+    }
+    // Intervalos só incluem linhas com a data/competência preenchida (como no MongoDB)
+    if !query.DataEntregaDe.IsZero() || !query.DataEntregaAte.IsZero() {
+        if p.DataEntrega.IsZero() {
+            return false
+        }
+        if !query.DataEntregaDe.IsZero() && p.DataEntrega.Compare(query.DataEntregaDe) < 0 {
+            return false
+        }
+        if !query.DataEntregaAte.IsZero() && p.DataEntrega.Compare(query.DataEntregaAte.Next()) >= 0 {
+            return false
+        }
+    }
+    if !query.MesAnoReferencia.IsZero() && p.MesAnoReferencia != query.MesAnoReferencia {
+        return false
+    }
+    if !query.MesAnoReferenciaDe.IsZero() || !query.MesAnoReferenciaAte.IsZero() {
+        if !p.MesAnoReferencia.HasMonth() {
+            return false
+        }
+        if !query.MesAnoReferenciaDe.IsZero() && p.MesAnoReferencia.Compare(query.MesAnoReferenciaDe) < 0 {
+            return false
+        }
+        if !query.MesAnoReferenciaAte.IsZero() && p.MesAnoReferencia.Compare(query.MesAnoReferenciaAte) > 0 {
+            return false
+        }
+    }
+    if query.Enviar != nil && p.Enviar != *query.Enviar {
+        return false
+    }
+    if len(query.IDs) > 0 {
+        found := false
+        for _, id := range query.IDs {
+            if p.ID == id {
+                found = true
+                break
+            }
+        }
+        if !found {
+            return false
+        }
+    }
+    return true
+}
+
+// portalSortValue retorna o valor do campo (nome JSON/BSON) usado na ordenação em memória;
+// inteiros são convertidos para float64 para uma única regra de comparação numérica e
+// competências/datas usam a ordem cronológica, como no MongoDB
+func portalSortValue(p model.Portal, field string) interface{} {
+    switch field {
+    case "dataEntrega":
+        return p.DataEntrega
+    case "mesAnoEnvio":
+        return p.MesAnoEnvio
+    case "mesAnoReferencia":
+        return p.MesAnoReferencia
+    case "ultimoMesEnviado":
+        return p.UltimoMesEnviado
+    }
+    v, _ := p.FieldValue(field)
+    if n, ok := v.(int); ok {
+        return float64(n)
+    }
+    return v
+}
+
+func compareSortValues(a, b interface{}) int {
+    switch av := a.(type) {
+    case model.Competencia:
+        return av.Compare(b.(model.Competencia))
+    case model.Data:
+        return av.Compare(b.(model.Data))
+    case string:
+        return strings.Compare(av, b.(string))
+    case float64:
+        bv := b.(float64)
+        if av < bv {
+            return -1
+        }
+        if av > bv {
+            return 1
+        }
+    case bool:
+        bv := b.(bool)
+        if !av && bv {
+            return -1
+        }
+        if av && !bv {
+            return 1
+        }
+    }
+    return 0
+}
+
 func (r *mockPortalRepository) GetPortalByID(id string) (model.Portal, error) {
     for _, p := range r.portals {
         if p.ID == id { // busca simples pelo campo ID
             return p, nil
         }
     }
-    return model.Portal{}, fmt.Errorf("portal não encontrado: %s", id)
+    return model.Portal{}, fmt.Errorf("portal não encontrado: %s: %w", id, mongo.ErrNoDocuments)
+}
+
+func (r *mockPortalRepository) GetPortalHistory(portal string) ([]model.Portal, error) {
+    history := []model.Portal{}
+    for _, p := range r.portals {
+        if p.Portal == portal {
+            history = append(history, p)
+        }
+    }
+    return history, nil
+}
+
+func (r *mockPortalRepository) DistinctReferencias() ([]string, error) {
+    seen := map[string]bool{}
+    referencias := []string{}
+    for _, p := range r.portals {
+        if p.Referencia != "" && !seen[p.Referencia] {
+            seen[p.Referencia] = true
+            referencias = append(referencias, p.Referencia)
+        }
+    }
+    return referencias, nil
 }
 
 // UpdatePortalFields atualiza campos específicos em memória para o mock
@@ -228,6 +431,9 @@ func (r *mockPortalRepository) UpdatePortalFields(id string, fields bson.M) erro
             if v, ok := fields["status"].(string); ok {
                 r.portals[i].Status = v
             }
+            if v, ok := fields["statusManual"].(bool); ok {
+                r.portals[i].StatusManual = v
+            }
             if v, ok := fields["pulouCompetencia"].(bool); ok {
                 r.portals[i].PulouCompetencia = v
             }
@@ -237,8 +443,149 @@ func (r *mockPortalRepository) UpdatePortalFields(id string, fields bson.M) erro
             if v, ok := fields["novosDados"].(bool); ok {
                 r.portals[i].NovosDados = v
             }
+            r.portals[i].Version++
             return nil
         }
     }
     return fmt.Errorf("portal não encontrado: %s", id)
-}
\ No newline at end of file
+}
+// UpdateManyPortalFields aplica os campos a cada linha da consulta, com a mesma semântica do MongoDB
+func (r *mockPortalRepository) UpdateManyPortalFields(query model.PortalQuery, fields bson.M) (int64, error) {
+    var matched int64
+    for i, p := range r.portals {
+        if !mockPortalMatches(p, query) {
+            continue
+        }
+        raw, err := bson.Marshal(p)
+        if err != nil {
+            return matched, err
+        }
+        doc := bson.M{}
+        if err := bson.Unmarshal(raw, &doc); err != nil {
+            return matched, err
+        }
+        for k, v := range fields {
+            doc[k] = v
+        }
+        if restoresAutomaticStatus(fields) && p.StatusAutomatico != "" {
+            doc["status"] = p.StatusAutomatico
+        }
+        if raw, err = bson.Marshal(doc); err != nil {
+            return matched, err
+        }
+        var updated model.Portal
+        if err := bson.Unmarshal(raw, &updated); err != nil {
+            return matched, err
+        }
+        updated.Version = p.Version + 1
+        r.portals[i] = updated
+        matched++
+    }
+    return matched, nil
+}
+
+// Dashboard reproduz em memória a agregação do MongoDB (mesmas contagens, somas, médias e ordem)
+func (r *mockPortalRepository) Dashboard(referencia string) (model.DashboardSummary, error) {
+    summary := newDashboardSummary(referencia)
+    var somaIndiceDados, somaIndiceServicos float64
+    matched := []model.Portal{}
+    for _, p := range r.portals {
+        if mockPortalMatches(p, model.PortalQuery{Referencia: referencia}) {
+            matched = append(matched, p)
+        }
+    }
+    sort.SliceStable(matched, func(i, j int) bool {
+        if matched[i].Portal != matched[j].Portal {
+            return matched[i].Portal < matched[j].Portal
+        }
+        return matched[i].ID < matched[j].ID
+    })
+    for _, p := range matched {
+        summary.Total++
+        summary.PorStatus[p.Status]++
+        summary.PorEsfera[p.Esfera]++
+        if p.Enviar {
+            summary.Enviar++
+        } else {
+            summary.NaoEnviar++
+        }
+        summary.SomaVolumeFonte += int64(p.VolumeFonte)
+        summary.SomaVolumetriaDados += int64(p.VolumetriaDados)
+        summary.SomaVolumetriaServicos += int64(p.VolumetriaServicos)
+        somaIndiceDados += p.IndiceDados
+        somaIndiceServicos += p.IndiceServicos
+        highlighted := model.DashboardPortal{
+            ID:                    p.ID,
+            Portal:                p.Portal,
+            Esfera:                p.Esfera,
+            MesAnoReferencia:      p.MesAnoReferencia,
+            CompetenciasFaltantes: p.CompetenciasFaltantes,
+            DefasagemMeses:        p.DefasagemMeses,
+        }
+        if p.PulouCompetencia {
+            summary.PulouCompetencia = append(summary.PulouCompetencia, highlighted)
+        }
+        if p.DefasagemNosDados {
+            summary.DefasagemNosDados = append(summary.DefasagemNosDados, highlighted)
+        }
+    }
+    if summary.Total > 0 {
+        summary.MediaIndiceDados = somaIndiceDados / float64(summary.Total)
+        summary.MediaIndiceServicos = somaIndiceServicos / float64(summary.Total)
+    }
+    return summary, nil
+}
+
+// SearchPortals reproduz em memória a busca textual: mesmos campos e pesos do índice de texto
+func (r *mockPortalRepository) SearchPortals(q string, limit int) ([]model.PortalSearchHit, error) {
+    query := model.ParseSearchQuery(q)
+    hits := []model.PortalSearchHit{}
+    for _, p := range r.portals {
+        p := p
+        fields := searchFields(portalTextWeights, func(field string) string {
+            value, _ := p.FieldValue(field)
+            text, _ := value.(string)
+            return text
+        })
+        if score := query.Score(fields); score > 0 {
+            hits = append(hits, model.PortalSearchHit{Portal: p, Score: score})
+        }
+    }
+    sort.SliceStable(hits, func(i, j int) bool {
+        if hits[i].Score != hits[j].Score {
+            return hits[i].Score > hits[j].Score
+        }
+        return hits[i].Portal.ID < hits[j].Portal.ID
+    })
+    if limit > 0 && len(hits) > limit {
+        hits = hits[:limit]
+    }
+    return hits, nil
+}
+
+func (r *mockPortalRepository) DeletePortal(id string) error {
+    for i, p := range r.portals {
+        if p.ID == id {
+            r.portals = append(r.portals[:i], r.portals[i+1:]...)
+            return nil
+        }
+    }
+    return mongo.ErrNoDocuments
+}
+
+// DeleteLegacySeedPortals remove as linhas de exemplo gravadas sem _id (no MongoDB receberiam um
+// ObjectID); as demais linhas sem _id são mantidas
+func (r *mockPortalRepository) DeleteLegacySeedPortals() (int64, error) {
+    kept := r.portals[:0]
+    var removed int64
+    for _, p := range r.portals {
+        if p.ID == "" && p.Portal == "transparencia_al" && p.Esfera == "ESTADUAL" && p.MesAnoEnvio == (model.Competencia{Ano: 2024, Mes: 11}) &&
+            p.Referencia == "" && p.DataEntrega.IsZero() {
+            removed++
+            continue
+        }
+        kept = append(kept, p)
+    }
+    r.portals = kept
+    return removed, nil
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// mockRuleRepository mantém regras e versões em memória (DATA_SOURCE=mock e testes)
+type mockRuleRepository struct {
+	rules    []model.ValidationRule
+	ruleSets []model.RuleSet
+}
+
+func NewMockRuleRepository() RuleRepository {
+	return &mockRuleRepository{}
+}
+
+func (r *mockRuleRepository) ListRules() ([]model.ValidationRule, error) {
+	return append([]model.ValidationRule{}, r.rules...), nil
+}
+
+func (r *mockRuleRepository) GetRule(id primitive.ObjectID) (model.ValidationRule, error) {
+	for _, rule := range r.rules {
+		if rule.ID == id {
+			return rule, nil
+		}
+	}
+	return model.ValidationRule{}, mongo.ErrNoDocuments
+}
+
+func (r *mockRuleRepository) InsertRule(rule model.ValidationRule) (model.ValidationRule, error) {
+	rule.ID = primitive.NewObjectID()
+	r.rules = append(r.rules, rule)
+	return rule, nil
+}
+
+func (r *mockRuleRepository) UpdateRule(rule model.ValidationRule) error {
+	for i := range r.rules {
+		if r.rules[i].ID == rule.ID {
+			r.rules[i] = rule
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *mockRuleRepository) DeleteRule(id primitive.ObjectID) error {
+	for i := range r.rules {
+		if r.rules[i].ID == id {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return mongo.ErrNoDocuments
+}
+
+func (r *mockRuleRepository) InsertRuleSet(set model.RuleSet) error {
+	r.ruleSets = append(r.ruleSets, set)
+	return nil
+}
+
+func (r *mockRuleRepository) LatestRuleSet() (model.RuleSet, error) {
+	if len(r.ruleSets) == 0 {
+		return model.RuleSet{}, mongo.ErrNoDocuments
+	}
+	return r.ruleSets[len(r.ruleSets)-1], nil
+}
+
+func (r *mockRuleRepository) GetRuleSet(version int) (model.RuleSet, error) {
+	for _, set := range r.ruleSets {
+		if set.Version == version {
+			return set, nil
+		}
+	}
+	return model.RuleSet{}, mongo.ErrNoDocuments
+}
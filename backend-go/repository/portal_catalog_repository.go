@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PortalCatalogRepository persiste o cadastro mestre de portais (portal_catalog)
+type PortalCatalogRepository interface {
+	ListEntries() ([]model.PortalCatalogEntry, error)
+	GetEntry(id primitive.ObjectID) (model.PortalCatalogEntry, error)
+	// FindByKey retorna a entrada cujo nome canônico ou alias normalizado é key
+	// (mongo.ErrNoDocuments quando não há)
+	FindByKey(key string) (model.PortalCatalogEntry, error)
+	CountEntries() (int64, error)
+	InsertEntry(entry model.PortalCatalogEntry) (model.PortalCatalogEntry, error)
+	UpdateEntry(entry model.PortalCatalogEntry) error
+	DeleteEntry(id primitive.ObjectID) error
+}
+
+type portalCatalogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPortalCatalogRepositoryDB(db *mongo.Database) PortalCatalogRepository {
+	return &portalCatalogRepository{collection: db.Collection("portal_catalog")}
+}
+
+func (r *portalCatalogRepository) ListEntries() ([]model.PortalCatalogEntry, error) {
+	ctx := context.Background()
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "portal", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	entries := []model.PortalCatalogEntry{}
+	err = cursor.All(ctx, &entries)
+	return entries, err
+}
+
+func (r *portalCatalogRepository) GetEntry(id primitive.ObjectID) (model.PortalCatalogEntry, error) {
+	var entry model.PortalCatalogEntry
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&entry)
+	return entry, err
+}
+
+func (r *portalCatalogRepository) FindByKey(key string) (model.PortalCatalogEntry, error) {
+	var entry model.PortalCatalogEntry
+	err := r.collection.FindOne(context.Background(), bson.M{"chaves": key}).Decode(&entry)
+	return entry, err
+}
+
+func (r *portalCatalogRepository) CountEntries() (int64, error) {
+	return r.collection.EstimatedDocumentCount(context.Background())
+}
+
+func (r *portalCatalogRepository) InsertEntry(entry model.PortalCatalogEntry) (model.PortalCatalogEntry, error) {
+	entry.ID = primitive.NewObjectID()
+	_, err := r.collection.InsertOne(context.Background(), entry)
+	return entry, err
+}
+
+func (r *portalCatalogRepository) UpdateEntry(entry model.PortalCatalogEntry) error {
+	result, err := r.collection.ReplaceOne(context.Background(), bson.M{"_id": entry.ID}, entry)
+	if err == nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *portalCatalogRepository) DeleteEntry(id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err == nil && result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PortalChangeRepository guarda o histórico de edições das linhas de portal (coleção portal_changes)
+type PortalChangeRepository interface {
+	InsertChange(change model.PortalChange) (model.PortalChange, error)
+	// FindChanges retorna as alterações que atendem à consulta, da mais recente para a mais antiga
+	FindChanges(query model.PortalChangeQuery) ([]model.PortalChange, error)
+}
+
+type portalChangeRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPortalChangeRepositoryDB(db *mongo.Database) PortalChangeRepository {
+	return &portalChangeRepository{collection: db.Collection("portal_changes")}
+}
+
+func (r *portalChangeRepository) InsertChange(change model.PortalChange) (model.PortalChange, error) {
+	if change.ID.IsZero() {
+		change.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(context.Background(), change)
+	return change, err
+}
+
+func (r *portalChangeRepository) FindChanges(query model.PortalChangeQuery) ([]model.PortalChange, error) {
+	filter := bson.M{}
+	if query.PortalID != "" {
+		filter["portalId"] = query.PortalID
+	}
+	if !query.UserID.IsZero() {
+		filter["userId"] = query.UserID
+	}
+	em := bson.M{}
+	if !query.De.IsZero() {
+		em["$gte"] = query.De
+	}
+	if !query.Ate.IsZero() {
+		em["$lte"] = query.Ate
+	}
+	if len(em) > 0 {
+		filter["em"] = em
+	}
+
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "em", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	changes := []model.PortalChange{}
+	err = cursor.All(ctx, &changes)
+	return changes, err
+}
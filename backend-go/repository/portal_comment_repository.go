@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PortalCommentRepository persiste os comentários das linhas de portal (coleção portal_comments)
+type PortalCommentRepository interface {
+	InsertComment(comment model.PortalComment) (model.PortalComment, error)
+	// GetComment retorna mongo.ErrNoDocuments quando o comentário não existe
+	GetComment(id primitive.ObjectID) (model.PortalComment, error)
+	UpdateComment(comment model.PortalComment) error
+	DeleteComment(id primitive.ObjectID) error
+	// FindComments retorna os comentários em ordem cronológica (mais antigo primeiro)
+	FindComments(query model.PortalCommentQuery) ([]model.PortalComment, error)
+	// SearchComments faz a busca textual no texto dos comentários (índice de texto de EnsureIndexes)
+	// e retorna até limit comentários, do mais relevante para o menos relevante
+	SearchComments(q string, limit int) ([]model.PortalCommentSearchHit, error)
+}
+
+type portalCommentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPortalCommentRepositoryDB(db *mongo.Database) PortalCommentRepository {
+	return &portalCommentRepository{collection: db.Collection("portal_comments")}
+}
+
+func (r *portalCommentRepository) InsertComment(comment model.PortalComment) (model.PortalComment, error) {
+	if comment.ID.IsZero() {
+		comment.ID = primitive.NewObjectID()
+	}
+	_, err := r.collection.InsertOne(context.Background(), comment)
+	return comment, err
+}
+
+func (r *portalCommentRepository) GetComment(id primitive.ObjectID) (model.PortalComment, error) {
+	var comment model.PortalComment
+	err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&comment)
+	return comment, err
+}
+
+func (r *portalCommentRepository) UpdateComment(comment model.PortalComment) error {
+	result, err := r.collection.ReplaceOne(context.Background(), bson.M{"_id": comment.ID}, comment)
+	if err == nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *portalCommentRepository) DeleteComment(id primitive.ObjectID) error {
+	result, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err == nil && result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *portalCommentRepository) FindComments(query model.PortalCommentQuery) ([]model.PortalComment, error) {
+	filter := bson.M{"portal": bson.M{"$in": query.Portais}}
+	if query.SomenteAbertos {
+		filter["resolvido"] = false
+	}
+	ctx := context.Background()
+	opts := options.Find().SetSort(bson.D{{Key: "criadoEm", Value: 1}, {Key: "_id", Value: 1}})
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	comments := []model.PortalComment{}
+	err = cursor.All(ctx, &comments)
+	return comments, err
+}
+
+func (r *portalCommentRepository) SearchComments(q string, limit int) ([]model.PortalCommentSearchHit, error) {
+	ctx := context.Background()
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+		SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"$text": bson.M{"$search": q}}, opts)
+	if err != nil {
+		return nil, err
+	}
+	var docs []struct {
+		Comment model.PortalComment `bson:",inline"`
+		Score   float64             `bson:"score"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	hits := make([]model.PortalCommentSearchHit, 0, len(docs))
+	for _, d := range docs {
+		hits = append(hits, model.PortalCommentSearchHit{Comment: d.Comment, Score: d.Score})
+	}
+	return hits, nil
+}
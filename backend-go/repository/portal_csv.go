@@ -0,0 +1,245 @@
+package repository
+
+import (
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+)
+
+// PortalCSVSeparator é o separador do CSV de portais (formato do antigo repositório GCS)
+const PortalCSVSeparator = ';'
+
+// textField é um campo com representação textual própria (competências e datas)
+type textField interface {
+	encoding.TextMarshaler
+	encoding.TextUnmarshaler
+}
+
+// portalCSVColumn liga um cabeçalho do CSV (nome do campo JSON) ao campo do model.Portal.
+// Apenas um dos acessores é preenchido, conforme o tipo do campo.
+type portalCSVColumn struct {
+	header string
+	str    func(p *model.Portal) *string
+	text   func(p *model.Portal) textField
+	num    func(p *model.Portal) *int
+	dec    func(p *model.Portal) *float64
+	flag   func(p *model.Portal) *bool
+}
+
+var portalCSVColumns = []portalCSVColumn{
+	{header: "_id", str: func(p *model.Portal) *string { return &p.ID }},
+	{header: "referencia", str: func(p *model.Portal) *string { return &p.Referencia }},
+	{header: "dataEntrega", text: func(p *model.Portal) textField { return &p.DataEntrega }},
+	{header: "portal", str: func(p *model.Portal) *string { return &p.Portal }},
+	{header: "esfera", str: func(p *model.Portal) *string { return &p.Esfera }},
+	{header: "mesAnoEnvio", text: func(p *model.Portal) textField { return &p.MesAnoEnvio }},
+	{header: "mesAnoReferencia", text: func(p *model.Portal) textField { return &p.MesAnoReferencia }},
+	{header: "volumeFonte", num: func(p *model.Portal) *int { return &p.VolumeFonte }},
+	{header: "volumetriaDados", num: func(p *model.Portal) *int { return &p.VolumetriaDados }},
+	{header: "volumetriaServicos", num: func(p *model.Portal) *int { return &p.VolumetriaServicos }},
+	{header: "indiceDados", dec: func(p *model.Portal) *float64 { return &p.IndiceDados }},
+	{header: "indiceServicos", dec: func(p *model.Portal) *float64 { return &p.IndiceServicos }},
+	{header: "volumeCpfsUnicosDados", num: func(p *model.Portal) *int { return &p.VolumeCpfsUnicosDados }},
+	{header: "volumeCpfsUnicosServicos", num: func(p *model.Portal) *int { return &p.VolumeCpfsUnicosServicos }},
+	{header: "mediaMovelCpfsUnicos", num: func(p *model.Portal) *int { return &p.MediaMovelCpfsUnicos }},
+	{header: "ultimoMesEnviado", text: func(p *model.Portal) textField { return &p.UltimoMesEnviado }},
+	{header: "ultimaReferencia", str: func(p *model.Portal) *string { return &p.UltimaReferencia }},
+	{header: "ultimaVolumetriaEnviada", num: func(p *model.Portal) *int { return &p.UltimaVolumetriaEnviada }},
+	{header: "mediaMovelUltimos12Meses", num: func(p *model.Portal) *int { return &p.MediaMovelUltimos12Meses }},
+	{header: "media", num: func(p *model.Portal) *int { return &p.Media }},
+	{header: "minimo", num: func(p *model.Portal) *int { return &p.Minimo }},
+	{header: "mesCompetenciaMinimo", text: func(p *model.Portal) textField { return &p.MesCompetenciaMinimo }},
+	{header: "maximo", num: func(p *model.Portal) *int { return &p.Maximo }},
+	{header: "mesCompetenciaMaximo", text: func(p *model.Portal) textField { return &p.MesCompetenciaMaximo }},
+	{header: "percentualVolumetriaUltima", dec: func(p *model.Portal) *float64 { return &p.PercentualVolumetriaUltima }},
+	{header: "percentualVolumetriaMediaMovel", dec: func(p *model.Portal) *float64 { return &p.PercentualVolumetriaMediaMovel }},
+	{header: "percentualVolumetriaMedia", dec: func(p *model.Portal) *float64 { return &p.PercentualVolumetriaMedia }},
+	{header: "percentualVolumetriaMinimo", dec: func(p *model.Portal) *float64 { return &p.PercentualVolumetriaMinimo }},
+	{header: "percentualVolumetriaMaximo", dec: func(p *model.Portal) *float64 { return &p.PercentualVolumetriaMaximo }},
+	{header: "pulouCompetencia", flag: func(p *model.Portal) *bool { return &p.PulouCompetencia }},
+	{header: "defasagemNosDados", flag: func(p *model.Portal) *bool { return &p.DefasagemNosDados }},
+	{header: "novosDados", flag: func(p *model.Portal) *bool { return &p.NovosDados }},
+	{header: "status", str: func(p *model.Portal) *string { return &p.Status }},
+	{header: "observacaoTimeDados", str: func(p *model.Portal) *string { return &p.ObservacaoTimeDados }},
+	{header: "enviar", flag: func(p *model.Portal) *bool { return &p.Enviar }},
+}
+
+func (c portalCSVColumn) format(p *model.Portal) string {
+	switch {
+	case c.str != nil:
+		return *c.str(p)
+	case c.text != nil:
+		v, _ := c.text(p).MarshalText()
+		return string(v)
+	case c.num != nil:
+		return strconv.Itoa(*c.num(p))
+	case c.dec != nil:
+		return strconv.FormatFloat(*c.dec(p), 'f', -1, 64)
+	default:
+		return strconv.FormatBool(*c.flag(p))
+	}
+}
+
+// parse converte o valor textual para o campo; valores vazios mantêm o zero value
+func (c portalCSVColumn) parse(p *model.Portal, v string) error {
+	switch {
+	case c.str != nil:
+		*c.str(p) = v
+	case c.text != nil:
+		if err := c.text(p).UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("%s: %v", c.header, err)
+		}
+	case v == "":
+		return nil
+	case c.num != nil:
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("%s: número inteiro inválido %q", c.header, v)
+		}
+		*c.num(p) = n
+	case c.dec != nil:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("%s: número decimal inválido %q", c.header, v)
+		}
+		*c.dec(p) = n
+	default:
+		switch strings.ToLower(v) {
+		case "true", "1":
+			*c.flag(p) = true
+		case "false", "0":
+			*c.flag(p) = false
+		default:
+			return fmt.Errorf("%s: booleano inválido %q", c.header, v)
+		}
+	}
+	return nil
+}
+
+// PortalCSVEncoder escreve portais no formato CSV separado por ';'
+type PortalCSVEncoder struct {
+	w *csv.Writer
+}
+
+func NewPortalCSVEncoder(w io.Writer) *PortalCSVEncoder {
+	cw := csv.NewWriter(w)
+	cw.Comma = PortalCSVSeparator
+	return &PortalCSVEncoder{w: cw}
+}
+
+// Encode escreve o cabeçalho seguido de uma linha por portal
+func (e *PortalCSVEncoder) Encode(portals []model.Portal) error {
+	header := make([]string, len(portalCSVColumns))
+	for i, c := range portalCSVColumns {
+		header[i] = c.header
+	}
+	if err := e.w.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(portalCSVColumns))
+	for i := range portals {
+		for j, c := range portalCSVColumns {
+			record[j] = c.format(&portals[i])
+		}
+		if err := e.w.Write(record); err != nil {
+			return err
+		}
+	}
+	e.w.Flush()
+	return e.w.Error()
+}
+
+// PortalCSVDecoder lê portais do CSV separado por ';'. O cabeçalho é comparado sem
+// diferenciar maiúsculas (compatível com o parseCSVRecord do repositório GCS) e colunas
+// desconhecidas são ignoradas. Linhas inválidas são devolvidas em model.CSVRowError.
+type PortalCSVDecoder struct {
+	r  *csv.Reader
+	id func(dataEntrega, portal, mesAnoReferencia string, index int) string
+}
+
+func NewPortalCSVDecoder(r io.Reader) *PortalCSVDecoder {
+	cr := csv.NewReader(r)
+	cr.Comma = PortalCSVSeparator
+	cr.FieldsPerRecord = -1 // a validação do tamanho é feita por linha
+	return &PortalCSVDecoder{r: cr}
+}
+
+// WithID define como calcular o _id das linhas sem a coluna _id: a função recebe o texto das
+// células dataEntrega, portal e mesAnoReferencia como está no arquivo (sem espaços nas pontas) e
+// a posição da linha entre as válidas
+func (d *PortalCSVDecoder) WithID(id func(dataEntrega, portal, mesAnoReferencia string, index int) string) *PortalCSVDecoder {
+	d.id = id
+	return d
+}
+
+func (d *PortalCSVDecoder) Decode() ([]model.Portal, []model.CSVRowError, error) {
+	header, err := d.r.Read()
+	if err == io.EOF {
+		return []model.Portal{}, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("erro ao ler cabeçalho do CSV: %v", err)
+	}
+
+	byHeader := make(map[string]portalCSVColumn, len(portalCSVColumns))
+	for _, c := range portalCSVColumns {
+		byHeader[strings.ToLower(c.header)] = c
+	}
+	columns := make([]*portalCSVColumn, len(header))
+	for i, h := range header {
+		if c, ok := byHeader[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))]; ok {
+			c := c
+			columns[i] = &c
+		}
+	}
+
+	portals := []model.Portal{}
+	var rowErrors []model.CSVRowError
+	for {
+		record, err := d.r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				rowErrors = append(rowErrors, model.CSVRowError{Line: parseErr.Line, Error: parseErr.Err.Error()})
+				continue
+			}
+			return nil, nil, err
+		}
+		line, _ := d.r.FieldPos(0)
+		if len(record) != len(header) {
+			rowErrors = append(rowErrors, model.CSVRowError{Line: line, Error: fmt.Sprintf("linha com %d colunas, cabeçalho com %d", len(record), len(header))})
+			continue
+		}
+
+		var portal model.Portal
+		var fieldErrs []string
+		raw := map[string]string{}
+		for i, value := range record {
+			if columns[i] == nil {
+				continue
+			}
+			raw[columns[i].header] = strings.TrimSpace(value)
+			if err := columns[i].parse(&portal, strings.TrimSpace(value)); err != nil {
+				fieldErrs = append(fieldErrs, err.Error())
+			}
+		}
+		if len(fieldErrs) > 0 {
+			rowErrors = append(rowErrors, model.CSVRowError{Line: line, Error: strings.Join(fieldErrs, "; ")})
+			continue
+		}
+		if portal.ID == "" && d.id != nil {
+			portal.ID = d.id(raw["dataEntrega"], raw["portal"], raw["mesAnoReferencia"], len(portals))
+		}
+		portals = append(portals, portal)
+	}
+	return portals, rowErrors, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PortalHistoryRepository persiste a identidade dos portais (portal_identities) e
+// as entregas mensais (entregas), chaveadas por portal e competência.
+type PortalHistoryRepository interface {
+	UpsertIdentity(identity model.PortalIdentity) error
+	GetIdentity(portal string) (model.PortalIdentity, error)
+	ListIdentities() ([]model.PortalIdentity, error)
+	UpsertEntrega(entrega model.Entrega) error
+	GetEntrega(id string) (model.Entrega, error)
+	// GetEntregas retorna as entregas do portal ordenadas da competência mais antiga para a mais recente
+	GetEntregas(portal string) ([]model.Entrega, error)
+	// Clear remove todas as identidades e entregas (reversão da reconstrução)
+	Clear() error
+}
+
+type portalHistoryRepository struct {
+	identities *mongo.Collection
+	entregas   *mongo.Collection
+}
+
+func NewPortalHistoryRepositoryDB(db *mongo.Database) PortalHistoryRepository {
+	return &portalHistoryRepository{
+		identities: db.Collection("portal_identities"),
+		entregas:   db.Collection("entregas"),
+	}
+}
+
+func (r *portalHistoryRepository) UpsertIdentity(identity model.PortalIdentity) error {
+	filter := bson.M{"_id": identity.Portal}
+	update := bson.M{"$set": identity}
+	_, err := r.identities.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *portalHistoryRepository) GetIdentity(portal string) (model.PortalIdentity, error) {
+	var identity model.PortalIdentity
+	err := r.identities.FindOne(context.Background(), bson.M{"_id": portal}).Decode(&identity)
+	return identity, err
+}
+
+func (r *portalHistoryRepository) ListIdentities() ([]model.PortalIdentity, error) {
+	ctx := context.Background()
+	cursor, err := r.identities.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	identities := []model.PortalIdentity{}
+	err = cursor.All(ctx, &identities)
+	return identities, err
+}
+
+func (r *portalHistoryRepository) UpsertEntrega(entrega model.Entrega) error {
+	filter := bson.M{"_id": entrega.ID}
+	update := bson.M{"$set": entrega}
+	_, err := r.entregas.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+func (r *portalHistoryRepository) GetEntrega(id string) (model.Entrega, error) {
+	var entrega model.Entrega
+	err := r.entregas.FindOne(context.Background(), bson.M{"_id": id}).Decode(&entrega)
+	return entrega, err
+}
+
+func (r *portalHistoryRepository) GetEntregas(portal string) ([]model.Entrega, error) {
+	ctx := context.Background()
+	sortDoc := bson.D{{Key: "competencia", Value: 1}}
+	cursor, err := r.entregas.Find(ctx, bson.M{"portal": portal}, options.Find().SetSort(sortDoc))
+	if err != nil {
+		return nil, err
+	}
+	entregas := []model.Entrega{}
+	err = cursor.All(ctx, &entregas)
+	return entregas, err
+}
+
+func (r *portalHistoryRepository) Clear() error {
+	ctx := context.Background()
+	if err := r.entregas.Drop(ctx); err != nil {
+		return err
+	}
+	return r.identities.Drop(ctx)
+}
@@ -2,8 +2,10 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"log"
 	"os"
+	"regexp"
 
 	"solid_react_golang_mongo_project/backend-go/model"
 
@@ -12,11 +14,40 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// ErrVersionConflict indica que o portal foi gravado por outra operação desde a versão esperada
+var ErrVersionConflict = errors.New("versão do portal divergente")
+
+// PortalRepository persiste as linhas de portal. Upserts e atualizações de campos incrementam
+// Portal.Version (o valor recebido no portal é ignorado).
 type PortalRepository interface {
     InsertPortal(portal model.Portal) error
+    UpsertPortal(portal model.Portal) error
+    // UpsertPortalIfVersion grava o portal existente apenas se a versão gravada ainda for version;
+    // caso contrário retorna ErrVersionConflict
+    UpsertPortalIfVersion(portal model.Portal, version int) error
     GetAllPortals() ([]model.Portal, error)
+    FindPortals(query model.PortalQuery) (model.PortalPage, error)
     GetPortalByID(id string) (model.Portal, error)
+    GetPortalHistory(portal string) ([]model.Portal, error)
+    DistinctReferencias() ([]string, error)
     UpdatePortalFields(id string, fields bson.M) error
+    // UpdateManyPortalFields aplica os campos a todas as linhas da consulta (filtros apenas) em uma
+    // única operação e retorna quantas foram encontradas. "statusManual": false sem "status"
+    // devolve a cada linha o seu statusAutomatico.
+    UpdateManyPortalFields(query model.PortalQuery, fields bson.M) (int64, error)
+    // DeletePortal remove a linha pelo _id (mongo.ErrNoDocuments quando não existe)
+    DeletePortal(id string) error
+    // DeleteLegacySeedPortals remove as cópias da linha de exemplo gravadas a cada inicialização
+    // pelo antigo InitializeData: _id gerado pelo MongoDB (ObjectID), transparencia_al/ESTADUAL,
+    // envio 11/2024 e sem referência nem data de entrega. Outras linhas com ObjectID são mantidas.
+    DeleteLegacySeedPortals() (int64, error)
+    // Dashboard resume as linhas da referência (todas, quando vazia): contagens por status, esfera
+    // e enviar, somas de volume, médias dos índices e as linhas com pulouCompetencia ou
+    // defasagemNosDados, ordenadas por portal
+    Dashboard(referencia string) (model.DashboardSummary, error)
+    // SearchPortals faz a busca textual (índice de texto de EnsureIndexes) e retorna até
+    // limit linhas, da mais relevante para a menos relevante, sem os trechos destacados
+    SearchPortals(q string, limit int) ([]model.PortalSearchHit, error)
 }
 
 type portalRepository struct {
@@ -56,6 +87,51 @@ func (r *portalRepository) InsertPortal(portal model.Portal) error {
 	return err
 }
 
+// UpsertPortal grava o portal pelo _id, criando-o quando ainda não existe
+func (r *portalRepository) UpsertPortal(portal model.Portal) error {
+    update, err := portalVersionedUpdate(portal)
+    if err != nil {
+        return err
+    }
+    filter := bson.M{"_id": portal.ID}
+    _, err = r.collection.UpdateOne(context.Background(), filter, update, options.Update().SetUpsert(true))
+    return err
+}
+
+func (r *portalRepository) UpsertPortalIfVersion(portal model.Portal, version int) error {
+    update, err := portalVersionedUpdate(portal)
+    if err != nil {
+        return err
+    }
+    // Documentos anteriores ao controle de versão não têm o campo: valem como versão 0
+    filter := bson.M{"_id": portal.ID, "version": version}
+    if version == 0 {
+        filter["version"] = bson.M{"$in": bson.A{0, nil}}
+    }
+    result, err := r.collection.UpdateOne(context.Background(), filter, update)
+    if err != nil {
+        return err
+    }
+    if result.MatchedCount == 0 {
+        return ErrVersionConflict
+    }
+    return nil
+}
+
+// portalVersionedUpdate monta o $set do portal (sem o campo version) com o incremento da versão
+func portalVersionedUpdate(portal model.Portal) (bson.M, error) {
+    raw, err := bson.Marshal(portal)
+    if err != nil {
+        return nil, err
+    }
+    doc := bson.M{}
+    if err := bson.Unmarshal(raw, &doc); err != nil {
+        return nil, err
+    }
+    delete(doc, "version")
+    return bson.M{"$set": doc, "$inc": bson.M{"version": 1}}, nil
+}
+
 func (r *portalRepository) GetAllPortals() ([]model.Portal, error) {
     cursor, err := r.collection.Find(context.Background(), bson.M{})
     if err != nil {
@@ -66,16 +142,338 @@ func (r *portalRepository) GetAllPortals() ([]model.Portal, error) {
     return portals, err
 }
 
+// FindPortals aplica filtros, ordenação e paginação diretamente no MongoDB
+func (r *portalRepository) FindPortals(query model.PortalQuery) (model.PortalPage, error) {
+    ctx := context.Background()
+    filter := portalQueryFilter(query)
+
+    total, err := r.collection.CountDocuments(ctx, filter)
+    if err != nil {
+        return model.PortalPage{}, err
+    }
+
+    findOpts := options.Find()
+    sortDoc := bson.D{}
+    for _, s := range withIDTieBreaker(query.Sort) {
+        dir := 1
+        if s.Desc {
+            dir = -1
+        }
+        sortDoc = append(sortDoc, bson.E{Key: s.Field, Value: dir})
+    }
+    findOpts.SetSort(sortDoc)
+    if query.Offset > 0 {
+        findOpts.SetSkip(int64(query.Offset))
+    }
+    if query.Limit > 0 {
+        findOpts.SetLimit(int64(query.Limit))
+    }
+
+    cursor, err := r.collection.Find(ctx, filter, findOpts)
+    if err != nil {
+        return model.PortalPage{}, err
+    }
+    portals := []model.Portal{}
+    if err = cursor.All(ctx, &portals); err != nil {
+        return model.PortalPage{}, err
+    }
+    return model.PortalPage{Items: portals, Total: total, Limit: query.Limit, Offset: query.Offset}, nil
+}
+
+// withIDTieBreaker completa a ordenação com _id, para que a paginação por offset sobre chaves
+// repetidas (esfera, status...) não repita nem pule linhas entre páginas
+func withIDTieBreaker(sort []model.SortField) []model.SortField {
+    for _, s := range sort {
+        if s.Field == "_id" {
+            return sort
+        }
+    }
+    return append(append([]model.SortField{}, sort...), model.SortField{Field: "_id"})
+}
+
+// portalQueryFilter converte a PortalQuery no filtro BSON equivalente
+func portalQueryFilter(query model.PortalQuery) bson.M {
+    filter := bson.M{}
+    if query.Portal != "" && query.PortalExato {
+        filter["portal"] = query.Portal
+    } else if query.Portal != "" {
+        filter["portal"] = bson.M{"$regex": regexp.QuoteMeta(query.Portal), "$options": "i"}
+    }
+    if query.Esfera != "" {
+        filter["esfera"] = query.Esfera
+    }
+    if query.Status != "" {
+        filter["status"] = query.Status
+    }
+    if query.Referencia != "" {
+        filter["referencia"] = query.Referencia
+    }
+    if !query.DataEntrega.IsZero() {
+        filter["dataEntrega"] = query.DataEntrega
+    }
+    // Datas e competências são gravadas como "yyyy-MM-dd"/"YYYY-MM": a comparação de strings
+    // segue a ordem cronológica. O limite final usa o valor seguinte (exclusivo) para incluir
+    // o mês inteiro quando a data tem precisão de mês.
+    if !query.DataEntregaDe.IsZero() || !query.DataEntregaAte.IsZero() {
+        cond := bson.M{}
+        if !query.DataEntregaDe.IsZero() {
+            cond["$gte"] = query.DataEntregaDe
+        }
+        if !query.DataEntregaAte.IsZero() {
+            cond["$lt"] = query.DataEntregaAte.Next()
+        }
+        filter["dataEntrega"] = cond
+    }
+    if !query.MesAnoReferencia.IsZero() {
+        filter["mesAnoReferencia"] = query.MesAnoReferencia
+    }
+    // O marcador "SEM NOVOS DADOS" fica fora de qualquer intervalo: os limites padrão só
+    // abrangem valores "YYYY-MM"
+    if !query.MesAnoReferenciaDe.IsZero() || !query.MesAnoReferenciaAte.IsZero() {
+        cond := bson.M{"$gte": "0000-00", "$lte": "9999-99"}
+        if !query.MesAnoReferenciaDe.IsZero() {
+            cond["$gte"] = query.MesAnoReferenciaDe
+        }
+        if !query.MesAnoReferenciaAte.IsZero() {
+            cond["$lte"] = query.MesAnoReferenciaAte
+        }
+        filter["mesAnoReferencia"] = cond
+    }
+    if query.Enviar != nil {
+        filter["enviar"] = *query.Enviar
+    }
+    if len(query.IDs) > 0 {
+        filter["_id"] = bson.M{"$in": query.IDs}
+    }
+    return filter
+}
+
 func (r *portalRepository) GetPortalByID(id string) (model.Portal, error) {
     var portal model.Portal
     err := r.collection.FindOne(context.Background(), bson.M{"_id": id}).Decode(&portal)
     return portal, err
 }
 
+// GetPortalHistory retorna todas as linhas (entregas) de um portal pelo nome exato
+func (r *portalRepository) GetPortalHistory(portal string) ([]model.Portal, error) {
+    cursor, err := r.collection.Find(context.Background(), bson.M{"portal": portal})
+    if err != nil {
+        return nil, err
+    }
+    portals := []model.Portal{}
+    err = cursor.All(context.Background(), &portals)
+    return portals, err
+}
+
+// DistinctReferencias lista as referências (entregas) presentes nas linhas de portal
+func (r *portalRepository) DistinctReferencias() ([]string, error) {
+    values, err := r.collection.Distinct(context.Background(), "referencia", bson.M{"referencia": bson.M{"$ne": ""}})
+    if err != nil {
+        return nil, err
+    }
+    referencias := make([]string, 0, len(values))
+    for _, v := range values {
+        if s, ok := v.(string); ok {
+            referencias = append(referencias, s)
+        }
+    }
+    return referencias, nil
+}
+
 // UpdatePortalFields atualiza campos específicos de um portal identificado por _id
 func (r *portalRepository) UpdatePortalFields(id string, fields bson.M) error {
     filter := bson.M{"_id": id}
-    update := bson.M{"$set": fields}
+    update := bson.M{"$set": fields, "$inc": bson.M{"version": 1}}
     _, err := r.collection.UpdateOne(context.Background(), filter, update)
     return err
 }
+
+func (r *portalRepository) UpdateManyPortalFields(query model.PortalQuery, fields bson.M) (int64, error) {
+    set := bson.M{}
+    for k, v := range fields {
+        // $literal: no pipeline, textos iniciados por "$" seriam lidos como caminhos de campo
+        set[k] = bson.M{"$literal": v}
+    }
+    // Pipeline de atualização: permite referenciar o statusAutomatico de cada documento
+    if restoresAutomaticStatus(fields) {
+        set["status"] = bson.M{"$ifNull": bson.A{"$statusAutomatico", "$status"}}
+    }
+    set["version"] = bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}}
+    update := mongo.Pipeline{{{Key: "$set", Value: set}}}
+    result, err := r.collection.UpdateMany(context.Background(), portalQueryFilter(query), update)
+    if err != nil {
+        return 0, err
+    }
+    return result.MatchedCount, nil
+}
+
+// restoresAutomaticStatus indica se a atualização remove o override manual sem informar um status
+func restoresAutomaticStatus(fields bson.M) bool {
+    manual, ok := fields["statusManual"].(bool)
+    _, hasStatus := fields["status"]
+    return ok && !manual && !hasStatus
+}
+
+// Dashboard calcula o resumo em uma única agregação: cada $facet produz uma parte do resultado
+func (r *portalRepository) Dashboard(referencia string) (model.DashboardSummary, error) {
+    ctx := context.Background()
+    countBy := func(field string) bson.A {
+        return bson.A{bson.M{"$group": bson.M{"_id": "$" + field, "count": bson.M{"$sum": 1}}}}
+    }
+    highlight := func(flag string) bson.A {
+        return bson.A{
+            bson.M{"$match": bson.M{flag: true}},
+            bson.M{"$sort": bson.D{{Key: "portal", Value: 1}, {Key: "_id", Value: 1}}},
+            bson.M{"$project": bson.M{"portal": 1, "esfera": 1, "mesAnoReferencia": 1, "competenciasFaltantes": 1, "defasagemMeses": 1}},
+        }
+    }
+    pipeline := mongo.Pipeline{
+        {{Key: "$match", Value: portalQueryFilter(model.PortalQuery{Referencia: referencia})}},
+        {{Key: "$facet", Value: bson.M{
+            "porStatus": countBy("status"),
+            "porEsfera": countBy("esfera"),
+            "porEnviar": countBy("enviar"),
+            "totais": bson.A{bson.M{"$group": bson.M{
+                "_id":                    nil,
+                "total":                  bson.M{"$sum": 1},
+                "somaVolumeFonte":        bson.M{"$sum": "$volumeFonte"},
+                "somaVolumetriaDados":    bson.M{"$sum": "$volumetriaDados"},
+                "somaVolumetriaServicos": bson.M{"$sum": "$volumetriaServicos"},
+                "mediaIndiceDados":       bson.M{"$avg": "$indiceDados"},
+                "mediaIndiceServicos":    bson.M{"$avg": "$indiceServicos"},
+            }}},
+            "pulouCompetencia":  highlight("pulouCompetencia"),
+            "defasagemNosDados": highlight("defasagemNosDados"),
+        }}},
+    }
+    cursor, err := r.collection.Aggregate(ctx, pipeline)
+    if err != nil {
+        return model.DashboardSummary{}, err
+    }
+    type statusCount struct {
+        Key   string `bson:"_id"`
+        Count int    `bson:"count"`
+    }
+    var facets []struct {
+        PorStatus []statusCount `bson:"porStatus"`
+        PorEsfera []statusCount `bson:"porEsfera"`
+        PorEnviar []struct {
+            Key   bool `bson:"_id"`
+            Count int  `bson:"count"`
+        } `bson:"porEnviar"`
+        Totais []struct {
+            Total                  int     `bson:"total"`
+            SomaVolumeFonte        int64   `bson:"somaVolumeFonte"`
+            SomaVolumetriaDados    int64   `bson:"somaVolumetriaDados"`
+            SomaVolumetriaServicos int64   `bson:"somaVolumetriaServicos"`
+            MediaIndiceDados       float64 `bson:"mediaIndiceDados"`
+            MediaIndiceServicos    float64 `bson:"mediaIndiceServicos"`
+        } `bson:"totais"`
+        PulouCompetencia  []model.DashboardPortal `bson:"pulouCompetencia"`
+        DefasagemNosDados []model.DashboardPortal `bson:"defasagemNosDados"`
+    }
+    if err := cursor.All(ctx, &facets); err != nil {
+        return model.DashboardSummary{}, err
+    }
+
+    summary := newDashboardSummary(referencia)
+    if len(facets) == 0 {
+        return summary, nil
+    }
+    f := facets[0]
+    for _, c := range f.PorStatus {
+        summary.PorStatus[c.Key] += c.Count
+    }
+    for _, c := range f.PorEsfera {
+        summary.PorEsfera[c.Key] += c.Count
+    }
+    for _, c := range f.PorEnviar {
+        if c.Key {
+            summary.Enviar += c.Count
+        } else {
+            summary.NaoEnviar += c.Count
+        }
+    }
+    if len(f.Totais) > 0 {
+        t := f.Totais[0]
+        summary.Total = t.Total
+        summary.SomaVolumeFonte = t.SomaVolumeFonte
+        summary.SomaVolumetriaDados = t.SomaVolumetriaDados
+        summary.SomaVolumetriaServicos = t.SomaVolumetriaServicos
+        summary.MediaIndiceDados = t.MediaIndiceDados
+        summary.MediaIndiceServicos = t.MediaIndiceServicos
+    }
+    summary.PulouCompetencia = append(summary.PulouCompetencia, f.PulouCompetencia...)
+    summary.DefasagemNosDados = append(summary.DefasagemNosDados, f.DefasagemNosDados...)
+    return summary, nil
+}
+
+// newDashboardSummary devolve o resumo vazio (mapas e listas inicializados para o JSON)
+func newDashboardSummary(referencia string) model.DashboardSummary {
+    return model.DashboardSummary{
+        Referencia:        referencia,
+        PorStatus:         map[string]int{},
+        PorEsfera:         map[string]int{},
+        PulouCompetencia:  []model.DashboardPortal{},
+        DefasagemNosDados: []model.DashboardPortal{},
+    }
+}
+
+func (r *portalRepository) SearchPortals(q string, limit int) ([]model.PortalSearchHit, error) {
+    ctx := context.Background()
+    score := bson.M{"$meta": "textScore"}
+    opts := options.Find().
+        SetProjection(bson.M{"score": score}).
+        SetSort(bson.D{{Key: "score", Value: score}, {Key: "_id", Value: 1}}).
+        SetLimit(int64(limit))
+    cursor, err := r.collection.Find(ctx, bson.M{"$text": bson.M{"$search": q}}, opts)
+    if err != nil {
+        return nil, err
+    }
+    var docs []struct {
+        Portal model.Portal `bson:",inline"`
+        Score  float64      `bson:"score"`
+    }
+    if err := cursor.All(ctx, &docs); err != nil {
+        return nil, err
+    }
+    hits := make([]model.PortalSearchHit, 0, len(docs))
+    for _, d := range docs {
+        hits = append(hits, model.PortalSearchHit{Portal: d.Portal, Score: d.Score})
+    }
+    return hits, nil
+}
+
+func (r *portalRepository) DeletePortal(id string) error {
+	res, err := r.collection.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+	if res.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return nil
+}
+
+// legacySeedFilter casa a linha de exemplo do antigo InitializeData. Ela pode ter sido gravada
+// antes das tags bson (campos em minúsculas) e antes da normalização das competências.
+func legacySeedFilter() bson.M {
+	envio := bson.M{"$in": bson.A{"11/2024", "2024-11"}}
+	empty := bson.M{"$in": bson.A{"", nil}}
+	return bson.M{
+		"_id":         bson.M{"$type": "objectId"},
+		"portal":      "transparencia_al",
+		"esfera":      "ESTADUAL",
+		"referencia":  empty,
+		"dataEntrega": empty,
+		"$or":         bson.A{bson.M{"mesAnoEnvio": envio}, bson.M{"mesanoenvio": envio}},
+	}
+}
+
+func (r *portalRepository) DeleteLegacySeedPortals() (int64, error) {
+	res, err := r.collection.DeleteMany(context.Background(), legacySeedFilter())
+	if err != nil {
+		return 0, err
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RuleRepository persiste as regras de validação (validation_rules) e as versões
+// imutáveis do conjunto de regras (rule_set_versions).
+type RuleRepository interface {
+	ListRules() ([]model.ValidationRule, error)
+	GetRule(id primitive.ObjectID) (model.ValidationRule, error)
+	InsertRule(rule model.ValidationRule) (model.ValidationRule, error)
+	UpdateRule(rule model.ValidationRule) error
+	DeleteRule(id primitive.ObjectID) error
+	InsertRuleSet(set model.RuleSet) error
+	// LatestRuleSet retorna mongo.ErrNoDocuments quando nenhuma versão foi gravada
+	LatestRuleSet() (model.RuleSet, error)
+	GetRuleSet(version int) (model.RuleSet, error)
+}
+
+type ruleRepository struct {
+	rules    *mongo.Collection
+	ruleSets *mongo.Collection
+}
+
+func NewRuleRepositoryDB(db *mongo.Database) RuleRepository {
+	return &ruleRepository{
+		rules:    db.Collection("validation_rules"),
+		ruleSets: db.Collection("rule_set_versions"),
+	}
+}
+
+func (r *ruleRepository) ListRules() ([]model.ValidationRule, error) {
+	ctx := context.Background()
+	cursor, err := r.rules.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "criadoEm", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	rules := []model.ValidationRule{}
+	err = cursor.All(ctx, &rules)
+	return rules, err
+}
+
+func (r *ruleRepository) GetRule(id primitive.ObjectID) (model.ValidationRule, error) {
+	var rule model.ValidationRule
+	err := r.rules.FindOne(context.Background(), bson.M{"_id": id}).Decode(&rule)
+	return rule, err
+}
+
+func (r *ruleRepository) InsertRule(rule model.ValidationRule) (model.ValidationRule, error) {
+	rule.ID = primitive.NewObjectID()
+	_, err := r.rules.InsertOne(context.Background(), rule)
+	return rule, err
+}
+
+func (r *ruleRepository) UpdateRule(rule model.ValidationRule) error {
+	result, err := r.rules.ReplaceOne(context.Background(), bson.M{"_id": rule.ID}, rule)
+	if err == nil && result.MatchedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *ruleRepository) DeleteRule(id primitive.ObjectID) error {
+	result, err := r.rules.DeleteOne(context.Background(), bson.M{"_id": id})
+	if err == nil && result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	return err
+}
+
+func (r *ruleRepository) InsertRuleSet(set model.RuleSet) error {
+	_, err := r.ruleSets.InsertOne(context.Background(), set)
+	return err
+}
+
+func (r *ruleRepository) LatestRuleSet() (model.RuleSet, error) {
+	var set model.RuleSet
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := r.ruleSets.FindOne(context.Background(), bson.M{}, opts).Decode(&set)
+	return set, err
+}
+
+func (r *ruleRepository) GetRuleSet(version int) (model.RuleSet, error) {
+	var set model.RuleSet
+	err := r.ruleSets.FindOne(context.Background(), bson.M{"_id": version}).Decode(&set)
+	return set, err
+}
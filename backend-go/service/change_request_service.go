@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrChangeRequestNotFound  = errors.New("solicitação de alteração não encontrada")
+	ErrChangeRequestPending   = errors.New("já existe uma solicitação pendente para o portal")
+	ErrChangeRequestDecided   = errors.New("a solicitação já foi decidida")
+	ErrChangeRequestForbidden = errors.New("usuário não pode decidir esta solicitação")
+)
+
+// ChangeRequestService implementa a aprovação em duas pessoas das alterações de "enviar"
+// feitas por editores: a alteração fica pendente até um aprovador (outro usuário, com um dos
+// papéis configurados) aprová-la ou rejeitá-la.
+type ChangeRequestService interface {
+	// RequiresApproval indica se as alterações de "enviar" do usuário passam por aprovação
+	RequiresApproval(user *model.User) bool
+	// RequestEnviar cria a solicitação pendente; retorna nil quando o valor já é o proposto
+	RequestEnviar(portalID string, enviar bool, user *model.User) (*model.ChangeRequest, error)
+	// RequestEnviarBulk cria solicitações para as linhas da consulta cujo valor difere do proposto.
+	// Linhas com solicitação pendente são ignoradas. Com dryRun, apenas monta as solicitações.
+	RequestEnviarBulk(query model.PortalQuery, enviar bool, user *model.User, dryRun bool) ([]model.ChangeRequest, error)
+	Approve(id primitive.ObjectID, user *model.User) (model.ChangeRequest, error)
+	Reject(id primitive.ObjectID, user *model.User, motivo string) (model.ChangeRequest, error)
+	GetChangeRequest(id primitive.ObjectID) (model.ChangeRequest, error)
+	ListChangeRequests(query model.ChangeRequestQuery) ([]model.ChangeRequest, error)
+	// AttachPending preenche AlteracaoPendente das linhas (uma consulta para a página inteira)
+	AttachPending(portals []model.Portal) error
+}
+
+type changeRequestService struct {
+	repo          repository.ChangeRequestRepository
+	portalRepo    repository.PortalRepository
+	portalService PortalService
+	deliveries    DeliveryService
+	approverRoles map[string]bool
+}
+
+func NewChangeRequestService(repo repository.ChangeRequestRepository, portalRepo repository.PortalRepository, portalService PortalService, deliveries DeliveryService, approverRoles []string) ChangeRequestService {
+	roles := map[string]bool{}
+	for _, role := range approverRoles {
+		roles[role] = true
+	}
+	return &changeRequestService{repo: repo, portalRepo: portalRepo, portalService: portalService, deliveries: deliveries, approverRoles: roles}
+}
+
+func (s *changeRequestService) RequiresApproval(user *model.User) bool {
+	return user.Role == "editor"
+}
+
+func (s *changeRequestService) RequestEnviar(portalID string, enviar bool, user *model.User) (*model.ChangeRequest, error) {
+	row, err := s.portalRepo.GetPortalByID(portalID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrPortalNotFound, portalID)
+	}
+	if row.Enviar == enviar {
+		return nil, nil
+	}
+	if err := s.deliveries.CheckWritable(row.Referencia); err != nil {
+		return nil, err
+	}
+	pending, err := s.repo.FindChangeRequests(model.ChangeRequestQuery{Status: model.ChangeRequestPending, PortalIDs: []string{row.ID}})
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) > 0 {
+		return nil, ErrChangeRequestPending
+	}
+	created, err := s.repo.InsertChangeRequest(newEnviarRequest(row, enviar, user))
+	if err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func newEnviarRequest(row model.Portal, enviar bool, user *model.User) model.ChangeRequest {
+	version := row.Version
+	return model.ChangeRequest{
+		PortalID:      row.ID,
+		Portal:        row.Portal,
+		Referencia:    row.Referencia,
+		Campo:         "enviar",
+		ValorAtual:    row.Enviar,
+		ValorProposto: enviar,
+		VersaoPortal:  &version,
+		Status:        model.ChangeRequestPending,
+		SolicitadoPor: user.ID,
+		SolicitadoEm:  time.Now(),
+	}
+}
+
+func (s *changeRequestService) RequestEnviarBulk(query model.PortalQuery, enviar bool, user *model.User, dryRun bool) ([]model.ChangeRequest, error) {
+	page, err := s.portalRepo.FindPortals(query)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(page.Items))
+	for _, p := range page.Items {
+		if err := s.deliveries.CheckWritable(p.Referencia); err != nil {
+			return nil, err
+		}
+		ids = append(ids, p.ID)
+	}
+	pending := map[string]bool{}
+	if len(ids) > 0 {
+		open, err := s.repo.FindChangeRequests(model.ChangeRequestQuery{Status: model.ChangeRequestPending, PortalIDs: ids})
+		if err != nil {
+			return nil, err
+		}
+		for _, req := range open {
+			pending[req.PortalID] = true
+		}
+	}
+
+	requests := []model.ChangeRequest{}
+	for _, p := range page.Items {
+		if p.Enviar == enviar || pending[p.ID] {
+			continue
+		}
+		req := newEnviarRequest(p, enviar, user)
+		if !dryRun {
+			if req, err = s.repo.InsertChangeRequest(req); err != nil {
+				return requests, err
+			}
+		}
+		requests = append(requests, req)
+	}
+	return requests, nil
+}
+
+// pendingRequest carrega a solicitação e confere se o usuário pode decidi-la
+func (s *changeRequestService) pendingRequest(id primitive.ObjectID, user *model.User) (model.ChangeRequest, error) {
+	req, err := s.GetChangeRequest(id)
+	if err != nil {
+		return req, err
+	}
+	if req.Status != model.ChangeRequestPending {
+		return req, ErrChangeRequestDecided
+	}
+	if !s.approverRoles[user.Role] {
+		return req, fmt.Errorf("%w: papel %s não aprova alterações", ErrChangeRequestForbidden, user.Role)
+	}
+	if req.SolicitadoPor == user.ID {
+		return req, fmt.Errorf("%w: o solicitante não pode decidir a própria solicitação", ErrChangeRequestForbidden)
+	}
+	return req, nil
+}
+
+func (s *changeRequestService) Approve(id primitive.ObjectID, user *model.User) (model.ChangeRequest, error) {
+	req, err := s.pendingRequest(id, user)
+	if err != nil {
+		return req, err
+	}
+	// A decisão é gravada antes (compare-and-set): uma rejeição ou outra aprovação concorrente
+	// que ganhe a disputa não deixa a linha alterada
+	approved, err := s.decide(req, model.ChangeRequestApproved, user, "")
+	if err != nil {
+		return approved, err
+	}
+	// A alteração é aplicada em nome do aprovador, e fica no histórico de edições do portal. Se a
+	// linha mudou desde o pedido (ou a entrega foi travada), a solicitação fica como FAILED.
+	if err := s.portalService.UpdatePortalFieldsMap(req.PortalID, bson.M{req.Campo: req.ValorProposto}, user.ID, req.VersaoPortal); err != nil {
+		failed := approved
+		failed.Status = model.ChangeRequestFailed
+		failed.Motivo = err.Error()
+		if markErr := s.repo.UpdateChangeRequestIfStatus(failed, model.ChangeRequestApproved); markErr != nil {
+			return approved, fmt.Errorf("%w (a solicitação não pôde ser marcada como FAILED: %v)", err, markErr)
+		}
+		return failed, err
+	}
+	return approved, nil
+}
+
+func (s *changeRequestService) Reject(id primitive.ObjectID, user *model.User, motivo string) (model.ChangeRequest, error) {
+	req, err := s.pendingRequest(id, user)
+	if err != nil {
+		return req, err
+	}
+	return s.decide(req, model.ChangeRequestRejected, user, motivo)
+}
+
+func (s *changeRequestService) decide(req model.ChangeRequest, status string, user *model.User, motivo string) (model.ChangeRequest, error) {
+	now := time.Now()
+	req.Status = status
+	req.DecididoPor = &user.ID
+	req.DecididoEm = &now
+	req.Motivo = motivo
+	err := s.repo.UpdateChangeRequestIfStatus(req, model.ChangeRequestPending)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return req, ErrChangeRequestDecided
+	}
+	return req, err
+}
+
+func (s *changeRequestService) GetChangeRequest(id primitive.ObjectID) (model.ChangeRequest, error) {
+	req, err := s.repo.GetChangeRequest(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return req, ErrChangeRequestNotFound
+	}
+	return req, err
+}
+
+func (s *changeRequestService) ListChangeRequests(query model.ChangeRequestQuery) ([]model.ChangeRequest, error) {
+	return s.repo.FindChangeRequests(query)
+}
+
+func (s *changeRequestService) AttachPending(portals []model.Portal) error {
+	if len(portals) == 0 {
+		return nil
+	}
+	ids := make([]string, 0, len(portals))
+	for _, p := range portals {
+		ids = append(ids, p.ID)
+	}
+	pending, err := s.repo.FindChangeRequests(model.ChangeRequestQuery{Status: model.ChangeRequestPending, PortalIDs: ids})
+	if err != nil {
+		return err
+	}
+	byPortal := map[string]model.ChangeRequest{}
+	for _, req := range pending {
+		byPortal[req.PortalID] = req
+	}
+	for i := range portals {
+		if req, ok := byPortal[portals[i].ID]; ok {
+			portals[i].AlteracaoPendente = &req
+		}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestChangeRequests_TwoPersonApproval(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deps := newTestPortalServiceDeps(repo)
+    portals := NewPortalService(repo, deps)
+    svc := NewChangeRequestService(repository.NewMockChangeRequestRepository(), repo, portals, deps.Deliveries, []string{"admin"})
+    editor := &model.User{ID: primitive.NewObjectID(), Role: "editor"}
+    otherEditor := &model.User{ID: primitive.NewObjectID(), Role: "editor"}
+    admin := &model.User{ID: primitive.NewObjectID(), Role: "admin"}
+
+    if err := repo.UpsertPortal(model.Portal{ID: "cr1", Portal: "transparencia_pe", Referencia: "11/2025", MesAnoReferencia: mustCompetencia("10/2025")}); err != nil {
+        t.Fatalf("UpsertPortal falhou: %v", err)
+    }
+    if !svc.RequiresApproval(editor) || svc.RequiresApproval(admin) {
+        t.Fatalf("somente editores dependem de aprovação")
+    }
+    if req, err := svc.RequestEnviar("cr1", false, editor); err != nil || req != nil {
+        t.Fatalf("valor igual ao atual não deveria gerar solicitação: %+v (%v)", req, err)
+    }
+
+    req, err := svc.RequestEnviar("cr1", true, editor)
+    if err != nil || req == nil || req.Status != model.ChangeRequestPending {
+        t.Fatalf("RequestEnviar falhou: %+v (%v)", req, err)
+    }
+    if _, err := svc.RequestEnviar("cr1", true, otherEditor); !errors.Is(err, ErrChangeRequestPending) {
+        t.Fatalf("esperava ErrChangeRequestPending, obtive %v", err)
+    }
+    if row, _ := repo.GetPortalByID("cr1"); row.Enviar {
+        t.Fatalf("enviar não deveria mudar antes da aprovação")
+    }
+    page := []model.Portal{{ID: "cr1"}}
+    if err := svc.AttachPending(page); err != nil || page[0].AlteracaoPendente == nil || page[0].AlteracaoPendente.ID != req.ID {
+        t.Fatalf("alteração pendente não anexada: %+v (%v)", page[0].AlteracaoPendente, err)
+    }
+
+    // Com aprovadores só admins, outro editor também não decide
+    if _, err := svc.Approve(req.ID, otherEditor); !errors.Is(err, ErrChangeRequestForbidden) {
+        t.Fatalf("esperava ErrChangeRequestForbidden para editor, obtive %v", err)
+    }
+    approved, err := svc.Approve(req.ID, admin)
+    if err != nil || approved.Status != model.ChangeRequestApproved || approved.DecididoPor == nil || *approved.DecididoPor != admin.ID {
+        t.Fatalf("Approve falhou: %+v (%v)", approved, err)
+    }
+    if row, _ := repo.GetPortalByID("cr1"); !row.Enviar {
+        t.Fatalf("enviar deveria ter sido aplicado na aprovação")
+    }
+    if _, err := svc.Reject(req.ID, admin, "tarde demais"); !errors.Is(err, ErrChangeRequestDecided) {
+        t.Fatalf("esperava ErrChangeRequestDecided, obtive %v", err)
+    }
+}
+
+func TestChangeRequests_EditorApproversAndSelfApproval(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deps := newTestPortalServiceDeps(repo)
+    svc := NewChangeRequestService(repository.NewMockChangeRequestRepository(), repo, NewPortalService(repo, deps), deps.Deliveries, []string{"admin", "editor"})
+    editor := &model.User{ID: primitive.NewObjectID(), Role: "editor"}
+    otherEditor := &model.User{ID: primitive.NewObjectID(), Role: "editor"}
+
+    for _, id := range []string{"cr2", "cr3"} {
+        if err := repo.UpsertPortal(model.Portal{ID: id, Portal: "portal_" + id, Referencia: "11/2025", Enviar: true}); err != nil {
+            t.Fatalf("UpsertPortal falhou: %v", err)
+        }
+    }
+    requests, err := svc.RequestEnviarBulk(model.PortalQuery{Referencia: "11/2025"}, false, editor, false)
+    if err != nil || len(requests) != 2 {
+        t.Fatalf("RequestEnviarBulk falhou: %+v (%v)", requests, err)
+    }
+    if _, err := svc.Approve(requests[0].ID, editor); !errors.Is(err, ErrChangeRequestForbidden) {
+        t.Fatalf("o solicitante não pode aprovar a própria solicitação, obtive %v", err)
+    }
+    rejected, err := svc.Reject(requests[1].ID, otherEditor, "portal ainda em validação")
+    if err != nil || rejected.Status != model.ChangeRequestRejected || rejected.Motivo == "" {
+        t.Fatalf("Reject falhou: %+v (%v)", rejected, err)
+    }
+    if _, err := svc.Approve(requests[0].ID, otherEditor); err != nil {
+        t.Fatalf("Approve por outro editor falhou: %v", err)
+    }
+    pending, _ := svc.ListChangeRequests(model.ChangeRequestQuery{Status: model.ChangeRequestPending})
+    if len(pending) != 0 {
+        t.Fatalf("não deveria restar solicitação pendente: %+v", pending)
+    }
+}
+
+func TestChangeRequests_ApproveAfterPortalEditFails(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deps := newTestPortalServiceDeps(repo)
+    portals := NewPortalService(repo, deps)
+    requests := repository.NewMockChangeRequestRepository()
+    svc := NewChangeRequestService(requests, repo, portals, deps.Deliveries, []string{"admin"})
+    editor := &model.User{ID: primitive.NewObjectID(), Role: "editor"}
+    admin := &model.User{ID: primitive.NewObjectID(), Role: "admin"}
+
+    if err := repo.UpsertPortal(model.Portal{ID: "cr4", Portal: "transparencia_pb", Referencia: "11/2025"}); err != nil {
+        t.Fatalf("UpsertPortal falhou: %v", err)
+    }
+    req, err := svc.RequestEnviar("cr4", true, editor)
+    if err != nil || req == nil || req.VersaoPortal == nil {
+        t.Fatalf("RequestEnviar deveria guardar a versão da linha: %+v (%v)", req, err)
+    }
+    // A linha é editada depois do pedido: a aprovação não pode sobrescrevê-la
+    if err := portals.UpdatePortalFieldsMap("cr4", bson.M{"observacaoTimeDados": "revisado"}, admin.ID, nil); err != nil {
+        t.Fatalf("UpdatePortalFieldsMap falhou: %v", err)
+    }
+    failed, err := svc.Approve(req.ID, admin)
+    if !errors.Is(err, ErrVersionConflict) || failed.Status != model.ChangeRequestFailed || failed.Motivo == "" {
+        t.Fatalf("esperava FAILED com ErrVersionConflict, obtive %+v (%v)", failed, err)
+    }
+    if row, _ := repo.GetPortalByID("cr4"); row.Enviar {
+        t.Fatalf("enviar não deveria mudar quando a aprovação falha")
+    }
+    if stored, _ := svc.GetChangeRequest(req.ID); stored.Status != model.ChangeRequestFailed {
+        t.Fatalf("a solicitação deveria ficar gravada como FAILED: %+v", stored)
+    }
+    // A decisão já tomada não pode ser refeita, mas um novo pedido é aceito
+    if _, err := svc.Reject(req.ID, admin, ""); !errors.Is(err, ErrChangeRequestDecided) {
+        t.Fatalf("esperava ErrChangeRequestDecided, obtive %v", err)
+    }
+    if again, err := svc.RequestEnviar("cr4", true, editor); err != nil || again == nil {
+        t.Fatalf("novo pedido deveria ser aceito após a falha: %+v (%v)", again, err)
+    }
+}
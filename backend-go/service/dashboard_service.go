@@ -0,0 +1,26 @@
+package service
+
+import (
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+)
+
+// DashboardService fornece os totais da tela de listagem, calculados no banco em vez de no navegador
+type DashboardService interface {
+	// Summary resume as linhas da referência; sem referência, considera todas as linhas
+	Summary(referencia string) (model.DashboardSummary, error)
+}
+
+type dashboardService struct {
+	portalRepo repository.PortalRepository
+}
+
+func NewDashboardService(portalRepo repository.PortalRepository) DashboardService {
+	return &dashboardService{portalRepo: portalRepo}
+}
+
+func (s *dashboardService) Summary(referencia string) (model.DashboardSummary, error) {
+	return s.portalRepo.Dashboard(strings.TrimSpace(referencia))
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+    "math"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func TestDashboardSummary(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    repo.InsertPortal(model.Portal{ID: "outra", Portal: "transparencia_ce", Referencia: "11-2025", Esfera: "ESTADUAL", Status: "ERROR", VolumeFonte: 10, IndiceDados: 50, PulouCompetencia: true})
+    svc := NewDashboardService(repo)
+
+    summary, err := svc.Summary(" 10-11-2024 ")
+    if err != nil {
+        t.Fatalf("Summary falhou: %v", err)
+    }
+    if summary.Referencia != "10-11-2024" || summary.Total != 5 || summary.Enviar != 5 || summary.NaoEnviar != 0 {
+        t.Fatalf("contagens inesperadas: %+v", summary)
+    }
+    if summary.PorStatus["OK"] != 3 || summary.PorStatus["WARNING"] != 2 || summary.PorEsfera["ESTADUAL"] != 3 || summary.PorEsfera["MUNICIPAL"] != 2 {
+        t.Fatalf("agrupamentos inesperados: %+v %+v", summary.PorStatus, summary.PorEsfera)
+    }
+    if summary.SomaVolumeFonte != 383655 {
+        t.Fatalf("soma de volumeFonte inesperada: %d", summary.SomaVolumeFonte)
+    }
+    if math.Abs(summary.MediaIndiceDados-95.0274) > 1e-9 {
+        t.Fatalf("média de indiceDados inesperada: %v", summary.MediaIndiceDados)
+    }
+    if len(summary.PulouCompetencia) != 1 || summary.PulouCompetencia[0].Portal != "transparencia_rj" {
+        t.Fatalf("pulouCompetencia inesperado: %+v", summary.PulouCompetencia)
+    }
+    // Destaques ordenados por portal
+    if len(summary.DefasagemNosDados) != 2 || summary.DefasagemNosDados[0].Portal != "transparencia_mg" || summary.DefasagemNosDados[1].Portal != "transparencia_sp" {
+        t.Fatalf("defasagemNosDados inesperado: %+v", summary.DefasagemNosDados)
+    }
+
+    // Sem referência, todas as linhas entram no resumo
+    all, _ := svc.Summary("")
+    if all.Total != 6 || all.PorStatus["ERROR"] != 1 || all.SomaVolumeFonte != 383665 || len(all.PulouCompetencia) != 2 {
+        t.Fatalf("resumo geral inesperado: %+v", all)
+    }
+
+    empty, _ := svc.Summary("01-1999")
+    if empty.Total != 0 || empty.MediaIndiceDados != 0 || empty.PorStatus == nil || empty.PulouCompetencia == nil {
+        t.Fatalf("resumo vazio inesperado: %+v", empty)
+    }
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+)
+
+// ErrInvalidDiff indica parâmetros inválidos na comparação de entregas
+var ErrInvalidDiff = errors.New("comparação de entregas inválida")
+
+// DefaultDiffThreshold é o limiar padrão (em %) para listar variações de volume
+const DefaultDiffThreshold = 10.0
+
+// diffVolumeFields são os campos de volume comparados entre as entregas
+var diffVolumeFields = []string{"volumeFonte", "volumetriaDados", "volumetriaServicos"}
+
+// DeliveryDiffService compara duas entregas: portais que entraram ou saíram, transições de
+// status e variações de volume acima do limiar
+type DeliveryDiffService interface {
+	// Compare junta as linhas das entregas pelo nome do portal. Quando um portal tem mais de uma
+	// linha na entrega, vale a de competência mais recente.
+	Compare(from, to string, threshold float64) (model.DeliveryDiff, error)
+	// ExportCSV escreve a comparação em CSV separado por ';', uma linha por diferença
+	ExportCSV(w io.Writer, diff model.DeliveryDiff) error
+}
+
+type deliveryDiffService struct {
+	deliveries DeliveryService
+	portalRepo repository.PortalRepository
+}
+
+func NewDeliveryDiffService(deliveries DeliveryService, portalRepo repository.PortalRepository) DeliveryDiffService {
+	return &deliveryDiffService{deliveries: deliveries, portalRepo: portalRepo}
+}
+
+func (s *deliveryDiffService) Compare(from, to string, threshold float64) (model.DeliveryDiff, error) {
+	if strings.TrimSpace(from) == "" || strings.TrimSpace(to) == "" {
+		return model.DeliveryDiff{}, fmt.Errorf("%w: from e to são obrigatórios", ErrInvalidDiff)
+	}
+	if threshold < 0 || math.IsNaN(threshold) {
+		return model.DeliveryDiff{}, fmt.Errorf("%w: threshold não pode ser negativo", ErrInvalidDiff)
+	}
+	before, fromRef, err := s.portalsByName(from)
+	if err != nil {
+		return model.DeliveryDiff{}, err
+	}
+	after, toRef, err := s.portalsByName(to)
+	if err != nil {
+		return model.DeliveryDiff{}, err
+	}
+
+	diff := model.DeliveryDiff{
+		From:             fromRef,
+		To:               toRef,
+		LimiarPercentual: threshold,
+		PortaisFrom:      len(before),
+		PortaisTo:        len(after),
+		Novos:            []model.DeliveryDiffPortal{},
+		Removidos:        []model.DeliveryDiffPortal{},
+		MudancasStatus:   []model.DeliveryStatusChange{},
+		VariacoesVolume:  []model.DeliveryVolumeChange{},
+	}
+	for _, name := range sortedPortalNames(before, after) {
+		old, hadOld := before[name]
+		cur, hasCur := after[name]
+		switch {
+		case !hadOld:
+			diff.Novos = append(diff.Novos, diffPortal(cur))
+		case !hasCur:
+			diff.Removidos = append(diff.Removidos, diffPortal(old))
+		default:
+			if old.Status != cur.Status {
+				diff.MudancasStatus = append(diff.MudancasStatus, model.DeliveryStatusChange{Portal: name, Esfera: cur.Esfera, De: old.Status, Para: cur.Status})
+			}
+			for _, field := range diffVolumeFields {
+				if change, ok := volumeChange(old, cur, field, threshold); ok {
+					diff.VariacoesVolume = append(diff.VariacoesVolume, change)
+				}
+			}
+		}
+	}
+	return diff, nil
+}
+
+// portalsByName carrega as linhas da entrega indexadas pelo nome do portal
+func (s *deliveryDiffService) portalsByName(referencia string) (map[string]model.Portal, string, error) {
+	delivery, err := s.deliveries.GetDelivery(referencia)
+	if err != nil {
+		return nil, "", err
+	}
+	page, err := s.portalRepo.FindPortals(model.PortalQuery{Referencia: delivery.Referencia})
+	if err != nil {
+		return nil, "", err
+	}
+	byName := map[string]model.Portal{}
+	for _, p := range page.Items {
+		existing, ok := byName[p.Portal]
+		if !ok || p.MesAnoReferencia.Compare(existing.MesAnoReferencia) > 0 ||
+			p.MesAnoReferencia.Compare(existing.MesAnoReferencia) == 0 && p.ID > existing.ID {
+			byName[p.Portal] = p
+		}
+	}
+	return byName, delivery.Referencia, nil
+}
+
+func sortedPortalNames(sets ...map[string]model.Portal) []string {
+	seen := map[string]bool{}
+	names := []string{}
+	for _, set := range sets {
+		for name := range set {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func diffPortal(p model.Portal) model.DeliveryDiffPortal {
+	return model.DeliveryDiffPortal{
+		ID:                 p.ID,
+		Portal:             p.Portal,
+		Esfera:             p.Esfera,
+		MesAnoReferencia:   p.MesAnoReferencia,
+		Status:             p.Status,
+		VolumetriaServicos: p.VolumetriaServicos,
+	}
+}
+
+// volumeChange compara o campo entre as linhas; a variação entra quando supera o limiar (sobre o
+// valor anterior) ou quando o valor anterior é zero e o novo não
+func volumeChange(old, cur model.Portal, field string, threshold float64) (model.DeliveryVolumeChange, bool) {
+	oldValue, _ := old.FieldValue(field)
+	curValue, _ := cur.FieldValue(field)
+	de, _ := oldValue.(int)
+	para, _ := curValue.(int)
+	change := model.DeliveryVolumeChange{Portal: cur.Portal, Esfera: cur.Esfera, Campo: field, De: de, Para: para, Delta: para - de}
+	if change.Delta == 0 {
+		return change, false
+	}
+	if de == 0 {
+		return change, true
+	}
+	percentual := float64(change.Delta) / float64(de) * 100
+	change.Percentual = &percentual
+	return change, math.Abs(percentual) > threshold
+}
+
+func (s *deliveryDiffService) ExportCSV(w io.Writer, diff model.DeliveryDiff) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = repository.PortalCSVSeparator
+	records := [][]string{{"tipo", "portal", "esfera", "campo", "de", "para", "delta", "percentual"}}
+	for _, p := range diff.Novos {
+		records = append(records, []string{"NOVO", p.Portal, p.Esfera, "mesAnoReferencia", "", p.MesAnoReferencia.String(), "", ""})
+	}
+	for _, p := range diff.Removidos {
+		records = append(records, []string{"REMOVIDO", p.Portal, p.Esfera, "mesAnoReferencia", p.MesAnoReferencia.String(), "", "", ""})
+	}
+	for _, c := range diff.MudancasStatus {
+		records = append(records, []string{"STATUS", c.Portal, c.Esfera, "status", c.De, c.Para, "", ""})
+	}
+	for _, c := range diff.VariacoesVolume {
+		percentual := ""
+		if c.Percentual != nil {
+			percentual = strconv.FormatFloat(*c.Percentual, 'f', 2, 64)
+		}
+		records = append(records, []string{"VOLUME", c.Portal, c.Esfera, c.Campo, strconv.Itoa(c.De), strconv.Itoa(c.Para), strconv.Itoa(c.Delta), percentual})
+	}
+	return cw.WriteAll(records)
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+    "bytes"
+    "errors"
+    "strings"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func TestDeliveryDiff_CompareAndExportCSV(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    // Entrega seguinte: transparencia_al muda de status e perde volume; transparencia_ce é nova
+    repo.InsertPortal(model.Portal{ID: "al", Referencia: "12-2024", Portal: "transparencia_al", Esfera: "ESTADUAL", MesAnoReferencia: mustCompetencia("10/2024"),
+        Status: "WARNING", VolumeFonte: 70655, VolumetriaDados: 70000, VolumetriaServicos: 50000})
+    repo.InsertPortal(model.Portal{ID: "ce-old", Referencia: "12-2024", Portal: "transparencia_ce", Esfera: "ESTADUAL", MesAnoReferencia: mustCompetencia("09/2024"), Status: "ERROR"})
+    repo.InsertPortal(model.Portal{ID: "ce", Referencia: "12-2024", Portal: "transparencia_ce", Esfera: "ESTADUAL", MesAnoReferencia: mustCompetencia("10/2024"), Status: "OK"})
+    svc := NewDeliveryDiffService(NewDeliveryService(repository.NewMockDeliveryRepository(), repo), repo)
+
+    diff, err := svc.Compare("10-11-2024", "12-2024", DefaultDiffThreshold)
+    if err != nil {
+        t.Fatalf("Compare falhou: %v", err)
+    }
+    if diff.PortaisFrom != 5 || diff.PortaisTo != 2 {
+        t.Fatalf("contagem de portais inesperada: %+v", diff)
+    }
+    // Com duas linhas na entrega, vale a competência mais recente
+    if len(diff.Novos) != 1 || diff.Novos[0].ID != "ce" || len(diff.Removidos) != 4 || diff.Removidos[0].Portal != "transparencia_mg" {
+        t.Fatalf("novos/removidos inesperados: %+v / %+v", diff.Novos, diff.Removidos)
+    }
+    if len(diff.MudancasStatus) != 1 || diff.MudancasStatus[0].De != "OK" || diff.MudancasStatus[0].Para != "WARNING" {
+        t.Fatalf("mudanças de status inesperadas: %+v", diff.MudancasStatus)
+    }
+    // volumetriaDados variou 3,4% (abaixo do limiar); volumetriaServicos caiu 26%
+    if len(diff.VariacoesVolume) != 1 || diff.VariacoesVolume[0].Campo != "volumetriaServicos" || diff.VariacoesVolume[0].Delta != -17701 {
+        t.Fatalf("variações de volume inesperadas: %+v", diff.VariacoesVolume)
+    }
+
+    all, _ := svc.Compare("10-11-2024", "12-2024", 0)
+    if len(all.VariacoesVolume) != 2 {
+        t.Fatalf("limiar zero deveria listar toda variação: %+v", all.VariacoesVolume)
+    }
+
+    var buf bytes.Buffer
+    if err := svc.ExportCSV(&buf, diff); err != nil {
+        t.Fatalf("ExportCSV falhou: %v", err)
+    }
+    lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+    if len(lines) != 8 || lines[0] != "tipo;portal;esfera;campo;de;para;delta;percentual" {
+        t.Fatalf("CSV inesperado:\n%s", buf.String())
+    }
+    if !strings.Contains(buf.String(), "VOLUME;transparencia_al;ESTADUAL;volumetriaServicos;67701;50000;-17701;-26.15") {
+        t.Fatalf("linha de volume ausente:\n%s", buf.String())
+    }
+
+    if _, err := svc.Compare("", "12-2024", DefaultDiffThreshold); !errors.Is(err, ErrInvalidDiff) {
+        t.Fatalf("esperava ErrInvalidDiff, obtive %v", err)
+    }
+    if _, err := svc.Compare("10-11-2024", "01-1999", DefaultDiffThreshold); !errors.Is(err, ErrDeliveryNotFound) {
+        t.Fatalf("esperava ErrDeliveryNotFound, obtive %v", err)
+    }
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ErrReferenciaNotFound indica que nenhuma linha de portal pertence à referência informada
+var ErrReferenciaNotFound = errors.New("referência não encontrada")
+
+// DeliveryExportService gera a planilha final de uma entrega no layout original do cliente
+type DeliveryExportService interface {
+	ExportXLSX(w io.Writer, referencia string) error
+}
+
+type deliveryExportService struct {
+	portalService PortalService
+}
+
+func NewDeliveryExportService(portalService PortalService) DeliveryExportService {
+	return &deliveryExportService{portalService: portalService}
+}
+
+// Formatos numéricos: o Excel exibe separador de milhar/decimal conforme o locale (pt-BR: 70.655 / 95,64)
+const (
+	xlsxIntFormat   = "#,##0"
+	xlsxFloatFormat = "#,##0.00"
+)
+
+// deliveryExportColumns são as colunas do layout do cliente (sem _id/referencia, que não existem na planilha)
+func deliveryExportColumns() []portalColumn {
+	cols := make([]portalColumn, 0, len(portalColumns))
+	for _, c := range portalColumns {
+		if c.Field == "_id" || c.Field == "referencia" {
+			continue
+		}
+		cols = append(cols, c)
+	}
+	return cols
+}
+
+// ExportXLSX escreve uma aba por DataEntrega com os cabeçalhos pt-BR do importador.
+// A linha 1 traz o título e a linha 2 os cabeçalhos, de modo que a planilha possa ser
+// reimportada com as opções padrão (headerRow=2).
+func (s *deliveryExportService) ExportXLSX(w io.Writer, referencia string) error {
+	portals, err := s.findByReferencia(referencia)
+	if err != nil {
+		return err
+	}
+
+	f := excelize.NewFile()
+	defer f.Close()
+
+	intStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: strPtr(xlsxIntFormat)})
+	if err != nil {
+		return err
+	}
+	floatStyle, err := f.NewStyle(&excelize.Style{CustomNumFmt: strPtr(xlsxFloatFormat)})
+	if err != nil {
+		return err
+	}
+	headerStyle, err := f.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return err
+	}
+
+	columns := deliveryExportColumns()
+	sheets := groupByDataEntrega(portals)
+	used := map[string]bool{}
+	for i, group := range sheets {
+		sheetName := deliverySheetName(group.dataEntrega)
+		for n := 2; used[sheetName]; n++ {
+			sheetName = fmt.Sprintf("%s (%d)", deliverySheetName(group.dataEntrega), n)
+		}
+		used[sheetName] = true
+		if i == 0 {
+			if err := f.SetSheetName("Sheet1", sheetName); err != nil {
+				return err
+			}
+		} else if _, err := f.NewSheet(sheetName); err != nil {
+			return err
+		}
+
+		title := fmt.Sprintf("ENTREGA ITAÚ - RELATÓRIO BOTS - Referência %s", referencia)
+		if err := f.SetCellValue(sheetName, "A1", title); err != nil {
+			return err
+		}
+		for c, col := range columns {
+			cell, _ := excelize.CoordinatesToCellName(c+1, 2)
+			if err := f.SetCellValue(sheetName, cell, col.Aliases[1]); err != nil {
+				return err
+			}
+			if err := f.SetCellStyle(sheetName, cell, cell, headerStyle); err != nil {
+				return err
+			}
+		}
+
+		for r := range group.portals {
+			p := &group.portals[r]
+			for c, col := range columns {
+				cell, _ := excelize.CoordinatesToCellName(c+1, r+3)
+				value, _ := p.FieldValue(col.Field)
+				style := 0
+				switch v := value.(type) {
+				case int:
+					style = intStyle
+				case float64:
+					style = floatStyle
+				case bool:
+					value = boolToSimNao(v)
+				}
+				if err := f.SetCellValue(sheetName, cell, value); err != nil {
+					return err
+				}
+				if style != 0 {
+					if err := f.SetCellStyle(sheetName, cell, cell, style); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	_, err = f.WriteTo(w)
+	return err
+}
+
+// findByReferencia busca as linhas da referência; como "/" não trafega em path params,
+// aceita também a forma com "-" (ex.: "11-2025" para "11/2025").
+func (s *deliveryExportService) findByReferencia(referencia string) ([]model.Portal, error) {
+	sort := []model.SortField{{Field: "dataEntrega"}, {Field: "portal"}, {Field: "mesAnoReferencia"}}
+	page, err := s.portalService.FindPortals(model.PortalQuery{Referencia: referencia, Sort: sort})
+	if err != nil {
+		return nil, err
+	}
+	if page.Total == 0 && strings.Contains(referencia, "-") {
+		page, err = s.portalService.FindPortals(model.PortalQuery{Referencia: strings.ReplaceAll(referencia, "-", "/"), Sort: sort})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if page.Total == 0 {
+		return nil, ErrReferenciaNotFound
+	}
+	return page.Items, nil
+}
+
+type dataEntregaGroup struct {
+	dataEntrega string
+	portals     []model.Portal
+}
+
+// groupByDataEntrega agrupa preservando a ordem de primeira ocorrência
+func groupByDataEntrega(portals []model.Portal) []dataEntregaGroup {
+	var groups []dataEntregaGroup
+	index := map[string]int{}
+	for _, p := range portals {
+		key := p.DataEntrega.String()
+		i, ok := index[key]
+		if !ok {
+			i = len(groups)
+			index[key] = i
+			groups = append(groups, dataEntregaGroup{dataEntrega: key})
+		}
+		groups[i].portals = append(groups[i].portals, p)
+	}
+	return groups
+}
+
+var invalidSheetChars = regexp.MustCompile(`[\\/?*\[\]:]`)
+
+// deliverySheetName gera o nome da aba ("Entrega 10112025" para 10/11/2025), o inverso de extractDeliveryDate
+func deliverySheetName(dataEntrega string) string {
+	if dataEntrega == "" {
+		return "Entrega"
+	}
+	digits := strings.NewReplacer("/", "", "-", "").Replace(dataEntrega)
+	name := "Entrega " + invalidSheetChars.ReplaceAllString(digits, "")
+	if len(name) > 31 {
+		name = name[:31]
+	}
+	return name
+}
+
+func boolToSimNao(v bool) string {
+	if v {
+		return "Sim"
+	}
+	return "Não"
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrDeliveryNotFound    = errors.New("entrega não encontrada")
+	ErrDeliveryExists      = errors.New("entrega já existe")
+	ErrInvalidDelivery     = errors.New("entrega inválida")
+	ErrInvalidTransition   = errors.New("transição de estado inválida")
+	ErrTransitionForbidden = errors.New("transição permitida somente para admins")
+	ErrDeliveryLocked      = errors.New("entrega fechada: linhas somente leitura")
+)
+
+// DeliveryService controla o ciclo de vida das entregas (OPEN → IN_REVIEW → CLOSED → SENT)
+type DeliveryService interface {
+	ListDeliveries() ([]model.Delivery, error)
+	GetDelivery(referencia string) (model.Delivery, error)
+	CreateDelivery(referencia string, dataEntrega model.Data) (model.Delivery, error)
+	// EnsureDelivery cria a entrega OPEN para a referência de uma linha gravada, se ainda não existir
+	EnsureDelivery(referencia string, dataEntrega model.Data) error
+	Transition(referencia, status string, user *model.User) (model.Delivery, error)
+	Summary(referencia string) (model.DeliverySummary, error)
+	// CheckWritable retorna ErrDeliveryLocked quando a entrega da referência está CLOSED ou SENT
+	CheckWritable(referencia string) error
+}
+
+type deliveryService struct {
+	repo       repository.DeliveryRepository
+	portalRepo repository.PortalRepository
+}
+
+func NewDeliveryService(repo repository.DeliveryRepository, portalRepo repository.PortalRepository) DeliveryService {
+	return &deliveryService{repo: repo, portalRepo: portalRepo}
+}
+
+func newDelivery(referencia string, dataEntrega model.Data) model.Delivery {
+	now := time.Now()
+	return model.Delivery{
+		Referencia:   referencia,
+		DataEntrega:  dataEntrega,
+		Status:       model.DeliveryOpen,
+		Historico:    []model.DeliveryTransition{},
+		CriadoEm:     now,
+		AtualizadoEm: now,
+	}
+}
+
+// ListDeliveries lista as entregas, registrando como OPEN as referências que só existem nas
+// linhas de portal (importadas antes da coleção deliveries)
+func (s *deliveryService) ListDeliveries() ([]model.Delivery, error) {
+	referencias, err := s.portalRepo.DistinctReferencias()
+	if err != nil {
+		return nil, err
+	}
+	for _, referencia := range referencias {
+		if err := s.EnsureDelivery(referencia, model.Data{}); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.ListDeliveries()
+}
+
+// GetDelivery aceita "-" no lugar de "/" (ex.: "11-2025" para "11/2025"), pois "/" não trafega em path params
+func (s *deliveryService) GetDelivery(referencia string) (model.Delivery, error) {
+	candidates := []string{referencia}
+	if strings.Contains(referencia, "-") {
+		candidates = append(candidates, strings.ReplaceAll(referencia, "-", "/"))
+	}
+	for _, candidate := range candidates {
+		delivery, err := s.repo.GetDelivery(candidate)
+		if err == nil {
+			return delivery, nil
+		}
+		if !errors.Is(err, mongo.ErrNoDocuments) {
+			return delivery, err
+		}
+	}
+
+	// Referência presente apenas nas linhas de portal: registra a entrega como OPEN
+	referencias, err := s.portalRepo.DistinctReferencias()
+	if err != nil {
+		return model.Delivery{}, err
+	}
+	for _, existing := range referencias {
+		for _, candidate := range candidates {
+			if existing == candidate {
+				if err := s.EnsureDelivery(candidate, model.Data{}); err != nil {
+					return model.Delivery{}, err
+				}
+				return s.repo.GetDelivery(candidate)
+			}
+		}
+	}
+	return model.Delivery{}, ErrDeliveryNotFound
+}
+
+func (s *deliveryService) CreateDelivery(referencia string, dataEntrega model.Data) (model.Delivery, error) {
+	referencia = strings.TrimSpace(referencia)
+	if referencia == "" {
+		return model.Delivery{}, fmt.Errorf("%w: referência é obrigatória", ErrInvalidDelivery)
+	}
+	delivery := newDelivery(referencia, dataEntrega)
+	created, err := s.repo.InsertDeliveryIfMissing(delivery)
+	if err != nil {
+		return delivery, err
+	}
+	if !created {
+		return delivery, ErrDeliveryExists
+	}
+	return delivery, nil
+}
+
+func (s *deliveryService) EnsureDelivery(referencia string, dataEntrega model.Data) error {
+	if referencia == "" {
+		return nil
+	}
+	_, err := s.repo.InsertDeliveryIfMissing(newDelivery(referencia, dataEntrega))
+	return err
+}
+
+// Transition muda o estado da entrega. Sair de CLOSED (reabrir ou marcar como enviada)
+// exige papel admin; as demais transições são permitidas a admins e editores.
+func (s *deliveryService) Transition(referencia, status string, user *model.User) (model.Delivery, error) {
+	delivery, err := s.GetDelivery(referencia)
+	if err != nil {
+		return delivery, err
+	}
+	status = strings.ToUpper(strings.TrimSpace(status))
+	allowed := false
+	for _, next := range model.DeliveryTransitions[delivery.Status] {
+		if next == status {
+			allowed = true
+			break
+		}
+	}
+	if !allowed {
+		return delivery, fmt.Errorf("%w: %s → %s", ErrInvalidTransition, delivery.Status, status)
+	}
+	if delivery.Status == model.DeliveryClosed && user.Role != "admin" {
+		return delivery, ErrTransitionForbidden
+	}
+
+	now := time.Now()
+	delivery.Historico = append(delivery.Historico, model.DeliveryTransition{De: delivery.Status, Para: status, UserID: user.ID, Em: now})
+	delivery.Status = status
+	delivery.AtualizadoEm = now
+	if err := s.repo.UpdateDelivery(delivery); err != nil {
+		return delivery, err
+	}
+	return delivery, nil
+}
+
+func (s *deliveryService) Summary(referencia string) (model.DeliverySummary, error) {
+	delivery, err := s.GetDelivery(referencia)
+	if err != nil {
+		return model.DeliverySummary{}, err
+	}
+	page, err := s.portalRepo.FindPortals(model.PortalQuery{Referencia: delivery.Referencia})
+	if err != nil {
+		return model.DeliverySummary{}, err
+	}
+	summary := model.DeliverySummary{
+		Referencia: delivery.Referencia,
+		Status:     delivery.Status,
+		Total:      len(page.Items),
+		PorStatus:  map[string]int{},
+	}
+	for _, p := range page.Items {
+		summary.PorStatus[p.Status]++
+		if p.Enviar {
+			summary.Enviar++
+		} else {
+			summary.NaoEnviar++
+		}
+	}
+	return summary, nil
+}
+
+func (s *deliveryService) CheckWritable(referencia string) error {
+	if referencia == "" {
+		return nil
+	}
+	delivery, err := s.repo.GetDelivery(referencia)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if delivery.Locked() {
+		return fmt.Errorf("%w (%s está %s)", ErrDeliveryLocked, delivery.Referencia, delivery.Status)
+	}
+	return nil
+}
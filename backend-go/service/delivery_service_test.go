@@ -0,0 +1,84 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestDeliveryLifecycle_LocksRowsWhenClosed(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deps := newTestPortalServiceDeps(repo)
+    svc := NewPortalService(repo, deps)
+    deliveries := deps.Deliveries
+    editor := &model.User{ID: primitive.NewObjectID(), Role: "editor"}
+    admin := &model.User{ID: primitive.NewObjectID(), Role: "admin"}
+
+    // Referência existente apenas nas linhas mock: é registrada como OPEN
+    delivery, err := deliveries.GetDelivery("10-11-2024")
+    if err != nil || delivery.Status != model.DeliveryOpen {
+        t.Fatalf("esperava entrega OPEN, obtive %+v (%v)", delivery, err)
+    }
+    if _, err := deliveries.Transition("10-11-2024", model.DeliveryClosed, editor); !errors.Is(err, ErrInvalidTransition) {
+        t.Fatalf("esperava ErrInvalidTransition de OPEN para CLOSED, obtive %v", err)
+    }
+    for _, status := range []string{model.DeliveryInReview, model.DeliveryClosed} {
+        if _, err := deliveries.Transition("10-11-2024", status, editor); err != nil {
+            t.Fatalf("transição para %s falhou: %v", status, err)
+        }
+    }
+
+    page, _ := repo.FindPortals(model.PortalQuery{Referencia: "10-11-2024"})
+    row := page.Items[0]
+    if err := svc.UpdatePortalFieldsMap(row.ID, bson.M{"observacaoTimeDados": "x"}, primitive.NewObjectID(), nil); !errors.Is(err, ErrDeliveryLocked) {
+        t.Fatalf("esperava ErrDeliveryLocked na edição, obtive %v", err)
+    }
+    if _, err := svc.SavePortal(row); !errors.Is(err, ErrDeliveryLocked) {
+        t.Fatalf("esperava ErrDeliveryLocked na importação, obtive %v", err)
+    }
+
+    // Reabrir exige admin
+    if _, err := deliveries.Transition("10-11-2024", model.DeliveryInReview, editor); !errors.Is(err, ErrTransitionForbidden) {
+        t.Fatalf("esperava ErrTransitionForbidden para editor, obtive %v", err)
+    }
+    delivery, err = deliveries.Transition("10-11-2024", model.DeliveryInReview, admin)
+    if err != nil {
+        t.Fatalf("reabertura falhou: %v", err)
+    }
+    if len(delivery.Historico) != 3 || delivery.Historico[2].UserID != admin.ID || delivery.Historico[2].De != model.DeliveryClosed {
+        t.Fatalf("histórico inesperado: %+v", delivery.Historico)
+    }
+    if err := svc.UpdatePortalFieldsMap(row.ID, bson.M{"observacaoTimeDados": "x"}, primitive.NewObjectID(), nil); err != nil {
+        t.Fatalf("edição após reabertura falhou: %v", err)
+    }
+}
+
+func TestDeliverySummary_CountsRows(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deliveries := NewDeliveryService(repository.NewMockDeliveryRepository(), repo)
+
+    rows := []model.Portal{
+        {ID: "s1", Portal: "p1", Esfera: "ESTADUAL", Referencia: "01/2025", MesAnoReferencia: mustCompetencia("10/2024"), Status: model.StatusOK, Enviar: true},
+        {ID: "s2", Portal: "p2", Esfera: "ESTADUAL", Referencia: "01/2025", MesAnoReferencia: mustCompetencia("10/2024"), Status: model.StatusOK},
+    }
+    for _, row := range rows {
+        if err := repo.UpsertPortal(row); err != nil {
+            t.Fatalf("UpsertPortal falhou: %v", err)
+        }
+    }
+
+    // "/" pode ser enviada como "-" no path
+    summary, err := deliveries.Summary("01-2025")
+    if err != nil {
+        t.Fatalf("Summary falhou: %v", err)
+    }
+    if summary.Referencia != "01/2025" || summary.Total != 2 || summary.Enviar != 1 || summary.NaoEnviar != 1 || summary.PorStatus[model.StatusOK] != 2 {
+        t.Fatalf("resumo inesperado: %+v", summary)
+    }
+    if _, err := deliveries.Summary("02-2025"); !errors.Is(err, ErrDeliveryNotFound) {
+        t.Fatalf("esperava ErrDeliveryNotFound, obtive %v", err)
+    }
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+)
+
+var (
+	ErrInvalidForecast     = errors.New("parâmetros de previsão inválidos")
+	ErrInsufficientHistory = errors.New("histórico insuficiente para previsão")
+)
+
+// ForecastService projeta métricas das próximas competências a partir do histórico de entregas do portal
+type ForecastService interface {
+	Forecast(portal, metric string, horizon int) (model.Forecast, error)
+}
+
+type forecastService struct {
+	history PortalHistoryService
+}
+
+func NewForecastService(history PortalHistoryService) ForecastService {
+	return &forecastService{history: history}
+}
+
+const (
+	forecastMaxHorizon = 24
+	// Holt-Winters exige ao menos duas temporadas completas; abaixo disso usa regressão linear
+	forecastSeasonLength = 12
+	forecastMinPoints    = 3
+	// z da normal para o intervalo de 95%
+	forecastConfidence = 0.95
+	forecastZ          = 1.96
+)
+
+// forecastMetrics são as métricas da entrega que podem ser projetadas
+var forecastMetrics = map[string]func(e model.Entrega) float64{
+	"volumeFonte":              func(e model.Entrega) float64 { return float64(e.VolumeFonte) },
+	"volumetriaDados":          func(e model.Entrega) float64 { return float64(e.VolumetriaDados) },
+	"volumetriaServicos":       func(e model.Entrega) float64 { return float64(e.VolumetriaServicos) },
+	"volumeCpfsUnicosDados":    func(e model.Entrega) float64 { return float64(e.VolumeCpfsUnicosDados) },
+	"volumeCpfsUnicosServicos": func(e model.Entrega) float64 { return float64(e.VolumeCpfsUnicosServicos) },
+	"indiceDados":              func(e model.Entrega) float64 { return e.IndiceDados },
+	"indiceServicos":           func(e model.Entrega) float64 { return e.IndiceServicos },
+}
+
+func (s *forecastService) Forecast(portal, metric string, horizon int) (model.Forecast, error) {
+	value, ok := forecastMetrics[metric]
+	if !ok {
+		return model.Forecast{}, fmt.Errorf("%w: métrica %q não suportada", ErrInvalidForecast, metric)
+	}
+	if horizon < 1 || horizon > forecastMaxHorizon {
+		return model.Forecast{}, fmt.Errorf("%w: horizon deve estar entre 1 e %d", ErrInvalidForecast, forecastMaxHorizon)
+	}
+	history, err := s.history.GetHistory(portal)
+	if err != nil {
+		return model.Forecast{}, err
+	}
+	if len(history.Entregas) < forecastMinPoints {
+		return model.Forecast{}, fmt.Errorf("%w: %d entregas, mínimo %d", ErrInsufficientHistory, len(history.Entregas), forecastMinPoints)
+	}
+
+	observations := monthlySeries(history.Entregas, value)
+	series := make([]float64, len(observations))
+	for i, o := range observations {
+		series[i] = o.Value
+	}
+
+	result := model.Forecast{
+		Portal:          portal,
+		Metric:          metric,
+		ConfidenceLevel: forecastConfidence,
+		History:         observations,
+	}
+	var predictions []float64
+	var stdErrors []float64
+	if len(series) >= 2*forecastSeasonLength {
+		fit := fitHoltWinters(series, forecastSeasonLength)
+		predictions, stdErrors = fit.forecast(horizon)
+		result.Method = "holt-winters"
+		result.SeasonLength = forecastSeasonLength
+		result.Parameters = map[string]float64{"alpha": fit.alpha, "beta": fit.beta, "gamma": fit.gamma}
+		result.RMSE = round3(fit.sigma)
+	} else {
+		fit := fitLinearRegression(series)
+		predictions, stdErrors = fit.forecast(horizon)
+		result.Method = "linear-regression"
+		result.Parameters = map[string]float64{"intercept": round3(fit.intercept), "slope": round3(fit.slope)}
+		result.RMSE = round3(fit.sigma)
+	}
+
+	last := history.Entregas[len(history.Entregas)-1].Competencia
+	for h := 0; h < horizon; h++ {
+		// As métricas são volumes e índices: valores negativos não fazem sentido
+		margin := forecastZ * stdErrors[h]
+		result.Predictions = append(result.Predictions, model.ForecastPrediction{
+			Competencia: last.AddMonths(h + 1).String(),
+			Value:       round3(math.Max(predictions[h], 0)),
+			Lower:       round3(math.Max(predictions[h]-margin, 0)),
+			Upper:       round3(math.Max(predictions[h]+margin, 0)),
+		})
+	}
+	return result, nil
+}
+
+// monthlySeries monta a série mensal contínua entre a primeira e a última competência,
+// interpolando linearmente os meses sem entrega
+func monthlySeries(entregas []model.Entrega, value func(model.Entrega) float64) []model.ForecastObservation {
+	first := entregas[0].Competencia
+	var series []model.ForecastObservation
+	for i, e := range entregas {
+		if i > 0 {
+			prev := entregas[i-1]
+			gap := e.Competencia.Index() - prev.Competencia.Index()
+			for k := 1; k < gap; k++ {
+				v := value(prev) + (value(e)-value(prev))*float64(k)/float64(gap)
+				c := first.AddMonths(len(series))
+				series = append(series, model.ForecastObservation{Competencia: c.String(), Value: round3(v), Interpolated: true})
+			}
+		}
+		series = append(series, model.ForecastObservation{Competencia: e.Competencia.String(), Value: value(e)})
+	}
+	return series
+}
+
+type linearFit struct {
+	intercept, slope float64
+	sigma            float64
+	n                int
+	meanX, sxx       float64
+}
+
+// fitLinearRegression ajusta y = a + b·t por mínimos quadrados (t = 0..n-1)
+func fitLinearRegression(y []float64) linearFit {
+	n := float64(len(y))
+	var sumX, sumY float64
+	for i, v := range y {
+		sumX += float64(i)
+		sumY += v
+	}
+	meanX, meanY := sumX/n, sumY/n
+	var sxx, sxy float64
+	for i, v := range y {
+		dx := float64(i) - meanX
+		sxx += dx * dx
+		sxy += dx * (v - meanY)
+	}
+	fit := linearFit{n: len(y), meanX: meanX, sxx: sxx}
+	if sxx > 0 {
+		fit.slope = sxy / sxx
+	}
+	fit.intercept = meanY - fit.slope*meanX
+	var sse float64
+	for i, v := range y {
+		r := v - (fit.intercept + fit.slope*float64(i))
+		sse += r * r
+	}
+	if len(y) > 2 {
+		fit.sigma = math.Sqrt(sse / (n - 2))
+	}
+	return fit
+}
+
+// forecast devolve as previsões e o erro padrão de predição de cada passo
+func (f linearFit) forecast(horizon int) ([]float64, []float64) {
+	values := make([]float64, horizon)
+	stdErrors := make([]float64, horizon)
+	n := float64(f.n)
+	for h := 0; h < horizon; h++ {
+		x := float64(f.n + h)
+		values[h] = f.intercept + f.slope*x
+		leverage := 1 / n
+		if f.sxx > 0 {
+			leverage += (x - f.meanX) * (x - f.meanX) / f.sxx
+		}
+		stdErrors[h] = f.sigma * math.Sqrt(1+leverage)
+	}
+	return values, stdErrors
+}
+
+type holtWintersFit struct {
+	alpha, beta, gamma float64
+	level, trend       float64
+	season             []float64
+	n, m               int
+	sigma              float64
+}
+
+// fitHoltWinters ajusta o Holt-Winters aditivo escolhendo alpha/beta/gamma numa grade que
+// minimiza o erro quadrático das previsões um passo à frente
+func fitHoltWinters(y []float64, m int) holtWintersFit {
+	grid := []float64{0.1, 0.3, 0.5, 0.7, 0.9}
+	best := holtWintersFit{sigma: math.Inf(1)}
+	for _, a := range grid {
+		for _, b := range grid {
+			for _, g := range grid {
+				if fit := runHoltWinters(y, m, a, b, g); fit.sigma < best.sigma {
+					best = fit
+				}
+			}
+		}
+	}
+	return best
+}
+
+func runHoltWinters(y []float64, m int, alpha, beta, gamma float64) holtWintersFit {
+	var first, second float64
+	for i := 0; i < m; i++ {
+		first += y[i]
+		second += y[m+i]
+	}
+	first /= float64(m)
+	second /= float64(m)
+	// A média da primeira temporada corresponde ao seu ponto central: o nível inicial é
+	// levado ao fim da temporada e os índices sazonais são calculados sem a tendência
+	trend := (second - first) / float64(m)
+	center := float64(m-1) / 2
+	fit := holtWintersFit{alpha: alpha, beta: beta, gamma: gamma, level: first + trend*center, trend: trend, n: len(y), m: m}
+	fit.season = make([]float64, m)
+	for i := 0; i < m; i++ {
+		fit.season[i] = y[i] - (first + trend*(float64(i)-center))
+	}
+
+	var sse float64
+	for t := m; t < len(y); t++ {
+		s := fit.season[t%m]
+		err := y[t] - (fit.level + fit.trend + s)
+		sse += err * err
+		level := alpha*(y[t]-s) + (1-alpha)*(fit.level+fit.trend)
+		fit.trend = beta*(level-fit.level) + (1-beta)*fit.trend
+		fit.season[t%m] = gamma*(y[t]-level) + (1-gamma)*s
+		fit.level = level
+	}
+	fit.sigma = math.Sqrt(sse / float64(len(y)-m))
+	return fit
+}
+
+// forecast usa a variância do modelo ETS(A,A,A): σ²·(1 + Σ c_j²), c_j = α(1+jβ) + γ·[j múltiplo de m]
+func (f holtWintersFit) forecast(horizon int) ([]float64, []float64) {
+	values := make([]float64, horizon)
+	stdErrors := make([]float64, horizon)
+	variance := 1.0
+	for h := 1; h <= horizon; h++ {
+		values[h-1] = f.level + float64(h)*f.trend + f.season[(f.n+h-1)%f.m]
+		stdErrors[h-1] = f.sigma * math.Sqrt(variance)
+		c := f.alpha * (1 + float64(h)*f.beta)
+		if h%f.m == 0 {
+			c += f.gamma
+		}
+		variance += c * c
+	}
+	return values, stdErrors
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+    "errors"
+    "math"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+// newForecastFixture grava no histórico uma entrega mensal por valor, a partir de 01/2022
+func newForecastFixture(t *testing.T, values []int, skip map[int]bool) ForecastService {
+    t.Helper()
+    repo := repository.NewMockPortalRepository()
+    history := NewPortalHistoryService(repository.NewMockPortalHistoryRepository(), repo)
+    start := model.Competencia{Ano: 2022, Mes: 1}
+    for i, v := range values {
+        if skip[i] {
+            continue
+        }
+        c := start.AddMonths(i)
+        row := model.Portal{ID: c.String(), Portal: "transparencia_ba", MesAnoReferencia: c, MesAnoEnvio: c.AddMonths(2), VolumetriaServicos: v}
+        if err := history.RecordDelivery(row); err != nil {
+            t.Fatalf("RecordDelivery falhou: %v", err)
+        }
+    }
+    return NewForecastService(history)
+}
+
+func TestForecast_LinearFallback(t *testing.T) {
+    svc := newForecastFixture(t, []int{100, 110, 120, 130, 140}, map[int]bool{2: true})
+
+    got, err := svc.Forecast("transparencia_ba", "volumetriaServicos", 2)
+    if err != nil {
+        t.Fatalf("Forecast falhou: %v", err)
+    }
+    if got.Method != "linear-regression" || len(got.History) != 5 || !got.History[2].Interpolated || got.History[2].Value != 120 {
+        t.Fatalf("série inesperada: %+v", got)
+    }
+    first := got.Predictions[0]
+    if first.Competencia != "06/2022" || first.Value != 150 || first.Lower != 150 || first.Upper != 150 {
+        t.Fatalf("tendência linear exata deveria prever 150 sem margem: %+v", first)
+    }
+    if got.Predictions[1].Competencia != "07/2022" || got.Predictions[1].Value != 160 {
+        t.Fatalf("segunda previsão inesperada: %+v", got.Predictions[1])
+    }
+}
+
+func TestForecast_HoltWintersSeasonal(t *testing.T) {
+    var values []int
+    for i := 0; i < 36; i++ {
+        // Tendência + sazonalidade anual (pico em dezembro) + ruído determinístico
+        v := 1000 + 10*float64(i) + 200*math.Sin(2*math.Pi*float64(i%12)/12) + float64((i*7)%5)
+        values = append(values, int(v))
+    }
+    svc := newForecastFixture(t, values, nil)
+
+    got, err := svc.Forecast("transparencia_ba", "volumetriaServicos", 12)
+    if err != nil {
+        t.Fatalf("Forecast falhou: %v", err)
+    }
+    if got.Method != "holt-winters" || got.SeasonLength != 12 || len(got.Predictions) != 12 {
+        t.Fatalf("método inesperado: %s %d", got.Method, len(got.Predictions))
+    }
+    for h, p := range got.Predictions {
+        expected := 1000 + 10*float64(36+h) + 200*math.Sin(2*math.Pi*float64((36+h)%12)/12)
+        if math.Abs(p.Value-expected) > 40 {
+            t.Fatalf("previsão %d fora do esperado: %v vs %v", h, p.Value, expected)
+        }
+        if p.Lower > p.Value || p.Upper < p.Value {
+            t.Fatalf("intervalo não contém a previsão: %+v", p)
+        }
+        if h > 0 && p.Upper-p.Lower < got.Predictions[h-1].Upper-got.Predictions[h-1].Lower {
+            t.Fatalf("intervalo deveria crescer com o horizonte: %+v", got.Predictions[:h+1])
+        }
+    }
+}
+
+func TestForecast_Errors(t *testing.T) {
+    svc := newForecastFixture(t, []int{100, 110}, nil)
+    if _, err := svc.Forecast("transparencia_ba", "status", 3); !errors.Is(err, ErrInvalidForecast) {
+        t.Fatalf("esperava ErrInvalidForecast para métrica, obtive %v", err)
+    }
+    if _, err := svc.Forecast("transparencia_ba", "volumetriaServicos", 0); !errors.Is(err, ErrInvalidForecast) {
+        t.Fatalf("esperava ErrInvalidForecast para horizon, obtive %v", err)
+    }
+    if _, err := svc.Forecast("transparencia_ba", "volumetriaServicos", 3); !errors.Is(err, ErrInsufficientHistory) {
+        t.Fatalf("esperava ErrInsufficientHistory, obtive %v", err)
+    }
+    if _, err := svc.Forecast("inexistente", "volumetriaServicos", 3); !errors.Is(err, ErrPortalNotFound) {
+        t.Fatalf("esperava ErrPortalNotFound, obtive %v", err)
+    }
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+)
+
+// LagService mede a defasagem entre o mês de envio e a competência dos dados de cada portal
+type LagService interface {
+	// Apply preenche DefasagemMeses/DefasagemEsperada e marca DefasagemNosDados quando a
+	// defasagem supera a esperada para a esfera. Sem datas válidas, DefasagemMeses fica vazio
+	// e a flag recebida é mantida.
+	Apply(portal model.Portal) model.Portal
+	// Report classifica os portais (última entrega de cada um) do mais atrasado para o menos
+	Report(referencia, esfera string) (model.LagReport, error)
+}
+
+type lagService struct {
+	repo       repository.PortalRepository
+	expected   map[string]int
+	defaultLag int
+}
+
+func NewLagService(repo repository.PortalRepository, expectedByEsfera map[string]int, defaultLag int) LagService {
+	expected := make(map[string]int, len(expectedByEsfera))
+	for esfera, lag := range expectedByEsfera {
+		expected[strings.ToUpper(esfera)] = lag
+	}
+	return &lagService{repo: repo, expected: expected, defaultLag: defaultLag}
+}
+
+// envioCompetencia devolve o mês de envio; sem MesAnoEnvio, usa o mês da DataEntrega
+func envioCompetencia(p model.Portal) (model.Competencia, bool) {
+	if p.MesAnoEnvio.HasMonth() {
+		return p.MesAnoEnvio, true
+	}
+	if !p.DataEntrega.IsZero() {
+		return p.DataEntrega.Competencia(), true
+	}
+	return model.Competencia{}, false
+}
+
+func (s *lagService) expectedLag(esfera string) int {
+	if lag, ok := s.expected[strings.ToUpper(strings.TrimSpace(esfera))]; ok {
+		return lag
+	}
+	return s.defaultLag
+}
+
+func (s *lagService) Apply(portal model.Portal) model.Portal {
+	envio, okEnvio := envioCompetencia(portal)
+	referencia := portal.MesAnoReferencia
+	if !okEnvio || !referencia.HasMonth() {
+		portal.DefasagemMeses = nil
+		return portal
+	}
+	lag := envio.Index() - referencia.Index()
+	portal.DefasagemMeses = &lag
+	portal.DefasagemEsperada = s.expectedLag(portal.Esfera)
+	portal.DefasagemNosDados = lag > portal.DefasagemEsperada
+	return portal
+}
+
+func (s *lagService) Report(referencia, esfera string) (model.LagReport, error) {
+	page, err := s.repo.FindPortals(model.PortalQuery{Referencia: referencia, Esfera: esfera})
+	if err != nil {
+		return model.LagReport{}, err
+	}
+
+	// Última entrega de cada portal: maior mês de envio e, no empate, maior competência
+	latest := map[string]model.Portal{}
+	for _, p := range page.Items {
+		current, seen := latest[p.Portal]
+		if !seen || deliveredAfter(p, current) {
+			latest[p.Portal] = p
+		}
+	}
+
+	report := model.LagReport{Referencia: referencia, Esfera: esfera, Items: []model.LagReportItem{}}
+	for name, p := range latest {
+		p = s.Apply(p)
+		if p.DefasagemMeses == nil {
+			report.SemDatas = append(report.SemDatas, name)
+			continue
+		}
+		report.Items = append(report.Items, model.LagReportItem{
+			PortalID:          p.ID,
+			Portal:            p.Portal,
+			Esfera:            p.Esfera,
+			Referencia:        p.Referencia,
+			MesAnoEnvio:       p.MesAnoEnvio,
+			MesAnoReferencia:  p.MesAnoReferencia,
+			DefasagemMeses:    *p.DefasagemMeses,
+			DefasagemEsperada: p.DefasagemEsperada,
+			Atraso:            *p.DefasagemMeses - p.DefasagemEsperada,
+			DefasagemNosDados: p.DefasagemNosDados,
+		})
+	}
+	sort.Slice(report.Items, func(i, j int) bool {
+		a, b := report.Items[i], report.Items[j]
+		if a.Atraso != b.Atraso {
+			return a.Atraso > b.Atraso
+		}
+		if a.DefasagemMeses != b.DefasagemMeses {
+			return a.DefasagemMeses > b.DefasagemMeses
+		}
+		return a.Portal < b.Portal
+	})
+	sort.Strings(report.SemDatas)
+	return report, nil
+}
+
+// deliveredAfter indica se a linha a é uma entrega posterior à linha b do mesmo portal
+func deliveredAfter(a, b model.Portal) bool {
+	ea, okA := envioCompetencia(a)
+	eb, okB := envioCompetencia(b)
+	if okA != okB {
+		return okA
+	}
+	if okA && ea != eb {
+		return eb.Before(ea)
+	}
+	ca, cb := a.MesAnoReferencia, b.MesAnoReferencia
+	if !ca.HasMonth() || !cb.HasMonth() {
+		return ca.HasMonth() && !cb.HasMonth()
+	}
+	return cb.Before(ca)
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrManifestNotFound = errors.New("manifesto não encontrado")
+	ErrManifestEmpty    = errors.New("nenhum portal marcado para envio")
+	ErrManifestBlocked  = errors.New("há portais marcados para envio com status ERROR")
+)
+
+// ManifestService gera e guarda o manifesto de envio de uma entrega
+type ManifestService interface {
+	// Generate reúne as linhas com Enviar=true da referência e grava o manifesto. Falha com
+	// ErrManifestBlocked se alguma dessas linhas estiver com status ERROR.
+	Generate(referencia string, userID primitive.ObjectID) (model.DeliveryManifest, error)
+	// GetManifest retorna o manifesto informado ou, com id vazio, o mais recente da referência
+	GetManifest(referencia, id string) (model.DeliveryManifest, error)
+	ListManifests(referencia string) ([]model.DeliveryManifest, error)
+}
+
+type manifestService struct {
+	repo          repository.ManifestRepository
+	portalService PortalService
+	deliveries    DeliveryService
+}
+
+func NewManifestService(repo repository.ManifestRepository, portalService PortalService, deliveries DeliveryService) ManifestService {
+	return &manifestService{repo: repo, portalService: portalService, deliveries: deliveries}
+}
+
+func (s *manifestService) Generate(referencia string, userID primitive.ObjectID) (model.DeliveryManifest, error) {
+	delivery, err := s.deliveries.GetDelivery(referencia)
+	if err != nil {
+		return model.DeliveryManifest{}, err
+	}
+	enviar := true
+	page, err := s.portalService.FindPortals(model.PortalQuery{
+		Referencia: delivery.Referencia,
+		Enviar:     &enviar,
+		Sort:       []model.SortField{{Field: "portal"}, {Field: "mesAnoReferencia"}},
+	})
+	if err != nil {
+		return model.DeliveryManifest{}, err
+	}
+	if len(page.Items) == 0 {
+		return model.DeliveryManifest{}, fmt.Errorf("%w em %s", ErrManifestEmpty, delivery.Referencia)
+	}
+
+	var blocked []string
+	manifest := model.DeliveryManifest{
+		Referencia: delivery.Referencia,
+		GeradoEm:   time.Now(),
+		GeradoPor:  userID,
+		Itens:      make([]model.ManifestItem, 0, len(page.Items)),
+	}
+	portals := map[string]bool{}
+	for _, p := range page.Items {
+		if p.Status == model.StatusError {
+			blocked = append(blocked, fmt.Sprintf("%s (%s)", p.Portal, p.MesAnoReferencia))
+			continue
+		}
+		manifest.Itens = append(manifest.Itens, model.ManifestItem{
+			PortalID:           p.ID,
+			Portal:             p.Portal,
+			Competencia:        p.MesAnoReferencia.String(),
+			VolumetriaServicos: p.VolumetriaServicos,
+		})
+		manifest.TotalVolumetriaServicos += p.VolumetriaServicos
+		portals[p.Portal] = true
+	}
+	if len(blocked) > 0 {
+		sort.Strings(blocked)
+		return model.DeliveryManifest{}, fmt.Errorf("%w: %s", ErrManifestBlocked, strings.Join(blocked, ", "))
+	}
+	manifest.TotalPortais = len(portals)
+
+	content, err := encodeManifestCSV(manifest)
+	if err != nil {
+		return model.DeliveryManifest{}, err
+	}
+	sum := sha256.Sum256(content)
+	manifest.CSV = string(content)
+	manifest.Checksum = hex.EncodeToString(sum[:])
+	return s.repo.InsertManifest(manifest)
+}
+
+// encodeManifestCSV escreve uma linha por item e, ao final, a linha de totais
+func encodeManifestCSV(manifest model.DeliveryManifest) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	w.Comma = repository.PortalCSVSeparator
+	records := [][]string{{"portal", "competencia", "volumetriaServicos"}}
+	for _, item := range manifest.Itens {
+		records = append(records, []string{item.Portal, item.Competencia, strconv.Itoa(item.VolumetriaServicos)})
+	}
+	records = append(records, []string{"TOTAL", strconv.Itoa(manifest.TotalPortais) + " portais", strconv.Itoa(manifest.TotalVolumetriaServicos)})
+	if err := w.WriteAll(records); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *manifestService) GetManifest(referencia, id string) (model.DeliveryManifest, error) {
+	if id == "" {
+		manifests, err := s.ListManifests(referencia)
+		if err != nil {
+			return model.DeliveryManifest{}, err
+		}
+		if len(manifests) == 0 {
+			return model.DeliveryManifest{}, ErrManifestNotFound
+		}
+		return manifests[0], nil
+	}
+
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return model.DeliveryManifest{}, ErrManifestNotFound
+	}
+	delivery, err := s.deliveries.GetDelivery(referencia)
+	if err != nil {
+		return model.DeliveryManifest{}, err
+	}
+	manifest, err := s.repo.GetManifest(objectID)
+	if errors.Is(err, mongo.ErrNoDocuments) || (err == nil && manifest.Referencia != delivery.Referencia) {
+		return model.DeliveryManifest{}, ErrManifestNotFound
+	}
+	return manifest, err
+}
+
+func (s *manifestService) ListManifests(referencia string) ([]model.DeliveryManifest, error) {
+	delivery, err := s.deliveries.GetDelivery(referencia)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListManifests(delivery.Referencia)
+}
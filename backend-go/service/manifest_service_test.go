@@ -0,0 +1,62 @@
+package service
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "strings"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestManifestGenerate_EnviarRowsWithTotalsAndChecksum(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deps := newTestPortalServiceDeps(repo)
+    svc := NewManifestService(repository.NewMockManifestRepository(), NewPortalService(repo, deps), deps.Deliveries)
+    user := primitive.NewObjectID()
+
+    rows := []model.Portal{
+        {ID: "m1", Portal: "p_b", Referencia: "02/2025", MesAnoReferencia: mustCompetencia("11/2024"), VolumetriaServicos: 300, Status: model.StatusOK, Enviar: true},
+        {ID: "m2", Portal: "p_a", Referencia: "02/2025", MesAnoReferencia: mustCompetencia("11/2024"), VolumetriaServicos: 200, Status: model.StatusWarning, Enviar: true},
+        {ID: "m3", Portal: "p_c", Referencia: "02/2025", MesAnoReferencia: mustCompetencia("11/2024"), VolumetriaServicos: 999, Status: model.StatusError},
+    }
+    for _, row := range rows {
+        if err := repo.UpsertPortal(row); err != nil {
+            t.Fatalf("UpsertPortal falhou: %v", err)
+        }
+    }
+
+    manifest, err := svc.Generate("02-2025", user)
+    if err != nil {
+        t.Fatalf("Generate falhou: %v", err)
+    }
+    if manifest.TotalPortais != 2 || manifest.TotalVolumetriaServicos != 500 || len(manifest.Itens) != 2 || manifest.Itens[0].Portal != "p_a" {
+        t.Fatalf("manifesto inesperado: %+v", manifest)
+    }
+    sum := sha256.Sum256([]byte(manifest.CSV))
+    if manifest.Checksum != hex.EncodeToString(sum[:]) {
+        t.Fatalf("checksum não confere com o CSV armazenado")
+    }
+    if !strings.HasPrefix(manifest.CSV, "portal;competencia;volumetriaServicos\np_a;11/2024;200\n") || !strings.HasSuffix(manifest.CSV, "TOTAL;2 portais;500\n") {
+        t.Fatalf("CSV inesperado:\n%s", manifest.CSV)
+    }
+
+    stored, err := svc.GetManifest("02/2025", "")
+    if err != nil || stored.ID != manifest.ID || stored.CSV != manifest.CSV {
+        t.Fatalf("esperava recuperar o manifesto armazenado, obtive %+v (%v)", stored, err)
+    }
+
+    // Uma linha em ERROR marcada para envio bloqueia a geração
+    rows[2].Enviar = true
+    if err := repo.UpsertPortal(rows[2]); err != nil {
+        t.Fatalf("UpsertPortal falhou: %v", err)
+    }
+    if _, err := svc.Generate("02/2025", user); !errors.Is(err, ErrManifestBlocked) || !strings.Contains(err.Error(), "p_c") {
+        t.Fatalf("esperava ErrManifestBlocked citando p_c, obtive %v", err)
+    }
+    if manifests, _ := svc.ListManifests("02/2025"); len(manifests) != 1 {
+        t.Fatalf("esperava 1 manifesto armazenado, obtive %d", len(manifests))
+    }
+}
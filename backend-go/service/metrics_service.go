@@ -0,0 +1,204 @@
+package service
+
+import (
+	"math"
+	"sort"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+)
+
+// MetricsService recalcula os campos derivados de volumetria a partir do histórico de entregas do portal
+type MetricsService interface {
+	// Compute devolve o portal com os campos derivados recalculados. history são as linhas do
+	// mesmo portal (pode incluir o próprio registro, que é ignorado). Com flagDivergences, os
+	// valores recebidos são comparados aos calculados e as diferenças são registradas em Divergencias.
+	Compute(portal model.Portal, history []model.Portal, flagDivergences bool) model.Portal
+}
+
+type metricsService struct {
+	tolerance float64
+}
+
+// NewMetricsService cria o serviço com a tolerância relativa usada para apontar divergências (0.01 = 1%)
+func NewMetricsService(tolerance float64) MetricsService {
+	return &metricsService{tolerance: tolerance}
+}
+
+// janela da média móvel, em meses
+const movingAverageMonths = 12
+
+type competenciaPoint struct {
+	competencia model.Competencia
+	portal      model.Portal
+}
+
+// previousDeliveries devolve as entregas com competência anterior à do portal, ordenadas
+// da mais antiga para a mais recente e com uma única linha por competência.
+func previousDeliveries(portal model.Portal, history []model.Portal) (model.Competencia, []competenciaPoint, bool) {
+	current := portal.MesAnoReferencia
+	if !current.HasMonth() {
+		return model.Competencia{}, nil, false
+	}
+	byIndex := map[int]competenciaPoint{}
+	for _, h := range history {
+		if h.ID == portal.ID {
+			continue
+		}
+		c := h.MesAnoReferencia
+		if !c.HasMonth() || !c.Before(current) {
+			continue
+		}
+		// Competência reenviada: prevalece a linha do envio mais recente
+		if existing, ok := byIndex[c.Index()]; ok && !sentBefore(existing.portal, h) {
+			continue
+		}
+		byIndex[c.Index()] = competenciaPoint{competencia: c, portal: h}
+	}
+	points := make([]competenciaPoint, 0, len(byIndex))
+	for _, p := range byIndex {
+		points = append(points, p)
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].competencia.Before(points[j].competencia) })
+	return current, points, true
+}
+
+// sentBefore indica se a entrega a foi enviada antes de b (pelo Mês/Ano de Envio)
+func sentBefore(a, b model.Portal) bool {
+	return !envioAfter(a.MesAnoEnvio, b.MesAnoEnvio)
+}
+
+// envioAfter indica se o Mês/Ano de Envio a é posterior a b; valores vazios nunca são posteriores
+func envioAfter(a, b model.Competencia) bool {
+	if !a.HasMonth() || !b.HasMonth() {
+		return false
+	}
+	return b.Before(a)
+}
+
+func (s *metricsService) Compute(portal model.Portal, history []model.Portal, flagDivergences bool) model.Portal {
+	imported := portal
+	result := portal
+
+	// Índices independem do histórico; sem os volumes (não informados) mantém o valor recebido
+	if result.VolumeFonte > 0 && result.VolumetriaDados > 0 {
+		result.IndiceDados = round3(float64(result.VolumetriaDados) / float64(result.VolumeFonte) * 100)
+	}
+	if result.VolumetriaDados > 0 && result.VolumetriaServicos > 0 {
+		result.IndiceServicos = round3(float64(result.VolumetriaServicos) / float64(result.VolumetriaDados) * 100)
+	}
+
+	current, previous, ok := previousDeliveries(portal, history)
+	if ok {
+		result.CompetenciasFaltantes = missingCompetencias(current, previous, portal.UltimaReferencia)
+		result.PulouCompetencia = len(result.CompetenciasFaltantes) > 0
+	}
+	if ok && len(previous) > 0 {
+		last := previous[len(previous)-1].portal
+
+		var sum float64
+		minPoint, maxPoint := previous[0], previous[0]
+		var windowSum, windowCpfs float64
+		windowCount := 0
+		windowStart := current.AddMonths(-movingAverageMonths)
+		for _, p := range previous {
+			v := p.portal.VolumetriaServicos
+			sum += float64(v)
+			if v < minPoint.portal.VolumetriaServicos {
+				minPoint = p
+			}
+			if v > maxPoint.portal.VolumetriaServicos {
+				maxPoint = p
+			}
+			if !p.competencia.Before(windowStart) {
+				windowSum += float64(v)
+				windowCpfs += float64(p.portal.VolumeCpfsUnicosServicos)
+				windowCount++
+			}
+		}
+		result.Media = int(math.Round(sum / float64(len(previous))))
+		result.Minimo = minPoint.portal.VolumetriaServicos
+		result.MesCompetenciaMinimo = minPoint.competencia
+		result.Maximo = maxPoint.portal.VolumetriaServicos
+		result.MesCompetenciaMaximo = maxPoint.competencia
+		if windowCount > 0 {
+			result.MediaMovelUltimos12Meses = int(math.Round(windowSum / float64(windowCount)))
+			result.MediaMovelCpfsUnicos = int(math.Round(windowCpfs / float64(windowCount)))
+		}
+
+		v := float64(result.VolumetriaServicos)
+		result.PercentualVolumetriaUltima = percentOf(v, last.VolumetriaServicos)
+		result.PercentualVolumetriaMediaMovel = percentOf(v, result.MediaMovelUltimos12Meses)
+		result.PercentualVolumetriaMedia = percentOf(v, result.Media)
+		result.PercentualVolumetriaMinimo = percentOf(v, result.Minimo)
+		result.PercentualVolumetriaMaximo = percentOf(v, result.Maximo)
+	}
+
+	if flagDivergences {
+		result.Divergencias = s.divergences(imported, result)
+	}
+	return result
+}
+
+// missingCompetencias lista os meses sem entrega entre a competência anterior e a atual. A anterior é
+// a última do histórico; sem histórico, usa a UltimaReferencia informada na planilha (quando anterior).
+func missingCompetencias(current model.Competencia, previous []competenciaPoint, ultimaReferencia string) []string {
+	var last model.Competencia
+	if len(previous) > 0 {
+		last = previous[len(previous)-1].competencia
+	} else if c, err := model.ParseCompetencia(ultimaReferencia); err == nil && c.HasMonth() && c.Before(current) {
+		last = c
+	} else {
+		return nil
+	}
+	var missing []string
+	for c := last.AddMonths(1); c.Before(current); c = c.AddMonths(1) {
+		missing = append(missing, c.String())
+	}
+	return missing
+}
+
+// derivedMetricFields são os campos numéricos recalculados e comparados com o valor importado
+var derivedMetricFields = []string{
+	"indiceDados", "indiceServicos", "media", "minimo", "maximo",
+	"mediaMovelUltimos12Meses", "mediaMovelCpfsUnicos",
+	"percentualVolumetriaUltima", "percentualVolumetriaMediaMovel", "percentualVolumetriaMedia",
+	"percentualVolumetriaMinimo", "percentualVolumetriaMaximo",
+}
+
+// divergences compara valores importados e calculados. Campos não preenchidos na importação (zero)
+// não são apontados, pois não há valor do cliente a contestar.
+func (s *metricsService) divergences(imported, computed model.Portal) []model.MetricDivergence {
+	var result []model.MetricDivergence
+	for _, field := range derivedMetricFields {
+		a, b := numericField(&imported, field), numericField(&computed, field)
+		if a == 0 || a == b {
+			continue
+		}
+		if math.Abs(a-b)/math.Max(math.Abs(b), 1) > s.tolerance {
+			result = append(result, model.MetricDivergence{Field: field, Imported: a, Computed: b})
+		}
+	}
+	return result
+}
+
+func numericField(p *model.Portal, field string) float64 {
+	v, _ := p.FieldValue(field)
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+func percentOf(value float64, base int) float64 {
+	if base == 0 {
+		return 0
+	}
+	return round3(value / float64(base) * 100)
+}
+
+func round3(v float64) float64 {
+	return math.Round(v*1000) / 1000
+}
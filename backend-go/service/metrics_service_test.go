@@ -0,0 +1,87 @@
+package service
+
+import (
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func historyRow(id, competencia string, volume int) model.Portal {
+    return model.Portal{ID: id, Portal: "transparencia_ba", MesAnoEnvio: mustCompetencia("12/2024"), MesAnoReferencia: mustCompetencia(competencia), VolumetriaServicos: volume, VolumeCpfsUnicosServicos: volume / 10}
+}
+
+func TestMetricsCompute_FromHistory(t *testing.T) {
+    svc := NewMetricsService(0.01)
+    history := []model.Portal{
+        historyRow("a", "01/2023", 500), // fora da janela de 12 meses
+        historyRow("b", "01/2024", 1000),
+        historyRow("c", "02/2024", 800),
+        historyRow("d", "03/2024", 1200),
+        historyRow("f", "05/2024", 9999), // competência posterior: ignorada
+    }
+    current := model.Portal{
+        ID: "e", Portal: "transparencia_ba", MesAnoReferencia: mustCompetencia("04/2024"),
+        VolumeFonte: 2000, VolumetriaDados: 1500, VolumetriaServicos: 1200,
+        Media: 875, PercentualVolumetriaMediaMovel: 50,
+    }
+
+    got := svc.Compute(current, history, true)
+
+    if got.IndiceDados != 75 || got.IndiceServicos != 80 {
+        t.Fatalf("índices inesperados: %v %v", got.IndiceDados, got.IndiceServicos)
+    }
+    if got.Media != 875 || got.MediaMovelUltimos12Meses != 1000 || got.MediaMovelCpfsUnicos != 100 {
+        t.Fatalf("médias inesperadas: media=%d movel=%d cpfs=%d", got.Media, got.MediaMovelUltimos12Meses, got.MediaMovelCpfsUnicos)
+    }
+    if got.Minimo != 500 || got.MesCompetenciaMinimo.String() != "01/2023" || got.Maximo != 1200 || got.MesCompetenciaMaximo.String() != "03/2024" {
+        t.Fatalf("mínimo/máximo inesperados: %+v", got)
+    }
+    if got.PercentualVolumetriaUltima != 100 || got.PercentualVolumetriaMediaMovel != 120 {
+        t.Fatalf("percentuais inesperados: %v %v", got.PercentualVolumetriaUltima, got.PercentualVolumetriaMediaMovel)
+    }
+    if len(got.Divergencias) != 1 || got.Divergencias[0].Field != "percentualVolumetriaMediaMovel" {
+        t.Fatalf("esperava apenas a divergência de percentualVolumetriaMediaMovel: %+v", got.Divergencias)
+    }
+}
+
+func TestSavePortal_RecalculatesLaterCompetencias(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := newTestPortalService(repo)
+
+    if _, err := svc.SavePortal(historyRow("b", "02/2024", 1000)); err != nil {
+        t.Fatalf("SavePortal falhou: %v", err)
+    }
+    if _, err := svc.SavePortal(historyRow("c", "03/2024", 1000)); err != nil {
+        t.Fatalf("SavePortal falhou: %v", err)
+    }
+    // Competência anterior chega depois: a média de "c" deve ser refeita
+    if _, err := svc.SavePortal(historyRow("a", "01/2024", 4000)); err != nil {
+        t.Fatalf("SavePortal falhou: %v", err)
+    }
+    c, _ := repo.GetPortalByID("c")
+    if c.Media != 2500 || c.Maximo != 4000 {
+        t.Fatalf("competência posterior não foi recalculada: media=%d maximo=%d", c.Media, c.Maximo)
+    }
+}
+
+func TestMetricsCompute_DetectsSkippedCompetencias(t *testing.T) {
+    svc := NewMetricsService(0.01)
+    history := []model.Portal{historyRow("a", "10/2024", 100), historyRow("b", "11/2024", 100)}
+
+    got := svc.Compute(model.Portal{ID: "c", Portal: "transparencia_ba", MesAnoReferencia: mustCompetencia("02/2025"), PulouCompetencia: false}, history, false)
+    if !got.PulouCompetencia || len(got.CompetenciasFaltantes) != 2 || got.CompetenciasFaltantes[0] != "12/2024" || got.CompetenciasFaltantes[1] != "01/2025" {
+        t.Fatalf("competências puladas não detectadas: %v %v", got.PulouCompetencia, got.CompetenciasFaltantes)
+    }
+
+    // Sequência contínua desmarca a flag mesmo que tenha sido marcada manualmente
+    got = svc.Compute(model.Portal{ID: "c", Portal: "transparencia_ba", MesAnoReferencia: mustCompetencia("12/2024"), PulouCompetencia: true}, history, false)
+    if got.PulouCompetencia || got.CompetenciasFaltantes != nil {
+        t.Fatalf("não deveria haver competência pulada: %v", got.CompetenciasFaltantes)
+    }
+
+    // Sem histórico, a UltimaReferencia da planilha é a competência anterior
+    got = svc.Compute(model.Portal{ID: "x", Portal: "transparencia_pe", MesAnoReferencia: mustCompetencia("8/2024"), UltimaReferencia: "06/2024"}, nil, false)
+    if !got.PulouCompetencia || len(got.CompetenciasFaltantes) != 1 || got.CompetenciasFaltantes[0] != "07/2024" {
+        t.Fatalf("UltimaReferencia não considerada: %v", got.CompetenciasFaltantes)
+    }
+}
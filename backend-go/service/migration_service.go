@@ -0,0 +1,156 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+)
+
+// ErrInvalidMigration indica uma lista de migrações mal definida ou um alvo desconhecido
+var ErrInvalidMigration = errors.New("migração inválida")
+
+// ErrIrreversibleMigration indica uma migração aplicada que não tem passo de reversão
+var ErrIrreversibleMigration = errors.New("migração irreversível")
+
+// Migration é uma mudança versionada do banco. Up é aplicado uma única vez por ambiente (o
+// registro fica em schema_migrations); Down desfaz o Up e é nil quando não há como desfazer.
+// OneWay marca correções de dados sem Down que não precisam ser desfeitas: a reversão apenas
+// remove o registro (um novo Up as reaplica) em vez de interromper.
+type Migration struct {
+	Version int
+	Nome    string
+	Up      func() error
+	Down    func() error
+	OneWay  bool
+}
+
+// MigrationService aplica e reverte as migrações em ordem de versão
+type MigrationService interface {
+	// Status lista as migrações conhecidas e se já foram aplicadas
+	Status() ([]model.MigrationStatus, error)
+	// Up aplica as migrações pendentes até target (todas, com target 0) e retorna as aplicadas
+	Up(target int) ([]model.SchemaMigration, error)
+	// Down reverte as últimas steps migrações aplicadas, da mais recente para a mais antiga;
+	// migrações OneWay são puladas (só o registro é removido)
+	Down(steps int) ([]model.SchemaMigration, error)
+}
+
+type migrationService struct {
+	repo       repository.MigrationRepository
+	migrations []Migration
+}
+
+// NewMigrationService recebe as migrações em ordem crescente de versão
+func NewMigrationService(repo repository.MigrationRepository, migrations []Migration) MigrationService {
+	return &migrationService{repo: repo, migrations: migrations}
+}
+
+func (s *migrationService) Status() ([]model.MigrationStatus, error) {
+	applied, err := s.applied()
+	if err != nil {
+		return nil, err
+	}
+	status := make([]model.MigrationStatus, 0, len(s.migrations))
+	for _, m := range s.migrations {
+		st := model.MigrationStatus{Version: m.Version, Nome: m.Nome, Reversivel: m.Down != nil, SentidoUnico: m.OneWay}
+		if rec, ok := applied[m.Version]; ok {
+			aplicadaEm := rec.AplicadaEm
+			st.Aplicada = true
+			st.AplicadaEm = &aplicadaEm
+		}
+		status = append(status, st)
+	}
+	return status, nil
+}
+
+func (s *migrationService) Up(target int) ([]model.SchemaMigration, error) {
+	applied, err := s.applied()
+	if err != nil {
+		return nil, err
+	}
+	if target != 0 && s.find(target) == nil {
+		return nil, fmt.Errorf("%w: versão %d desconhecida", ErrInvalidMigration, target)
+	}
+	done := []model.SchemaMigration{}
+	for _, m := range s.migrations {
+		if target != 0 && m.Version > target {
+			break
+		}
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := m.Up(); err != nil {
+			return done, fmt.Errorf("migração %d (%s): %w", m.Version, m.Nome, err)
+		}
+		rec := model.SchemaMigration{Version: m.Version, Nome: m.Nome, AplicadaEm: time.Now()}
+		if err := s.repo.MarkApplied(rec); err != nil {
+			return done, fmt.Errorf("migração %d (%s) aplicada, mas não registrada: %w", m.Version, m.Nome, err)
+		}
+		done = append(done, rec)
+	}
+	return done, nil
+}
+
+func (s *migrationService) Down(steps int) ([]model.SchemaMigration, error) {
+	if steps <= 0 {
+		return nil, fmt.Errorf("%w: informe quantas migrações reverter", ErrInvalidMigration)
+	}
+	applied, err := s.applied()
+	if err != nil {
+		return nil, err
+	}
+	done := []model.SchemaMigration{}
+	for i := len(s.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := s.migrations[i]
+		rec, ok := applied[m.Version]
+		if !ok {
+			continue
+		}
+		if m.Down == nil && !m.OneWay {
+			return done, fmt.Errorf("%w: %d (%s)", ErrIrreversibleMigration, m.Version, m.Nome)
+		}
+		if m.Down != nil {
+			if err := m.Down(); err != nil {
+				return done, fmt.Errorf("reversão da migração %d (%s): %w", m.Version, m.Nome, err)
+			}
+		}
+		if err := s.repo.UnmarkApplied(m.Version); err != nil {
+			return done, fmt.Errorf("migração %d (%s) revertida, mas o registro não foi removido: %w", m.Version, m.Nome, err)
+		}
+		done = append(done, rec)
+	}
+	return done, nil
+}
+
+// applied valida a lista de migrações e devolve os registros de schema_migrations por versão
+func (s *migrationService) applied() (map[int]model.SchemaMigration, error) {
+	for i, m := range s.migrations {
+		if m.Version <= 0 || m.Up == nil {
+			return nil, fmt.Errorf("%w: versão %d sem Up ou com número não positivo", ErrInvalidMigration, m.Version)
+		}
+		if i > 0 && m.Version <= s.migrations[i-1].Version {
+			return nil, fmt.Errorf("%w: versão %d fora de ordem ou repetida", ErrInvalidMigration, m.Version)
+		}
+	}
+	records, err := s.repo.ListApplied()
+	if err != nil {
+		return nil, err
+	}
+	applied := map[int]model.SchemaMigration{}
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+func (s *migrationService) find(version int) *Migration {
+	for i := range s.migrations {
+		if s.migrations[i].Version == version {
+			return &s.migrations[i]
+		}
+	}
+	return nil
+}
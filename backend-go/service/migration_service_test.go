@@ -0,0 +1,131 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func TestMigrations_SeedAppliedOnceAndReverted(t *testing.T) {
+    portalRepo := repository.NewMockPortalRepository()
+    // Linhas gravadas sem _id: a cópia do antigo InitializeData e uma linha real, que é mantida
+    portalRepo.InsertPortal(model.Portal{Portal: "transparencia_al", Esfera: "ESTADUAL", MesAnoEnvio: model.Competencia{Ano: 2024, Mes: 11}})
+    portalRepo.InsertPortal(model.Portal{Portal: "transparencia_al", Esfera: "ESTADUAL", MesAnoEnvio: model.Competencia{Ano: 2024, Mes: 11}, Referencia: "10-11-2024"})
+    migrationRepo := repository.NewMockMigrationRepository()
+    historyRepo := repository.NewMockPortalHistoryRepository()
+    deps := MigrationDeps{
+        Portals: portalRepo,
+        History: NewPortalHistoryService(historyRepo, portalRepo),
+    }
+
+    // Duas "inicializações": a segunda não tem o que aplicar
+    for run := 0; run < 2; run++ {
+        svc := NewMigrationService(migrationRepo, DefaultMigrations(deps))
+        applied, err := svc.Up(0)
+        if err != nil {
+            t.Fatalf("Up falhou: %v", err)
+        }
+        if want := []int{4, 0}[run]; len(applied) != want {
+            t.Fatalf("execução %d: esperava %d migrações aplicadas, obtive %+v", run, want, applied)
+        }
+    }
+    all, _ := portalRepo.GetAllPortals()
+    seeds, kept := 0, 0
+    for _, p := range all {
+        if p.ID == "" && p.Referencia == "" {
+            t.Fatalf("cópia do seed sem _id não foi removida: %+v", p)
+        }
+        if p.ID == "" {
+            kept++
+        }
+        if p.ID == SeedPortalID {
+            seeds++
+        }
+    }
+    if seeds != 1 || kept != 1 || len(all) != 7 {
+        t.Fatalf("esperava o seed uma única vez e a linha real mantida entre 7 linhas: %d seeds, %d mantidas, %d linhas", seeds, kept, len(all))
+    }
+
+    if identities, _ := historyRepo.ListIdentities(); len(identities) == 0 {
+        t.Fatalf("histórico não reconstruído")
+    }
+
+    // Todas as migrações revertem: o histórico some, a normalização de datas (sentido único) é
+    // pulada, a limpeza dos seeds não faz nada e o seed é removido
+    svc := NewMigrationService(migrationRepo, DefaultMigrations(deps))
+    reverted, err := svc.Down(4)
+    if err != nil || len(reverted) != 4 {
+        t.Fatalf("Down inesperado: %+v (%v)", reverted, err)
+    }
+    if identities, _ := historyRepo.ListIdentities(); len(identities) != 0 {
+        t.Fatalf("histórico não removido: %+v", identities)
+    }
+    if _, err := portalRepo.GetPortalByID(SeedPortalID); err == nil {
+        t.Fatalf("seed não removido")
+    }
+    status, _ := svc.Status()
+    for _, st := range status {
+        if st.Aplicada || st.SentidoUnico != (st.Version == 3) {
+            t.Fatalf("status inesperado após Down: %+v", st)
+        }
+    }
+    if applied, err := svc.Up(0); err != nil || len(applied) != 4 {
+        t.Fatalf("reaplicação inesperada: %+v (%v)", applied, err)
+    }
+    if _, err := svc.Up(9); !errors.Is(err, ErrInvalidMigration) {
+        t.Fatalf("esperava ErrInvalidMigration para versão desconhecida, obtive %v", err)
+    }
+}
+
+func TestMigrations_UpToTargetAndDown(t *testing.T) {
+    var executed []string
+    step := func(name string) func() error {
+        return func() error { executed = append(executed, name); return nil }
+    }
+    migrations := []Migration{
+        {Version: 1, Nome: "a", Up: step("up a"), Down: step("down a")},
+        {Version: 2, Nome: "b", Up: step("up b"), Down: step("down b")},
+        {Version: 3, Nome: "c", Up: step("up c"), Down: step("down c")},
+    }
+    svc := NewMigrationService(repository.NewMockMigrationRepository(), migrations)
+
+    if _, err := svc.Up(2); err != nil {
+        t.Fatalf("Up falhou: %v", err)
+    }
+    status, err := svc.Status()
+    if err != nil || !status[0].Aplicada || !status[1].Aplicada || status[2].Aplicada || status[1].AplicadaEm == nil {
+        t.Fatalf("status inesperado: %+v %v", status, err)
+    }
+    if _, err := svc.Up(0); err != nil {
+        t.Fatalf("Up falhou: %v", err)
+    }
+    reverted, err := svc.Down(2)
+    if err != nil || len(reverted) != 2 || reverted[0].Version != 3 || reverted[1].Version != 2 {
+        t.Fatalf("Down inesperado: %+v %v", reverted, err)
+    }
+    want := []string{"up a", "up b", "up c", "down c", "down b"}
+    if len(executed) != len(want) {
+        t.Fatalf("passos executados: %v", executed)
+    }
+    for i := range want {
+        if executed[i] != want[i] {
+            t.Fatalf("passos executados: %v", executed)
+        }
+    }
+
+    // Sem Down (e sem OneWay), a reversão é interrompida
+    irreversible := NewMigrationService(repository.NewMockMigrationRepository(), []Migration{migrations[0], {Version: 2, Nome: "sem down", Up: step("up x")}})
+    if _, err := irreversible.Up(0); err != nil {
+        t.Fatalf("Up falhou: %v", err)
+    }
+    if _, err := irreversible.Down(1); !errors.Is(err, ErrIrreversibleMigration) {
+        t.Fatalf("esperava ErrIrreversibleMigration, obtive %v", err)
+    }
+
+    // Versões fora de ordem são recusadas antes de qualquer passo
+    bad := NewMigrationService(repository.NewMockMigrationRepository(), []Migration{migrations[1], migrations[0]})
+    if _, err := bad.Up(0); !errors.Is(err, ErrInvalidMigration) {
+        t.Fatalf("esperava ErrInvalidMigration, obtive %v", err)
+    }
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// SeedPortalID é o _id fixo da linha de exemplo gravada pela migração 1, para que ela exista uma
+// única vez por ambiente
+const SeedPortalID = "seed_transparencia_al"
+
+// MigrationDeps agrupa o que as migrações do backend usam. Dates é nil quando não há documentos
+// no MongoDB para regravar (DATA_SOURCE=mock).
+type MigrationDeps struct {
+	Portals repository.PortalRepository
+	History PortalHistoryService
+	Dates   repository.DateNormalizationRepository
+	// Logf recebe as mensagens de progresso das migrações (nil descarta)
+	Logf func(format string, args ...interface{})
+}
+
+// DefaultMigrations lista, em ordem, as migrações do backend. Novas migrações entram sempre no
+// final, com a próxima versão; uma versão já publicada não deve ser alterada.
+func DefaultMigrations(deps MigrationDeps) []Migration {
+	logf := deps.Logf
+	if logf == nil {
+		logf = func(string, ...interface{}) {}
+	}
+	return []Migration{
+		{
+			Version: 1,
+			Nome:    "seed_portal_exemplo",
+			Up: func() error {
+				// Antes gravada por InitializeData a cada inicialização, sem _id. Gravada como está,
+				// sem passar pelo catálogo: uma versão publicada não pode depender do cadastro atual.
+				if _, err := deps.Portals.GetPortalByID(SeedPortalID); err == nil {
+					return nil
+				}
+				return deps.Portals.UpsertPortal(model.Portal{
+					ID:          SeedPortalID,
+					Portal:      "transparencia_al",
+					Esfera:      "ESTADUAL",
+					MesAnoEnvio: model.Competencia{Ano: 2024, Mes: 11},
+				})
+			},
+			Down: func() error {
+				if err := deps.Portals.DeletePortal(SeedPortalID); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+					return err
+				}
+				return nil
+			},
+		},
+		{
+			Version: 2,
+			Nome:    "remove_seeds_duplicados",
+			Up: func() error {
+				removed, err := deps.Portals.DeleteLegacySeedPortals()
+				if err != nil {
+					return err
+				}
+				logf("%d cópias da linha de exemplo do antigo InitializeData removidas", removed)
+				return nil
+			},
+			// As cópias removidas eram lixo: a reversão não as recria
+			Down: func() error { return nil },
+		},
+		{
+			Version: 3,
+			Nome:    "normaliza_datas",
+			Up: func() error {
+				if deps.Dates == nil {
+					return nil
+				}
+				report, err := deps.Dates.NormalizeDates()
+				for _, c := range report.Colecoes {
+					logf("%s: %d documentos, %d atualizados, %d valores inválidos", c.Colecao, c.Documentos, c.Atualizados, len(c.Invalidos))
+				}
+				return err
+			},
+			// Correção de dados em um sentido só: o formato antigo não é restaurado
+			OneWay: true,
+		},
+		{
+			Version: 4,
+			Nome:    "reconstroi_historico",
+			Up: func() error {
+				report, err := deps.History.Rebuild()
+				if err != nil {
+					return fmt.Errorf("reconstrução do histórico: %w", err)
+				}
+				logf("histórico reconstruído: %d linhas, %d entregas, %d portais", report.ProcessedRows, report.Entregas, report.Portais)
+				return nil
+			},
+			Down: func() error {
+				return deps.History.Clear()
+			},
+		},
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrCatalogEntryNotFound = errors.New("portal não encontrado no catálogo")
+	ErrInvalidCatalogEntry  = errors.New("cadastro de portal inválido")
+	// ErrCatalogConflict indica nome ou alias já usado por outro portal do catálogo
+	ErrCatalogConflict = errors.New("nome ou alias já cadastrado para outro portal")
+	// ErrUnknownPortal indica nome de portal fora do catálogo (com rejeição de nomes desconhecidos)
+	ErrUnknownPortal = errors.New("portal fora do catálogo")
+)
+
+// PortalCatalogService mantém o cadastro mestre de portais e resolve as grafias (aliases)
+// usadas na planilha para o nome canônico
+type PortalCatalogService interface {
+	ListEntries() ([]model.PortalCatalogEntry, error)
+	GetEntry(id primitive.ObjectID) (model.PortalCatalogEntry, error)
+	CreateEntry(entry model.PortalCatalogEntry) (model.PortalCatalogEntry, error)
+	UpdateEntry(id primitive.ObjectID, entry model.PortalCatalogEntry) (model.PortalCatalogEntry, error)
+	DeleteEntry(id primitive.ObjectID) error
+	// Resolve troca o nome do portal (canônico ou alias, sem diferenciar maiúsculas) pelo nome
+	// canônico e completa a esfera vazia com a do catálogo. Nomes desconhecidos (ou de cadastros
+	// inativos) são marcados em ForaDoCatalogo ou, com rejectUnknown, recusados com ErrUnknownPortal. Enquanto o catálogo
+	// estiver vazio, os nomes são mantidos sem marcação.
+	Resolve(portal model.Portal) (model.Portal, error)
+}
+
+type portalCatalogService struct {
+	repo          repository.PortalCatalogRepository
+	rejectUnknown bool
+}
+
+func NewPortalCatalogService(repo repository.PortalCatalogRepository, rejectUnknown bool) PortalCatalogService {
+	return &portalCatalogService{repo: repo, rejectUnknown: rejectUnknown}
+}
+
+// catalogKey normaliza um nome para comparação: sem espaços nas pontas, repetidos ou maiúsculas
+func catalogKey(name string) string {
+	return strings.ToLower(strings.Join(strings.Fields(name), " "))
+}
+
+func (s *portalCatalogService) ListEntries() ([]model.PortalCatalogEntry, error) {
+	return s.repo.ListEntries()
+}
+
+func (s *portalCatalogService) GetEntry(id primitive.ObjectID) (model.PortalCatalogEntry, error) {
+	entry, err := s.repo.GetEntry(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return entry, ErrCatalogEntryNotFound
+	}
+	return entry, err
+}
+
+// validateEntry normaliza a entrada, monta as chaves de resolução e confere que nenhuma delas
+// pertence a outro portal do catálogo
+func (s *portalCatalogService) validateEntry(entry *model.PortalCatalogEntry) error {
+	entry.Portal = strings.TrimSpace(entry.Portal)
+	entry.Esfera = strings.ToUpper(strings.TrimSpace(entry.Esfera))
+	entry.UF = strings.ToUpper(strings.TrimSpace(entry.UF))
+	entry.Municipio = strings.TrimSpace(entry.Municipio)
+	entry.CodigoIBGE = strings.TrimSpace(entry.CodigoIBGE)
+	entry.Equipe = strings.TrimSpace(entry.Equipe)
+	if entry.Portal == "" {
+		return fmt.Errorf("%w: portal é obrigatório", ErrInvalidCatalogEntry)
+	}
+	if entry.UF != "" && len(entry.UF) != 2 {
+		return fmt.Errorf("%w: UF deve ter 2 letras", ErrInvalidCatalogEntry)
+	}
+	if entry.CodigoIBGE != "" && (len(entry.CodigoIBGE) != 7 || strings.Trim(entry.CodigoIBGE, "0123456789") != "") {
+		return fmt.Errorf("%w: código IBGE deve ter 7 dígitos", ErrInvalidCatalogEntry)
+	}
+
+	aliases := []string{}
+	keys := []string{catalogKey(entry.Portal)}
+	seen := map[string]bool{keys[0]: true}
+	for _, alias := range entry.Aliases {
+		alias = strings.TrimSpace(alias)
+		key := catalogKey(alias)
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		aliases = append(aliases, alias)
+		keys = append(keys, key)
+	}
+	entry.Aliases, entry.Chaves = aliases, keys
+
+	for _, key := range keys {
+		other, err := s.repo.FindByKey(key)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if other.ID != entry.ID {
+			return fmt.Errorf("%w: %q pertence a %s", ErrCatalogConflict, key, other.Portal)
+		}
+	}
+	return nil
+}
+
+func (s *portalCatalogService) CreateEntry(entry model.PortalCatalogEntry) (model.PortalCatalogEntry, error) {
+	entry.ID = primitive.NilObjectID
+	if err := s.validateEntry(&entry); err != nil {
+		return entry, err
+	}
+	entry.CriadoEm = time.Now()
+	entry.AtualizadoEm = entry.CriadoEm
+	return s.repo.InsertEntry(entry)
+}
+
+func (s *portalCatalogService) UpdateEntry(id primitive.ObjectID, entry model.PortalCatalogEntry) (model.PortalCatalogEntry, error) {
+	existing, err := s.GetEntry(id)
+	if err != nil {
+		return entry, err
+	}
+	entry.ID = id
+	if err := s.validateEntry(&entry); err != nil {
+		return entry, err
+	}
+	entry.CriadoEm = existing.CriadoEm
+	entry.AtualizadoEm = time.Now()
+	if err := s.repo.UpdateEntry(entry); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
+
+func (s *portalCatalogService) DeleteEntry(id primitive.ObjectID) error {
+	err := s.repo.DeleteEntry(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrCatalogEntryNotFound
+	}
+	return err
+}
+
+func (s *portalCatalogService) Resolve(portal model.Portal) (model.Portal, error) {
+	entry, err := s.repo.FindByKey(catalogKey(portal.Portal))
+	if err == nil && entry.Ativo {
+		portal.Portal = entry.Portal
+		if strings.TrimSpace(portal.Esfera) == "" {
+			portal.Esfera = entry.Esfera
+		}
+		portal.ForaDoCatalogo = false
+		return portal, nil
+	}
+	// Cadastros inativos contam como nome desconhecido
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return portal, err
+	}
+	count, err := s.repo.CountEntries()
+	if err != nil || count == 0 {
+		return portal, err
+	}
+	if s.rejectUnknown {
+		return portal, fmt.Errorf("%w: %q", ErrUnknownPortal, portal.Portal)
+	}
+	portal.ForaDoCatalogo = true
+	return portal, nil
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func newTestPortalCatalogService() PortalCatalogService {
+    return NewPortalCatalogService(repository.NewMockPortalCatalogRepository(), false)
+}
+
+func TestPortalCatalog_CRUDAndConflicts(t *testing.T) {
+    svc := newTestPortalCatalogService()
+
+    if _, err := svc.CreateEntry(model.PortalCatalogEntry{Portal: " "}); !errors.Is(err, ErrInvalidCatalogEntry) {
+        t.Fatalf("esperava ErrInvalidCatalogEntry sem nome, obtive %v", err)
+    }
+    if _, err := svc.CreateEntry(model.PortalCatalogEntry{Portal: "transparencia_go", CodigoIBGE: "52"}); !errors.Is(err, ErrInvalidCatalogEntry) {
+        t.Fatalf("esperava ErrInvalidCatalogEntry com código IBGE curto, obtive %v", err)
+    }
+
+    goias, err := svc.CreateEntry(model.PortalCatalogEntry{
+        Portal: "transparencia_go", Esfera: "estadual", UF: "go", Ativo: true,
+        Aliases: []string{"Transparencia_GO", " bot_goias ", "BOT_GOIAS"},
+    })
+    if err != nil {
+        t.Fatalf("CreateEntry falhou: %v", err)
+    }
+    if goias.Esfera != "ESTADUAL" || goias.UF != "GO" || len(goias.Aliases) != 1 || goias.Aliases[0] != "bot_goias" {
+        t.Fatalf("cadastro não normalizado: %+v", goias)
+    }
+
+    // Alias de outro portal não pode ser reaproveitado
+    if _, err := svc.CreateEntry(model.PortalCatalogEntry{Portal: "transparencia_ba", Aliases: []string{"BOT_GOIAS"}}); !errors.Is(err, ErrCatalogConflict) {
+        t.Fatalf("esperava ErrCatalogConflict, obtive %v", err)
+    }
+
+    // Atualizar o próprio cadastro mantendo os aliases não é conflito
+    goias.Aliases = append(goias.Aliases, "transp go")
+    updated, err := svc.UpdateEntry(goias.ID, goias)
+    if err != nil || len(updated.Aliases) != 2 || !updated.CriadoEm.Equal(goias.CriadoEm) {
+        t.Fatalf("UpdateEntry inesperado: %+v %v", updated, err)
+    }
+
+    if err := svc.DeleteEntry(goias.ID); err != nil {
+        t.Fatalf("DeleteEntry falhou: %v", err)
+    }
+    if _, err := svc.GetEntry(goias.ID); !errors.Is(err, ErrCatalogEntryNotFound) {
+        t.Fatalf("esperava ErrCatalogEntryNotFound, obtive %v", err)
+    }
+}
+
+func TestPortalCatalog_Resolve(t *testing.T) {
+    repo := repository.NewMockPortalCatalogRepository()
+    flag := NewPortalCatalogService(repo, false)
+    reject := NewPortalCatalogService(repo, true)
+
+    // Catálogo vazio: nomes são mantidos sem marcação
+    p, err := reject.Resolve(model.Portal{Portal: "qualquer"})
+    if err != nil || p.ForaDoCatalogo {
+        t.Fatalf("catálogo vazio não deveria marcar nem recusar: %+v %v", p, err)
+    }
+
+    if _, err := flag.CreateEntry(model.PortalCatalogEntry{Portal: "transparencia_go", Esfera: "ESTADUAL", Ativo: true, Aliases: []string{"bot_goias"}}); err != nil {
+        t.Fatalf("CreateEntry falhou: %v", err)
+    }
+
+    p, err = flag.Resolve(model.Portal{Portal: "  BOT_Goias ", ForaDoCatalogo: true})
+    if err != nil || p.Portal != "transparencia_go" || p.Esfera != "ESTADUAL" || p.ForaDoCatalogo {
+        t.Fatalf("alias não resolvido: %+v %v", p, err)
+    }
+    p, err = flag.Resolve(model.Portal{Portal: "transparencia_xx"})
+    if err != nil || p.Portal != "transparencia_xx" || !p.ForaDoCatalogo {
+        t.Fatalf("nome desconhecido deveria ser marcado: %+v %v", p, err)
+    }
+    if _, err := reject.Resolve(model.Portal{Portal: "transparencia_xx"}); !errors.Is(err, ErrUnknownPortal) {
+        t.Fatalf("esperava ErrUnknownPortal, obtive %v", err)
+    }
+
+    // Cadastro inativo: nome e alias são tratados como desconhecidos
+    if _, err := flag.CreateEntry(model.PortalCatalogEntry{Portal: "transparencia_ba", Esfera: "ESTADUAL", Aliases: []string{"bot_bahia"}}); err != nil {
+        t.Fatalf("CreateEntry falhou: %v", err)
+    }
+    p, err = flag.Resolve(model.Portal{Portal: "bot_bahia"})
+    if err != nil || p.Portal != "bot_bahia" || p.Esfera != "" || !p.ForaDoCatalogo {
+        t.Fatalf("cadastro inativo deveria ser marcado como fora do catálogo: %+v %v", p, err)
+    }
+    if _, err := reject.Resolve(model.Portal{Portal: "transparencia_ba"}); !errors.Is(err, ErrUnknownPortal) {
+        t.Fatalf("esperava ErrUnknownPortal para cadastro inativo, obtive %v", err)
+    }
+}
+
+func TestImportWorkbook_ResolvesCatalogAliases(t *testing.T) {
+    for _, rejectUnknown := range []bool{false, true} {
+        repo := repository.NewMockPortalRepository()
+        catalog := NewPortalCatalogService(repository.NewMockPortalCatalogRepository(), rejectUnknown)
+        if _, err := catalog.CreateEntry(model.PortalCatalogEntry{Portal: "transparencia_goias", Esfera: "ESTADUAL", Ativo: true, Aliases: []string{"transparencia_go"}}); err != nil {
+            t.Fatalf("CreateEntry falhou: %v", err)
+        }
+        deps := newTestPortalServiceDeps(repo)
+        deps.Catalog = catalog
+        svc := NewPortalImportService(NewPortalService(repo, deps), catalog)
+
+        report, err := svc.ImportWorkbook("entrega.xlsx", buildTestWorkbook(t), model.ImportOptions{})
+        if err != nil {
+            t.Fatalf("ImportWorkbook falhou: %v", err)
+        }
+
+        // O _id usa o texto da planilha (alias incluído); o nome gravado é o canônico
+        got, err := repo.GetPortalByID(computeHashedId("11/2025", "transparencia_go", "4/2025", "", 0))
+        if err != nil || got.Portal != "transparencia_goias" || got.ForaDoCatalogo {
+            t.Fatalf("alias não resolvido na importação: %+v %v", got, err)
+        }
+
+        // transparencia_ba não está no catálogo: marcada ou recusada
+        warnings := strings.Join(report.Sheets[0].Warnings, "\n")
+        baID := computeHashedId("10/11/2025", "transparencia_ba", "5/2025", "", 0)
+        ba, err := repo.GetPortalByID(baID)
+        if rejectUnknown {
+            if err == nil || report.Totals.Upserts != 1 || !strings.Contains(warnings, "ignorada") {
+                t.Fatalf("portal desconhecido deveria ser recusado: %+v\n%s", report.Totals, warnings)
+            }
+            continue
+        }
+        if err != nil || !ba.ForaDoCatalogo || report.Totals.Upserts != 2 || !strings.Contains(warnings, "fora do catálogo") {
+            t.Fatalf("portal desconhecido deveria ser marcado: %+v %v\n%s", ba, err, warnings)
+        }
+    }
+}
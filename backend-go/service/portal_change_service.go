@@ -0,0 +1,103 @@
+package service
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PortalChangeService registra e consulta o histórico de edições das linhas de portal
+type PortalChangeService interface {
+	// Record grava as diferenças campo a campo entre before e after; sem diferenças, nada é gravado
+	Record(before, after model.Portal, userID primitive.ObjectID) error
+	FindChanges(query model.PortalChangeQuery) ([]model.PortalChange, error)
+}
+
+type portalChangeService struct {
+	repo repository.PortalChangeRepository
+}
+
+func NewPortalChangeService(repo repository.PortalChangeRepository) PortalChangeService {
+	return &portalChangeService{repo: repo}
+}
+
+func (s *portalChangeService) Record(before, after model.Portal, userID primitive.ObjectID) error {
+	alteracoes, err := diffPortals(before, after)
+	if err != nil || len(alteracoes) == 0 {
+		return err
+	}
+	_, err = s.repo.InsertChange(model.PortalChange{
+		PortalID:   after.ID,
+		Portal:     after.Portal,
+		UserID:     userID,
+		Em:         time.Now(),
+		Alteracoes: alteracoes,
+	})
+	return err
+}
+
+func (s *portalChangeService) FindChanges(query model.PortalChangeQuery) ([]model.PortalChange, error) {
+	return s.repo.FindChanges(query)
+}
+
+// diffPortals compara os documentos BSON das duas versões, em ordem alfabética de campo.
+// Campos ausentes (omitempty) aparecem com valor nil; _id e version não são comparados.
+func diffPortals(before, after model.Portal) ([]model.FieldChange, error) {
+	a, err := portalDocument(before)
+	if err != nil {
+		return nil, err
+	}
+	b, err := portalDocument(after)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]bool{}
+	for k := range a {
+		fields[k] = true
+	}
+	for k := range b {
+		fields[k] = true
+	}
+	// version é controle de concorrência, não um dado do portal
+	delete(fields, "_id")
+	delete(fields, "version")
+
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	changes := []model.FieldChange{}
+	for _, name := range names {
+		if reflect.DeepEqual(a[name], b[name]) {
+			continue
+		}
+		antes, depois := a[name], b[name]
+		// Campos textuais (incluindo competências e datas) são registrados no formato de exibição
+		if v, ok := before.FieldValue(name); ok {
+			if _, text := v.(string); text {
+				antes = v
+				depois, _ = after.FieldValue(name)
+			}
+		}
+		changes = append(changes, model.FieldChange{Campo: name, Antes: antes, Depois: depois})
+	}
+	return changes, nil
+}
+
+func portalDocument(p model.Portal) (bson.M, error) {
+	raw, err := bson.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	doc := bson.M{}
+	err = bson.Unmarshal(raw, &doc)
+	return doc, err
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+    "testing"
+    "time"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestUpdatePortalFieldsMap_RecordsFieldChanges(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deps := newTestPortalServiceDeps(repo)
+    svc := NewPortalService(repo, deps)
+    alice, bob := primitive.NewObjectID(), primitive.NewObjectID()
+
+    before, _ := repo.GetPortalByID("1")
+    if err := svc.UpdatePortalFieldsMap("1", bson.M{"enviar": !before.Enviar, "observacaoTimeDados": "revisado"}, alice, nil); err != nil {
+        t.Fatalf("UpdatePortalFieldsMap falhou: %v", err)
+    }
+    if err := svc.UpdatePortalFieldsMap("1", bson.M{"status": model.StatusError}, bob, nil); err != nil {
+        t.Fatalf("UpdatePortalFieldsMap falhou: %v", err)
+    }
+
+    changes, err := deps.Changes.FindChanges(model.PortalChangeQuery{PortalID: "1"})
+    if err != nil || len(changes) != 2 {
+        t.Fatalf("esperava 2 alterações, obtive %d (%v)", len(changes), err)
+    }
+    if changes[0].UserID != bob || changes[1].UserID != alice {
+        t.Fatalf("ordem/usuários inesperados: %+v", changes)
+    }
+    fields := map[string]model.FieldChange{}
+    for _, fc := range changes[1].Alteracoes {
+        fields[fc.Campo] = fc
+    }
+    if fc, ok := fields["enviar"]; !ok || fc.Antes != before.Enviar || fc.Depois != !before.Enviar {
+        t.Fatalf("alteração de enviar inesperada: %+v", fields)
+    }
+    if fc, ok := fields["observacaoTimeDados"]; !ok || fc.Antes != before.ObservacaoTimeDados || fc.Depois != "revisado" {
+        t.Fatalf("alteração de observacaoTimeDados inesperada: %+v", fields)
+    }
+    // Campos derivados recalculados pela edição também são registrados
+    second := map[string]model.FieldChange{}
+    for _, fc := range changes[0].Alteracoes {
+        second[fc.Campo] = fc
+    }
+    if second["status"].Depois != model.StatusError || second["statusManual"].Depois != true {
+        t.Fatalf("alteração de status inesperada: %+v", second)
+    }
+    if _, ok := second["enviar"]; ok {
+        t.Fatalf("enviar não mudou na segunda edição: %+v", second)
+    }
+
+    byUser, _ := deps.Changes.FindChanges(model.PortalChangeQuery{PortalID: "1", UserID: alice})
+    if len(byUser) != 1 || byUser[0].UserID != alice {
+        t.Fatalf("filtro por usuário falhou: %+v", byUser)
+    }
+    future, _ := deps.Changes.FindChanges(model.PortalChangeQuery{PortalID: "1", De: time.Now().Add(time.Hour)})
+    if len(future) != 0 {
+        t.Fatalf("filtro por data falhou: %+v", future)
+    }
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// portalColumn associa um campo do model.Portal (nome JSON) aos cabeçalhos aceitos na planilha.
+// O primeiro alias "humano" (após o nome do campo) é o cabeçalho usado nas exportações.
+type portalColumn struct {
+	Field   string
+	Aliases []string
+}
+
+// portalColumns é o de/para (COLUMN_MAP) herdado de scripts/import_excel_portals.js,
+// na mesma ordem das colunas da planilha do cliente.
+var portalColumns = []portalColumn{
+	{"_id", []string{"_id", "id", "ID"}},
+	{"referencia", []string{"referencia", "Referência", "aba", "ref"}},
+	{"portal", []string{
+		"portal", "Portal",
+		"bot", "Bot", "BOT",
+		"Nome do Bot", "Nome Bot",
+		"Portal/Bot", "Portal - Bot",
+		"Fonte", "Origem",
+		"Serviço", "Servico", "Serviços", "Servicos",
+		"Serviço/Bot", "Servicos/Bot", "Serviços/Bot",
+		"Sistema", "Produto", "Aplicação", "Aplicacao",
+	}},
+	{"esfera", []string{"esfera", "Esfera"}},
+	{"mesAnoEnvio", []string{"mesAnoEnvio", "Mês/Ano de Envio", "Mês/Ano Envio", "Mes/Ano Envio", "Mes/Ano de Envio"}},
+	{"mesAnoReferencia", []string{"mesAnoReferencia", "Mês/Ano de referência (Competência do envio atual)", "Mês/Ano Referência", "Mes/Ano Referencia", "Mes/Ano de referencia (Competencia do envio atual)"}},
+	{"volumeFonte", []string{"volumeFonte", "Volume da fonte", "Volume Fonte"}},
+	{"volumetriaDados", []string{"volumetriaDados", "Volumetria de agregação (Dados)", "Volumetria Dados"}},
+	{"volumetriaServicos", []string{"volumetriaServicos", "Volumetria a ser enviada (Serviços)", "Volumetria Serviços", "Volumetria Servicos"}},
+	{"indiceDados", []string{"indiceDados", "Índice agregação (Dados)", "Índice Dados", "Indice Dados"}},
+	{"indiceServicos", []string{"indiceServicos", "Índice agregação (Serviços)", "Índice Serviços", "Indice Servicos"}},
+	{"volumeCpfsUnicosDados", []string{"volumeCpfsUnicosDados", "Volume cpfs únicos (Dados)", "Volume CPFs Únicos (Dados)", "Volume CPFs Unicos (Dados)"}},
+	{"volumeCpfsUnicosServicos", []string{"volumeCpfsUnicosServicos", "Volume cpfs únicos (Serviços)", "Volume CPFs Únicos (Serviços)", "Volume CPFs Unicos (Servicos)"}},
+	{"mediaMovelCpfsUnicos", []string{"mediaMovelCpfsUnicos", "Média Móvel CPFs únicos (últimos 12 meses)", "Média Móvel CPFs Únicos", "Media Movel CPFs Unicos"}},
+	{"ultimoMesEnviado", []string{"ultimoMesEnviado", "Último mês enviado", "Último Mês Enviado", "Ultimo Mes Enviado"}},
+	{"ultimaReferencia", []string{"ultimaReferencia", "Última referência enviada", "Última Referência", "Ultima Referencia"}},
+	{"ultimaVolumetriaEnviada", []string{"ultimaVolumetriaEnviada", "Última Volumetria Total enviada", "Última Volumetria Enviada", "Ultima Volumetria Enviada"}},
+	{"mediaMovelUltimos12Meses", []string{"mediaMovelUltimos12Meses", "Média Móvel Total (últimos 12 meses)", "Média Móvel (Últimos 12 Meses)", "Media Movel (Ultimos 12 Meses)"}},
+	{"media", []string{"media", "Média Histórica Total", "Média", "Media"}},
+	{"minimo", []string{"minimo", "Mínimo Total", "Mínimo", "Minimo"}},
+	{"mesCompetenciaMinimo", []string{"mesCompetenciaMinimo", "Mês competência Mínimo", "Mês Competência Mínimo", "Mes Competencia Minimo"}},
+	{"maximo", []string{"maximo", "Máximo", "Maximo"}},
+	{"mesCompetenciaMaximo", []string{"mesCompetenciaMaximo", "Mês competência Máximo", "Mês Competência Máximo", "Mes Competencia Maximo"}},
+	{"percentualVolumetriaUltima", []string{"percentualVolumetriaUltima", "% Volumetria vs última volumetria enviada", "% Volumetria vs Última", "% Volumetria vs Ultima"}},
+	{"percentualVolumetriaMediaMovel", []string{"percentualVolumetriaMediaMovel", "% Volumetria vs Média Móvel", "% Volumetria vs Media Movel"}},
+	{"percentualVolumetriaMedia", []string{"percentualVolumetriaMedia", "% Volumetria vs Média", "% Volumetria vs Media"}},
+	{"percentualVolumetriaMinimo", []string{"percentualVolumetriaMinimo", "% Volumetria vs Mínimo", "% Volumetria vs Minimo"}},
+	{"percentualVolumetriaMaximo", []string{"percentualVolumetriaMaximo", "% Volumetria vs Máximo", "% Volumetria vs Maximo"}},
+	{"pulouCompetencia", []string{"pulouCompetencia", "Pulou competência?", "Pulou Competência?", "Pulou Competencia?"}},
+	{"defasagemNosDados", []string{"defasagemNosDados", "Há defasagem nos dados?", "Defasagem nos Dados?", "Defasagem nos Dados"}},
+	{"novosDados", []string{"novosDados", "Com novos dados?", "Novos Dados?", "Novos Dados"}},
+	{"status", []string{"status", "Status"}},
+	{"observacaoTimeDados", []string{"observacaoTimeDados", "Observação - Time Dados", "Observação Time Dados", "Observacao Time Dados"}},
+	{"enviar", []string{"enviar", "Enviar?", "Enviar"}},
+}
+
+// normalizeHeader remove espaços nas pontas, caixa e acentuação (equivalente ao NFD + strip do script Node)
+func normalizeHeader(h string) string {
+	decomposed := norm.NFD.String(strings.ToLower(strings.TrimSpace(h)))
+	var b strings.Builder
+	for _, r := range decomposed {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// detectColumns devolve, para cada campo, o índice da primeira coluna cujo cabeçalho
+// normalizado coincide com algum alias (-1 quando não encontrado).
+func detectColumns(headers []string) map[string]int {
+	normalized := make([]string, len(headers))
+	for i, h := range headers {
+		normalized[i] = normalizeHeader(h)
+	}
+	indexes := make(map[string]int, len(portalColumns))
+	for _, col := range portalColumns {
+		indexes[col.Field] = -1
+		keys := make(map[string]bool, len(col.Aliases))
+		for _, a := range col.Aliases {
+			keys[normalizeHeader(a)] = true
+		}
+		for i, h := range normalized {
+			if keys[h] {
+				indexes[col.Field] = i
+				break
+			}
+		}
+	}
+	return indexes
+}
+
+var nonNumeric = regexp.MustCompile(`[^0-9.\-]`)
+
+// parseBRNumber interpreta números no formato pt-BR ("1.234,56", "95,6%")
+func parseBRNumber(s string) (float64, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, false
+	}
+	s = strings.ReplaceAll(s, ".", "")
+	s = strings.ReplaceAll(s, ",", ".")
+	s = nonNumeric.ReplaceAllString(s, "")
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+		return 0, false
+	}
+	return n, true
+}
+
+// toInt segue a regra do script: números nativos são arredondados; textos passam pelo parse pt-BR
+func toInt(c sheetCell) int {
+	if c.Numeric {
+		return int(math.Round(c.Number))
+	}
+	n, _ := parseBRNumber(c.Text)
+	return int(math.Round(n))
+}
+
+func toFloat(c sheetCell) float64 {
+	if c.Numeric {
+		return c.Number
+	}
+	n, _ := parseBRNumber(c.Text)
+	return n
+}
+
+func toBool(c sheetCell) bool {
+	switch strings.ToLower(strings.TrimSpace(c.Text)) {
+	case "true", "sim", "yes", "1", "y":
+		return true
+	}
+	return false
+}
+
+var sheetDatePattern = regexp.MustCompile(`(\d{8})`)
+
+// extractDeliveryDate extrai a data (ddMMyyyy) do nome da aba, ex.: "Entrega 10112025" => "10/11/2025"
+func extractDeliveryDate(sheetName, dateFmt string) string {
+	m := sheetDatePattern.FindString(sheetName)
+	if m == "" {
+		return ""
+	}
+	dd, mm, yyyy := m[0:2], m[2:4], m[4:8]
+	switch strings.TrimSpace(dateFmt) {
+	case "dd/MM/yy":
+		return fmt.Sprintf("%s/%s/%s", dd, mm, yyyy[2:])
+	case "dd/MM/yyy":
+		return fmt.Sprintf("%s/%s/%s", dd, mm, yyyy[1:])
+	default:
+		return fmt.Sprintf("%s/%s/%s", dd, mm, yyyy)
+	}
+}
+
+// computeHashedId gera o _id estável (SHA-1) a partir de dataEntrega|portal|mesAnoReferencia.
+// Quando algum desses campos está vazio, inclui aba e linha para não colidir registros incompletos.
+func computeHashedId(deliveryDate, portal, mesAnoReferencia, sheetName string, rowIndex int) string {
+	deliveryDate = strings.TrimSpace(deliveryDate)
+	portal = strings.TrimSpace(portal)
+	mesAnoReferencia = strings.TrimSpace(mesAnoReferencia)
+	base := deliveryDate + "|" + portal + "|" + mesAnoReferencia
+	if deliveryDate == "" || portal == "" || mesAnoReferencia == "" {
+		base = fmt.Sprintf("%s|%s|row:%d", base, strings.TrimSpace(sheetName), rowIndex)
+	}
+	sum := sha1.Sum([]byte(base))
+	return hex.EncodeToString(sum[:])
+}
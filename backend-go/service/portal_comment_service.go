@@ -0,0 +1,201 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrCommentNotFound  = errors.New("comentário não encontrado")
+	ErrCommentForbidden = errors.New("apenas o autor pode alterar o comentário")
+	ErrInvalidComment   = errors.New("comentário inválido")
+)
+
+// PortalCommentService mantém as discussões das linhas de portal. Comentários não resolvidos
+// continuam visíveis nas competências seguintes do mesmo portal até serem resolvidos.
+type PortalCommentService interface {
+	// ListComments retorna os comentários da linha e os não resolvidos herdados de competências anteriores
+	ListComments(portalID string) ([]model.PortalComment, error)
+	AddComment(portalID, texto string, author *model.User) (model.PortalComment, error)
+	EditComment(portalID string, commentID primitive.ObjectID, texto string, user *model.User) (model.PortalComment, error)
+	DeleteComment(portalID string, commentID primitive.ObjectID, user *model.User) error
+	SetResolved(portalID string, commentID primitive.ObjectID, resolvido bool, user *model.User) (model.PortalComment, error)
+	// AttachLatestUnresolved preenche UltimoComentario das linhas (uma consulta para a página inteira)
+	AttachLatestUnresolved(portals []model.Portal) error
+}
+
+type portalCommentService struct {
+	repo       repository.PortalCommentRepository
+	portalRepo repository.PortalRepository
+}
+
+func NewPortalCommentService(repo repository.PortalCommentRepository, portalRepo repository.PortalRepository) PortalCommentService {
+	return &portalCommentService{repo: repo, portalRepo: portalRepo}
+}
+
+// row carrega a linha de portal; como em GET /portals/:id, qualquer falha vale como não encontrada
+func (s *portalCommentService) row(portalID string) (model.Portal, error) {
+	row, err := s.portalRepo.GetPortalByID(portalID)
+	if err != nil {
+		return row, fmt.Errorf("%w: %s", ErrPortalNotFound, portalID)
+	}
+	return row, nil
+}
+
+// visibleOn indica se o comentário aparece na linha: os da própria linha sempre; os de outras
+// linhas do portal apenas se não resolvidos e de competência anterior
+func visibleOn(c model.PortalComment, row model.Portal) (visible, inherited bool) {
+	if c.PortalID == row.ID {
+		return true, false
+	}
+	if c.Portal != row.Portal || c.Resolvido {
+		return false, false
+	}
+	origin, current := c.Competencia, row.MesAnoReferencia
+	if !origin.HasMonth() || !current.HasMonth() || !origin.Before(current) {
+		return false, false
+	}
+	return true, true
+}
+
+func (s *portalCommentService) ListComments(portalID string) ([]model.PortalComment, error) {
+	row, err := s.row(portalID)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.repo.FindComments(model.PortalCommentQuery{Portais: []string{row.Portal}})
+	if err != nil {
+		return nil, err
+	}
+	comments := []model.PortalComment{}
+	for _, c := range all {
+		if visible, inherited := visibleOn(c, row); visible {
+			c.Herdado = inherited
+			comments = append(comments, c)
+		}
+	}
+	return comments, nil
+}
+
+func (s *portalCommentService) AddComment(portalID, texto string, author *model.User) (model.PortalComment, error) {
+	texto = strings.TrimSpace(texto)
+	if texto == "" {
+		return model.PortalComment{}, fmt.Errorf("%w: texto é obrigatório", ErrInvalidComment)
+	}
+	row, err := s.row(portalID)
+	if err != nil {
+		return model.PortalComment{}, err
+	}
+	return s.repo.InsertComment(model.PortalComment{
+		PortalID:    row.ID,
+		Portal:      row.Portal,
+		Competencia: row.MesAnoReferencia,
+		AutorID:     author.ID,
+		AutorNome:   author.Nome,
+		Texto:       texto,
+		CriadoEm:    time.Now(),
+	})
+}
+
+// visibleComment carrega o comentário garantindo que ele aparece na linha informada
+func (s *portalCommentService) visibleComment(portalID string, commentID primitive.ObjectID) (model.PortalComment, error) {
+	row, err := s.row(portalID)
+	if err != nil {
+		return model.PortalComment{}, err
+	}
+	comment, err := s.repo.GetComment(commentID)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return comment, ErrCommentNotFound
+	}
+	if err != nil {
+		return comment, err
+	}
+	// Um comentário resolvido deixa de ser herdado, mas ainda pode ser reaberto pela linha de origem
+	if visible, _ := visibleOn(comment, row); !visible {
+		return comment, ErrCommentNotFound
+	}
+	return comment, nil
+}
+
+func (s *portalCommentService) EditComment(portalID string, commentID primitive.ObjectID, texto string, user *model.User) (model.PortalComment, error) {
+	texto = strings.TrimSpace(texto)
+	if texto == "" {
+		return model.PortalComment{}, fmt.Errorf("%w: texto é obrigatório", ErrInvalidComment)
+	}
+	comment, err := s.visibleComment(portalID, commentID)
+	if err != nil {
+		return comment, err
+	}
+	if comment.AutorID != user.ID {
+		return comment, ErrCommentForbidden
+	}
+	now := time.Now()
+	comment.Texto = texto
+	comment.EditadoEm = &now
+	return comment, s.repo.UpdateComment(comment)
+}
+
+func (s *portalCommentService) DeleteComment(portalID string, commentID primitive.ObjectID, user *model.User) error {
+	comment, err := s.visibleComment(portalID, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.AutorID != user.ID {
+		return ErrCommentForbidden
+	}
+	return s.repo.DeleteComment(comment.ID)
+}
+
+func (s *portalCommentService) SetResolved(portalID string, commentID primitive.ObjectID, resolvido bool, user *model.User) (model.PortalComment, error) {
+	comment, err := s.visibleComment(portalID, commentID)
+	if err != nil {
+		return comment, err
+	}
+	comment.Resolvido = resolvido
+	if resolvido {
+		now := time.Now()
+		comment.ResolvidoPor = &user.ID
+		comment.ResolvidoEm = &now
+	} else {
+		comment.ResolvidoPor = nil
+		comment.ResolvidoEm = nil
+	}
+	return comment, s.repo.UpdateComment(comment)
+}
+
+func (s *portalCommentService) AttachLatestUnresolved(portals []model.Portal) error {
+	if len(portals) == 0 {
+		return nil
+	}
+	names := []string{}
+	seen := map[string]bool{}
+	for _, p := range portals {
+		if !seen[p.Portal] {
+			seen[p.Portal] = true
+			names = append(names, p.Portal)
+		}
+	}
+	open, err := s.repo.FindComments(model.PortalCommentQuery{Portais: names, SomenteAbertos: true})
+	if err != nil {
+		return err
+	}
+	for i := range portals {
+		// Ordem cronológica: o último visível é o mais recente
+		for _, c := range open {
+			if visible, inherited := visibleOn(c, portals[i]); visible {
+				c.Herdado = inherited
+				latest := c
+				portals[i].UltimoComentario = &latest
+			}
+		}
+	}
+	return nil
+}
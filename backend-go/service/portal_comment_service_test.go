@@ -0,0 +1,79 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPortalComments_OwnershipResolutionAndCarryOver(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := NewPortalCommentService(repository.NewMockPortalCommentRepository(), repo)
+    alice := &model.User{ID: primitive.NewObjectID(), Nome: "Alice", Role: "editor"}
+    bob := &model.User{ID: primitive.NewObjectID(), Nome: "Bob", Role: "editor"}
+
+    rows := []model.Portal{
+        {ID: "c1", Portal: "transparencia_go", MesAnoReferencia: mustCompetencia("08/2024")},
+        {ID: "c2", Portal: "transparencia_go", MesAnoReferencia: mustCompetencia("09/2024")},
+    }
+    for _, row := range rows {
+        if err := repo.UpsertPortal(row); err != nil {
+            t.Fatalf("UpsertPortal falhou: %v", err)
+        }
+    }
+
+    open, err := svc.AddComment("c1", "volume caiu, confirmar com a fonte", alice)
+    if err != nil {
+        t.Fatalf("AddComment falhou: %v", err)
+    }
+    closed, _ := svc.AddComment("c1", "layout ok", alice)
+    if _, err := svc.SetResolved("c1", closed.ID, true, bob); err != nil {
+        t.Fatalf("SetResolved falhou: %v", err)
+    }
+    if _, err := svc.AddComment("c1", "   ", alice); !errors.Is(err, ErrInvalidComment) {
+        t.Fatalf("esperava ErrInvalidComment, obtive %v", err)
+    }
+
+    // Só o não resolvido é herdado pela competência seguinte
+    next, err := svc.ListComments("c2")
+    if err != nil || len(next) != 1 || next[0].ID != open.ID || !next[0].Herdado {
+        t.Fatalf("herança inesperada: %+v (%v)", next, err)
+    }
+    if own, _ := svc.ListComments("c1"); len(own) != 2 || own[0].Herdado {
+        t.Fatalf("comentários da linha inesperados: %+v", own)
+    }
+
+    if _, err := svc.EditComment("c2", open.ID, "outro texto", bob); !errors.Is(err, ErrCommentForbidden) {
+        t.Fatalf("esperava ErrCommentForbidden ao editar comentário alheio, obtive %v", err)
+    }
+    if err := svc.DeleteComment("c1", open.ID, bob); !errors.Is(err, ErrCommentForbidden) {
+        t.Fatalf("esperava ErrCommentForbidden ao excluir comentário alheio, obtive %v", err)
+    }
+    edited, err := svc.EditComment("c2", open.ID, "volume caiu; fonte confirmou", alice)
+    if err != nil || edited.EditadoEm == nil {
+        t.Fatalf("edição pelo autor falhou: %+v (%v)", edited, err)
+    }
+
+    page := []model.Portal{rows[0], rows[1]}
+    if err := svc.AttachLatestUnresolved(page); err != nil {
+        t.Fatalf("AttachLatestUnresolved falhou: %v", err)
+    }
+    if page[1].UltimoComentario == nil || page[1].UltimoComentario.Texto != "volume caiu; fonte confirmou" {
+        t.Fatalf("último comentário não resolvido ausente: %+v", page[1].UltimoComentario)
+    }
+
+    // Resolvido, deixa de aparecer na listagem e de ser herdado
+    if _, err := svc.SetResolved("c1", open.ID, true, bob); err != nil {
+        t.Fatalf("SetResolved falhou: %v", err)
+    }
+    page = []model.Portal{rows[0], rows[1]}
+    _ = svc.AttachLatestUnresolved(page)
+    if page[0].UltimoComentario != nil || page[1].UltimoComentario != nil {
+        t.Fatalf("comentário resolvido ainda exibido: %+v %+v", page[0].UltimoComentario, page[1].UltimoComentario)
+    }
+    if err := svc.DeleteComment("c1", open.ID, alice); err != nil {
+        t.Fatalf("exclusão pelo autor falhou: %v", err)
+    }
+}
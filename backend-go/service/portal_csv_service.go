@@ -0,0 +1,92 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+)
+
+// PortalCSVService importa e exporta portais no CSV separado por ';'
+type PortalCSVService interface {
+	ExportCSV(w io.Writer, query model.PortalQuery) error
+	ImportCSV(fileName string, r io.Reader, dryRun bool) (*model.ImportReport, error)
+}
+
+type portalCSVService struct {
+	portalService PortalService
+	catalog       PortalCatalogService
+}
+
+func NewPortalCSVService(portalService PortalService, catalog PortalCatalogService) PortalCSVService {
+	return &portalCSVService{portalService: portalService, catalog: catalog}
+}
+
+// ExportCSV escreve os portais que atendem à consulta (filtros e ordenação; paginação é respeitada se informada)
+func (s *portalCSVService) ExportCSV(w io.Writer, query model.PortalQuery) error {
+	page, err := s.portalService.FindPortals(query)
+	if err != nil {
+		return err
+	}
+	return repository.NewPortalCSVEncoder(w).Encode(page.Items)
+}
+
+// ImportCSV grava (upsert) os portais do CSV e relata as linhas que não puderam ser interpretadas.
+// Linhas sem _id recebem o mesmo hash estável usado na importação da planilha, calculado com o
+// texto das células.
+func (s *portalCSVService) ImportCSV(fileName string, r io.Reader, dryRun bool) (*model.ImportReport, error) {
+	// O _id usa o texto das células, como o script Node: reimportar o mesmo arquivo atualiza as
+	// mesmas linhas, mesmo que o catálogo troque o nome pelo canônico
+	portals, rowErrors, err := repository.NewPortalCSVDecoder(r).WithID(func(dataEntrega, portal, mesAnoReferencia string, index int) string {
+		return computeHashedId(dataEntrega, portal, mesAnoReferencia, fileName, index)
+	}).Decode()
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := model.ImportSheetReport{Sheet: fileName, HeaderRow: 1, Errors: rowErrors}
+	ids := map[string]bool{}
+	for _, portal := range portals {
+		sheet.ProcessedRows++
+		resolved, err := s.catalog.Resolve(portal)
+		if errors.Is(err, ErrUnknownPortal) {
+			sheet.Warnings = append(sheet.Warnings, fmt.Sprintf("linha ignorada: %v", err))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		portal = resolved
+		ids[portal.ID] = true
+		if portal.ForaDoCatalogo {
+			sheet.Warnings = append(sheet.Warnings, fmt.Sprintf("portal %s: %q fora do catálogo", portal.ID, portal.Portal))
+		}
+		if dryRun {
+			continue
+		}
+		saved, err := s.portalService.SavePortal(portal)
+		if err != nil {
+			log.Printf("Erro ao gravar portal %s do CSV: %v", portal.ID, err)
+			sheet.Warnings = append(sheet.Warnings, fmt.Sprintf("portal %s: %v", portal.ID, err))
+			continue
+		}
+		if warning, ok := skippedCompetenciaWarning(saved); ok {
+			sheet.Warnings = append(sheet.Warnings, fmt.Sprintf("portal %s: %s", portal.ID, warning))
+		}
+		sheet.Upserts++
+	}
+	sheet.UniqueIDCount = len(ids)
+
+	return &model.ImportReport{
+		File:        fileName,
+		HeaderRow:   1,
+		DryRun:      dryRun,
+		Sheets:      []model.ImportSheetReport{sheet},
+		Totals:      model.ImportTotals{ProcessedRows: sheet.ProcessedRows, Upserts: sheet.Upserts},
+		GeneratedAt: time.Now(),
+	}, nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+    "bytes"
+    "reflect"
+    "strings"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func TestExportImportCSV_RoundTrip(t *testing.T) {
+    src := repository.NewMockPortalRepository()
+    svc := NewPortalCSVService(newTestPortalService(src), newTestPortalCatalogService())
+
+    var buf bytes.Buffer
+    if err := svc.ExportCSV(&buf, model.PortalQuery{Esfera: "MUNICIPAL"}); err != nil {
+        t.Fatalf("ExportCSV falhou: %v", err)
+    }
+    lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+    if len(lines) != 3 {
+        t.Fatalf("esperava cabeçalho + 2 linhas, obtive %d", len(lines))
+    }
+    if !strings.HasPrefix(lines[0], "_id;referencia;dataEntrega;portal;") {
+        t.Fatalf("cabeçalho inesperado: %s", lines[0])
+    }
+
+    decoded, rowErrors, err := repository.NewPortalCSVDecoder(bytes.NewReader(buf.Bytes())).Decode()
+    if err != nil || len(rowErrors) != 0 {
+        t.Fatalf("Decode falhou: %v %+v", err, rowErrors)
+    }
+    original, _ := src.GetPortalByID("2")
+    if !reflect.DeepEqual(decoded[0], original) {
+        t.Fatalf("round-trip do codec divergente:\n%+v\n%+v", original, decoded[0])
+    }
+
+    dst := repository.NewMockPortalRepository()
+    report, err := NewPortalCSVService(newTestPortalService(dst), newTestPortalCatalogService()).ImportCSV("portals.csv", &buf, false)
+    if err != nil {
+        t.Fatalf("ImportCSV falhou: %v", err)
+    }
+    if report.Totals.Upserts != 2 || len(report.Sheets[0].Errors) != 0 {
+        t.Fatalf("relatório inesperado: %+v", report)
+    }
+}
+
+func TestImportCSV_ReportsInvalidRows(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := NewPortalCSVService(newTestPortalService(repo), newTestPortalCatalogService())
+
+    csv := "Portal;Esfera;MesAnoReferencia;VolumeFonte;Enviar\n" +
+        "transparencia_ba;ESTADUAL;05/2025;1200;true\n" +
+        "transparencia_pe;ESTADUAL;05/2025;mil;true\n" +
+        "transparencia_ce;ESTADUAL\n"
+    report, err := svc.ImportCSV("portals.csv", strings.NewReader(csv), true)
+    if err != nil {
+        t.Fatalf("ImportCSV falhou: %v", err)
+    }
+    sheet := report.Sheets[0]
+    if sheet.ProcessedRows != 1 || sheet.Upserts != 0 {
+        t.Fatalf("esperava 1 linha válida em dry-run: %+v", sheet)
+    }
+    if len(sheet.Errors) != 2 || sheet.Errors[0].Line != 3 || sheet.Errors[1].Line != 4 {
+        t.Fatalf("erros de linha inesperados: %+v", sheet.Errors)
+    }
+}
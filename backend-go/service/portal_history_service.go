@@ -0,0 +1,178 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrPortalNotFound indica que o portal não possui identidade/entregas registradas
+var ErrPortalNotFound = errors.New("portal não encontrado")
+
+// PortalHistoryService mantém a identidade do portal e suas entregas mensais a partir das linhas de portals
+type PortalHistoryService interface {
+	// RecordDelivery registra (ou atualiza) a entrega correspondente à linha do portal
+	RecordDelivery(portal model.Portal) error
+	GetHistory(portal string) (model.PortalHistory, error)
+	// Rebuild é a migração que constrói portal_identities/entregas a partir da coleção portals
+	Rebuild() (*model.HistoryRebuildReport, error)
+	// Clear desfaz Rebuild: remove portal_identities e entregas
+	Clear() error
+}
+
+type portalHistoryService struct {
+	repo       repository.PortalHistoryRepository
+	portalRepo repository.PortalRepository
+}
+
+func NewPortalHistoryService(repo repository.PortalHistoryRepository, portalRepo repository.PortalRepository) PortalHistoryService {
+	return &portalHistoryService{repo: repo, portalRepo: portalRepo}
+}
+
+// entregaFromPortal extrai a entrega da linha; linhas sem competência válida não formam entrega
+func entregaFromPortal(p model.Portal) (model.Entrega, bool) {
+	competencia := p.MesAnoReferencia
+	if !competencia.HasMonth() || p.Portal == "" {
+		return model.Entrega{}, false
+	}
+	return model.Entrega{
+		ID:                       model.EntregaID(p.Portal, competencia),
+		Portal:                   p.Portal,
+		Competencia:              competencia,
+		MesAnoReferencia:         p.MesAnoReferencia,
+		MesAnoEnvio:              p.MesAnoEnvio,
+		Referencia:               p.Referencia,
+		DataEntrega:              p.DataEntrega,
+		PortalRowID:              p.ID,
+		VolumeFonte:              p.VolumeFonte,
+		VolumetriaDados:          p.VolumetriaDados,
+		VolumetriaServicos:       p.VolumetriaServicos,
+		VolumeCpfsUnicosDados:    p.VolumeCpfsUnicosDados,
+		VolumeCpfsUnicosServicos: p.VolumeCpfsUnicosServicos,
+		IndiceDados:              p.IndiceDados,
+		IndiceServicos:           p.IndiceServicos,
+		Status:                   p.Status,
+		Enviar:                   p.Enviar,
+	}, true
+}
+
+func (s *portalHistoryService) RecordDelivery(portal model.Portal) error {
+	entrega, ok := entregaFromPortal(portal)
+	if !ok {
+		return nil
+	}
+	existing, err := s.repo.GetEntrega(entrega.ID)
+	switch {
+	case err == nil:
+		// Competência reenviada: uma linha de envio anterior não substitui a mais recente
+		if existing.PortalRowID != entrega.PortalRowID && envioAfter(existing.MesAnoEnvio, entrega.MesAnoEnvio) {
+			return nil
+		}
+	case !errors.Is(err, mongo.ErrNoDocuments):
+		return err
+	}
+	if err := s.repo.UpsertEntrega(entrega); err != nil {
+		return err
+	}
+	return s.refreshIdentity(portal.Portal, portal.Esfera)
+}
+
+// refreshIdentity recalcula a identidade do portal a partir das entregas gravadas
+func (s *portalHistoryService) refreshIdentity(portal, esfera string) error {
+	entregas, err := s.repo.GetEntregas(portal)
+	if err != nil || len(entregas) == 0 {
+		return err
+	}
+	if esfera == "" {
+		if current, err := s.repo.GetIdentity(portal); err == nil {
+			esfera = current.Esfera
+		}
+	}
+	last := entregas[len(entregas)-1]
+	return s.repo.UpsertIdentity(model.PortalIdentity{
+		Portal:                  portal,
+		Esfera:                  esfera,
+		PrimeiraCompetencia:     entregas[0].Competencia,
+		UltimaReferencia:        last.Competencia.String(),
+		UltimoMesEnviado:        last.MesAnoEnvio,
+		UltimaVolumetriaEnviada: last.VolumetriaServicos,
+		TotalEntregas:           len(entregas),
+		AtualizadoEm:            time.Now(),
+	})
+}
+
+func (s *portalHistoryService) GetHistory(portal string) (model.PortalHistory, error) {
+	identity, err := s.repo.GetIdentity(portal)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.PortalHistory{}, ErrPortalNotFound
+	}
+	if err != nil {
+		return model.PortalHistory{}, err
+	}
+	entregas, err := s.repo.GetEntregas(portal)
+	if err != nil {
+		return model.PortalHistory{}, err
+	}
+	return model.PortalHistory{Portal: identity, Entregas: entregas}, nil
+}
+
+func (s *portalHistoryService) Clear() error {
+	return s.repo.Clear()
+}
+
+// Rebuild lê todas as linhas de portals e grava uma entrega por (portal, competência) e uma
+// identidade por portal. É idempotente: pode ser executada novamente após importações antigas.
+func (s *portalHistoryService) Rebuild() (*model.HistoryRebuildReport, error) {
+	rows, err := s.portalRepo.GetAllPortals()
+	if err != nil {
+		return nil, err
+	}
+	report := &model.HistoryRebuildReport{ProcessedRows: len(rows)}
+
+	entregas := map[string]model.Entrega{}
+	var order []string
+	esferas := map[string]string{}
+	for _, row := range rows {
+		entrega, ok := entregaFromPortal(row)
+		if !ok {
+			report.Skipped = append(report.Skipped, fmt.Sprintf("%s (%s): sem competência válida (%q)", row.ID, row.Portal, row.MesAnoReferencia.String()))
+			continue
+		}
+		if row.Esfera != "" {
+			esferas[row.Portal] = row.Esfera
+		}
+		existing, seen := entregas[entrega.ID]
+		if !seen {
+			order = append(order, entrega.ID)
+		} else if envioAfter(existing.MesAnoEnvio, entrega.MesAnoEnvio) {
+			continue
+		}
+		entregas[entrega.ID] = entrega
+	}
+
+	portals := map[string]bool{}
+	var portalOrder []string
+	for _, id := range order {
+		entrega := entregas[id]
+		if err := s.repo.UpsertEntrega(entrega); err != nil {
+			return nil, err
+		}
+		if !portals[entrega.Portal] {
+			portals[entrega.Portal] = true
+			portalOrder = append(portalOrder, entrega.Portal)
+		}
+	}
+	for _, portal := range portalOrder {
+		if err := s.refreshIdentity(portal, esferas[portal]); err != nil {
+			return nil, err
+		}
+	}
+	report.Entregas = len(order)
+	report.Portais = len(portalOrder)
+	return report, nil
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+    "errors"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func TestSavePortal_RecordsEntregaAndIdentity(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    historyRepo := repository.NewMockPortalHistoryRepository()
+    history := NewPortalHistoryService(historyRepo, repo)
+    deps := newTestPortalServiceDeps(repo)
+    deps.History = history
+    svc := NewPortalService(repo, deps)
+
+    rows := []model.Portal{
+        {ID: "r2", Portal: "transparencia_ba", Esfera: "ESTADUAL", MesAnoEnvio: mustCompetencia("12/2024"), MesAnoReferencia: mustCompetencia("09/2024"), VolumetriaServicos: 900},
+        {ID: "r1", Portal: "transparencia_ba", Esfera: "ESTADUAL", MesAnoEnvio: mustCompetencia("11/2024"), MesAnoReferencia: mustCompetencia("08/2024"), VolumetriaServicos: 800},
+        // Reenvio mais antigo da mesma competência não substitui a entrega vigente
+        {ID: "r0", Portal: "transparencia_ba", Esfera: "ESTADUAL", MesAnoEnvio: mustCompetencia("10/2024"), MesAnoReferencia: mustCompetencia("09/2024"), VolumetriaServicos: 1},
+    }
+    for _, row := range rows {
+        if _, err := svc.SavePortal(row); err != nil {
+            t.Fatalf("SavePortal falhou: %v", err)
+        }
+    }
+
+    got, err := history.GetHistory("transparencia_ba")
+    if err != nil {
+        t.Fatalf("GetHistory falhou: %v", err)
+    }
+    if len(got.Entregas) != 2 || got.Entregas[0].MesAnoReferencia.String() != "08/2024" || got.Entregas[1].PortalRowID != "r2" {
+        t.Fatalf("entregas inesperadas: %+v", got.Entregas)
+    }
+    identity := got.Portal
+    if identity.Esfera != "ESTADUAL" || identity.PrimeiraCompetencia.String() != "08/2024" || identity.UltimaReferencia != "09/2024" ||
+        identity.UltimoMesEnviado.String() != "12/2024" || identity.UltimaVolumetriaEnviada != 900 || identity.TotalEntregas != 2 {
+        t.Fatalf("identidade inesperada: %+v", identity)
+    }
+
+    if _, err := history.GetHistory("inexistente"); !errors.Is(err, ErrPortalNotFound) {
+        t.Fatalf("esperava ErrPortalNotFound, obtive %v", err)
+    }
+}
+
+func TestPortalHistoryRebuild(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    repo.InsertPortal(model.Portal{ID: "x", Portal: "transparencia_al", MesAnoReferencia: model.CompetenciaSemNovosDados})
+    history := NewPortalHistoryService(repository.NewMockPortalHistoryRepository(), repo)
+
+    report, err := history.Rebuild()
+    if err != nil {
+        t.Fatalf("Rebuild falhou: %v", err)
+    }
+    if report.ProcessedRows != 6 || report.Entregas != 5 || report.Portais != 5 || len(report.Skipped) != 1 {
+        t.Fatalf("relatório inesperado: %+v", report)
+    }
+    got, err := history.GetHistory("transparencia_al")
+    if err != nil || len(got.Entregas) != 1 || got.Portal.UltimaReferencia != "08/2024" {
+        t.Fatalf("histórico inesperado: %+v %v", got, err)
+    }
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// PortalImportService importa a planilha multi-abas do cliente (substitui scripts/import_excel_portals.js)
+type PortalImportService interface {
+	ImportWorkbook(fileName string, r io.Reader, opts model.ImportOptions) (*model.ImportReport, error)
+}
+
+type portalImportService struct {
+	portalService PortalService
+	catalog       PortalCatalogService
+}
+
+func NewPortalImportService(portalService PortalService, catalog PortalCatalogService) PortalImportService {
+	return &portalImportService{portalService: portalService, catalog: catalog}
+}
+
+// sheetCell guarda o valor bruto da célula e, quando numérica, o número nativo
+// (o script Node recebia números já tipados do XLSX e só fazia parse pt-BR de textos).
+type sheetCell struct {
+	Text    string
+	Number  float64
+	Numeric bool
+}
+
+func (c sheetCell) isBlank() bool {
+	return strings.TrimSpace(c.Text) == ""
+}
+
+func (s *portalImportService) ImportWorkbook(fileName string, r io.Reader, opts model.ImportOptions) (*model.ImportReport, error) {
+	if opts.HeaderRow <= 0 {
+		opts.HeaderRow = 2
+	}
+	if opts.DateFmt == "" {
+		opts.DateFmt = "dd/MM/yyyy"
+	}
+
+	f, err := excelize.OpenReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("arquivo Excel inválido: %v", err)
+	}
+	defer f.Close()
+
+	sheetNames := f.GetSheetList()
+	if len(sheetNames) == 0 {
+		return nil, fmt.Errorf("arquivo Excel não possui abas")
+	}
+
+	report := &model.ImportReport{
+		File:      fileName,
+		HeaderRow: opts.HeaderRow,
+		DryRun:    opts.DryRun,
+		Sheets:    []model.ImportSheetReport{},
+	}
+
+	for _, sheetName := range sheetNames {
+		rows, err := readSheet(f, sheetName)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler aba %q: %v", sheetName, err)
+		}
+		sheetReport := model.ImportSheetReport{Sheet: sheetName, HeaderRow: opts.HeaderRow, DetectedMap: map[string]*string{}}
+		if len(rows) <= opts.HeaderRow-1 {
+			sheetReport.Warnings = append(sheetReport.Warnings, fmt.Sprintf("aba vazia ou sem cabeçalhos na linha %d", opts.HeaderRow))
+			report.Sheets = append(report.Sheets, sheetReport)
+			continue
+		}
+
+		headers := make([]string, len(rows[opts.HeaderRow-1]))
+		for i, c := range rows[opts.HeaderRow-1] {
+			headers[i] = c.Text
+		}
+		columns := detectColumns(headers)
+		for _, col := range portalColumns {
+			if idx := columns[col.Field]; idx >= 0 {
+				h := headers[idx]
+				sheetReport.DetectedMap[col.Field] = &h
+			} else {
+				sheetReport.DetectedMap[col.Field] = nil
+			}
+		}
+
+		sheetDeliveryDate := extractDeliveryDate(sheetName, opts.DateFmt)
+		ids := map[string]bool{}
+		for rIdx, row := range rows[opts.HeaderRow:] {
+			if rowIsBlank(row) {
+				continue
+			}
+			portal, problems := portalFromRow(row, columns, sheetName, sheetDeliveryDate, rIdx)
+			if resolved, err := s.catalog.Resolve(portal); err == nil {
+				portal = resolved
+			} else if errors.Is(err, ErrUnknownPortal) {
+				problems = append(problems, err.Error())
+			} else {
+				return nil, err
+			}
+			ids[portal.ID] = true
+			sheetReport.ProcessedRows++
+			if len(problems) > 0 {
+				sheetReport.Warnings = append(sheetReport.Warnings, fmt.Sprintf("linha %d ignorada: %s", rIdx+opts.HeaderRow+1, strings.Join(problems, "; ")))
+				continue
+			}
+			if portal.ForaDoCatalogo {
+				sheetReport.Warnings = append(sheetReport.Warnings, fmt.Sprintf("linha %d: portal %q fora do catálogo", rIdx+opts.HeaderRow+1, portal.Portal))
+			}
+
+			if opts.DryRun {
+				continue
+			}
+			saved, err := s.portalService.SavePortal(portal)
+			if err != nil {
+				log.Printf("Erro ao gravar portal %s (aba %s, linha %d): %v", portal.Portal, sheetName, rIdx+opts.HeaderRow+1, err)
+				sheetReport.Warnings = append(sheetReport.Warnings, fmt.Sprintf("linha %d: %v", rIdx+opts.HeaderRow+1, err))
+				continue
+			}
+			if warning, ok := skippedCompetenciaWarning(saved); ok {
+				sheetReport.Warnings = append(sheetReport.Warnings, fmt.Sprintf("linha %d: %s", rIdx+opts.HeaderRow+1, warning))
+			}
+			sheetReport.Upserts++
+		}
+		if sheetReport.ProcessedRows == 0 {
+			sheetReport.Warnings = append(sheetReport.Warnings, "aba não possui linhas válidas para importação")
+		}
+		sheetReport.UniqueIDCount = len(ids)
+
+		report.Totals.ProcessedRows += sheetReport.ProcessedRows
+		report.Totals.Upserts += sheetReport.Upserts
+		report.Sheets = append(report.Sheets, sheetReport)
+	}
+
+	report.GeneratedAt = time.Now()
+	return report, nil
+}
+
+// readSheet lê todas as linhas da aba preservando o tipo numérico das células
+func readSheet(f *excelize.File, sheetName string) ([][]sheetCell, error) {
+	rows, err := f.GetRows(sheetName, excelize.Options{RawCellValue: true})
+	if err != nil {
+		return nil, err
+	}
+	result := make([][]sheetCell, len(rows))
+	for r, row := range rows {
+		cells := make([]sheetCell, len(row))
+		for c, value := range row {
+			cells[c] = sheetCell{Text: value}
+			if value == "" {
+				continue
+			}
+			axis, err := excelize.CoordinatesToCellName(c+1, r+1)
+			if err != nil {
+				return nil, err
+			}
+			cellType, err := f.GetCellType(sheetName, axis)
+			if err != nil {
+				return nil, err
+			}
+			if cellType == excelize.CellTypeNumber || cellType == excelize.CellTypeUnset {
+				if n, err := strconv.ParseFloat(value, 64); err == nil {
+					cells[c].Number = n
+					cells[c].Numeric = true
+				}
+			}
+		}
+		result[r] = cells
+	}
+	return result, nil
+}
+
+func rowIsBlank(row []sheetCell) bool {
+	for _, c := range row {
+		if !c.isBlank() {
+			return false
+		}
+	}
+	return true
+}
+
+// portalFromRow converte uma linha da planilha em model.Portal seguindo as regras do script Node.
+// Competências e datas que não puderam ser interpretadas são devolvidas em problems. O _id é
+// calculado depois que o nome do portal é resolvido no catálogo.
+func portalFromRow(row []sheetCell, columns map[string]int, sheetName, sheetDeliveryDate string, rowIndex int) (portal model.Portal, problems []string) {
+	cell := func(field string) sheetCell {
+		idx := columns[field]
+		if idx < 0 || idx >= len(row) {
+			return sheetCell{}
+		}
+		return row[idx]
+	}
+	text := func(field string) string {
+		return cell(field).Text
+	}
+	competencia := func(field string) model.Competencia {
+		c, err := parseCompetenciaCell(cell(field))
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field, err))
+		}
+		return c
+	}
+
+	mesAnoEnvio := text("mesAnoEnvio")
+	mesAnoReferencia := text("mesAnoReferencia")
+	portalName := text("portal")
+
+	// dataEntrega vem de "Mês/Ano de Envio"; na ausência, usa a data extraída do nome da aba
+	dataEntrega := mesAnoEnvio
+	if dataEntrega == "" {
+		dataEntrega = sheetDeliveryDate
+	}
+	referencia := firstNonEmpty(dataEntrega, sheetDeliveryDate, text("referencia"), mesAnoReferencia,
+		mesAnoEnvio, text("ultimaReferencia"), text("ultimoMesEnviado"))
+
+	envio := competencia("mesAnoEnvio")
+	competenciaDados := competencia("mesAnoReferencia")
+	entrega := model.Data{Ano: envio.Ano, Mes: envio.Mes}
+	if !envio.HasMonth() {
+		var err error
+		if entrega, err = model.ParseData(sheetDeliveryDate); err != nil {
+			problems = append(problems, fmt.Sprintf("dataEntrega (aba %q): %v", sheetName, err))
+		}
+	}
+
+	return model.Portal{
+		// O _id usa o texto das células, como o script Node: reimportar a mesma planilha atualiza
+		// as mesmas linhas, mesmo que o catálogo troque o nome pelo canônico
+		ID:                             computeHashedId(dataEntrega, portalName, mesAnoReferencia, sheetName, rowIndex),
+		Referencia:                     referencia,
+		DataEntrega:                    entrega,
+		Portal:                         portalName,
+		Esfera:                         text("esfera"),
+		MesAnoEnvio:                    envio,
+		MesAnoReferencia:               competenciaDados,
+		VolumeFonte:                    toInt(cell("volumeFonte")),
+		VolumetriaDados:                toInt(cell("volumetriaDados")),
+		VolumetriaServicos:             toInt(cell("volumetriaServicos")),
+		IndiceDados:                    toFloat(cell("indiceDados")),
+		IndiceServicos:                 toFloat(cell("indiceServicos")),
+		VolumeCpfsUnicosDados:          toInt(cell("volumeCpfsUnicosDados")),
+		VolumeCpfsUnicosServicos:       toInt(cell("volumeCpfsUnicosServicos")),
+		MediaMovelCpfsUnicos:           toInt(cell("mediaMovelCpfsUnicos")),
+		UltimoMesEnviado:               competencia("ultimoMesEnviado"),
+		UltimaReferencia:               text("ultimaReferencia"),
+		UltimaVolumetriaEnviada:        toInt(cell("ultimaVolumetriaEnviada")),
+		MediaMovelUltimos12Meses:       toInt(cell("mediaMovelUltimos12Meses")),
+		Media:                          toInt(cell("media")),
+		Minimo:                         toInt(cell("minimo")),
+		MesCompetenciaMinimo:           competencia("mesCompetenciaMinimo"),
+		Maximo:                         toInt(cell("maximo")),
+		MesCompetenciaMaximo:           competencia("mesCompetenciaMaximo"),
+		PercentualVolumetriaUltima:     toFloat(cell("percentualVolumetriaUltima")),
+		PercentualVolumetriaMediaMovel: toFloat(cell("percentualVolumetriaMediaMovel")),
+		PercentualVolumetriaMedia:      toFloat(cell("percentualVolumetriaMedia")),
+		PercentualVolumetriaMinimo:     toFloat(cell("percentualVolumetriaMinimo")),
+		PercentualVolumetriaMaximo:     toFloat(cell("percentualVolumetriaMaximo")),
+		PulouCompetencia:               toBool(cell("pulouCompetencia")),
+		DefasagemNosDados:              toBool(cell("defasagemNosDados")),
+		NovosDados:                     toBool(cell("novosDados")),
+		Status:                         strings.TrimSpace(text("status")),
+		ObservacaoTimeDados:            text("observacaoTimeDados"),
+		Enviar:                         toBool(cell("enviar")),
+	}, problems
+}
+
+// parseCompetenciaCell interpreta a competência da célula: texto ("08/2024", "SEM NOVOS DADOS"),
+// data completa ("01/08/2024") ou data nativa do Excel (número serial)
+func parseCompetenciaCell(c sheetCell) (model.Competencia, error) {
+	if c.isBlank() {
+		return model.Competencia{}, nil
+	}
+	if c.Numeric {
+		t, err := excelize.ExcelDateToTime(c.Number, false)
+		if err != nil {
+			return model.Competencia{}, fmt.Errorf("competência inválida: %q", c.Text)
+		}
+		return model.DataFromTime(t).Competencia(), nil
+	}
+	competencia, err := model.ParseCompetencia(c.Text)
+	if err == nil {
+		return competencia, nil
+	}
+	if data, errData := model.ParseData(c.Text); errData == nil {
+		return data.Competencia(), nil
+	}
+	return model.Competencia{}, err
+}
+
+// skippedCompetenciaWarning descreve as competências puladas detectadas ao gravar o portal
+func skippedCompetenciaWarning(p model.Portal) (string, bool) {
+	if len(p.CompetenciasFaltantes) == 0 {
+		return "", false
+	}
+	return fmt.Sprintf("%s pulou competência(s) %s", p.Portal, strings.Join(p.CompetenciasFaltantes, ", ")), true
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+    "bytes"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "github.com/xuri/excelize/v2"
+)
+
+func buildTestWorkbook(t *testing.T) *bytes.Buffer {
+    t.Helper()
+    f := excelize.NewFile()
+    defer f.Close()
+    sheet := "Entrega 10112025"
+    if err := f.SetSheetName("Sheet1", sheet); err != nil {
+        t.Fatalf("SetSheetName: %v", err)
+    }
+    rows := [][]interface{}{
+        {"RELATÓRIO BOTS"},
+        {"Portal", "ESFERA", "Mês/Ano de Envio", "Mes/Ano de referencia (Competencia do envio atual)", "Volume da fonte", "Índice agregação (Dados)", "Status", "Enviar?"},
+        {"transparencia_go", "ESTADUAL", "11/2025", "4/2025", "70.655", 95.637, " OK ", "Sim"},
+        {"transparencia_ba", "ESTADUAL", "", "5/2025", 1234.6, "95,5%", "ERRO", "não"},
+        {},
+    }
+    for i, row := range rows {
+        cell, _ := excelize.CoordinatesToCellName(1, i+1)
+        if err := f.SetSheetRow(sheet, cell, &row); err != nil {
+            t.Fatalf("SetSheetRow: %v", err)
+        }
+    }
+    buf, err := f.WriteToBuffer()
+    if err != nil {
+        t.Fatalf("WriteToBuffer: %v", err)
+    }
+    return buf
+}
+
+func TestImportWorkbook(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := NewPortalImportService(newTestPortalService(repo), newTestPortalCatalogService())
+
+    report, err := svc.ImportWorkbook("entrega.xlsx", buildTestWorkbook(t), model.ImportOptions{})
+    if err != nil {
+        t.Fatalf("ImportWorkbook falhou: %v", err)
+    }
+    if report.Totals.ProcessedRows != 2 || report.Totals.Upserts != 2 {
+        t.Fatalf("totais inesperados: %+v", report.Totals)
+    }
+    sheet := report.Sheets[0]
+    if sheet.DetectedMap["mesAnoReferencia"] == nil || *sheet.DetectedMap["esfera"] != "ESFERA" {
+        t.Fatalf("mapeamento de cabeçalhos inesperado: %+v", sheet.DetectedMap)
+    }
+    if sheet.DetectedMap["volumetriaDados"] != nil {
+        t.Fatalf("volumetriaDados não deveria ter sido detectado")
+    }
+
+    id := computeHashedId("11/2025", "transparencia_go", "4/2025", "", 0)
+    got, err := repo.GetPortalByID(id)
+    if err != nil {
+        t.Fatalf("portal importado não encontrado: %v", err)
+    }
+    if got.VolumeFonte != 70655 || got.IndiceDados != 95.637 || !got.Enviar {
+        t.Fatalf("valores convertidos incorretamente: %+v", got)
+    }
+    // Envio 11/2025 com competência 4/2025: 7 meses, acima dos 2 esperados para ESTADUAL
+    if got.DefasagemMeses == nil || *got.DefasagemMeses != 7 || !got.DefasagemNosDados || got.Status != model.StatusWarning {
+        t.Fatalf("valores convertidos incorretamente: %+v", got)
+    }
+    if got.Referencia != "11/2025" || got.DataEntrega.String() != "11/2025" {
+        t.Fatalf("referência/dataEntrega inesperadas: %s / %s", got.Referencia, got.DataEntrega)
+    }
+
+    // Sem Mês/Ano de Envio, a dataEntrega vem do nome da aba
+    id = computeHashedId("10/11/2025", "transparencia_ba", "5/2025", "", 0)
+    got, err = repo.GetPortalByID(id)
+    if err != nil {
+        t.Fatalf("portal sem mesAnoEnvio não encontrado: %v", err)
+    }
+    if got.VolumeFonte != 1235 || got.IndiceDados != 95.5 || got.Enviar {
+        t.Fatalf("valores convertidos incorretamente: %+v", got)
+    }
+}
+
+func TestImportWorkbook_DryRun(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := NewPortalImportService(newTestPortalService(repo), newTestPortalCatalogService())
+
+    report, err := svc.ImportWorkbook("entrega.xlsx", buildTestWorkbook(t), model.ImportOptions{DryRun: true})
+    if err != nil {
+        t.Fatalf("ImportWorkbook falhou: %v", err)
+    }
+    if report.Totals.ProcessedRows != 2 || report.Totals.Upserts != 0 {
+        t.Fatalf("dry-run não deveria gravar: %+v", report.Totals)
+    }
+    all, _ := repo.GetAllPortals()
+    if len(all) != 5 {
+        t.Fatalf("dry-run alterou o repositório: %d registros", len(all))
+    }
+}
+
+func TestParseHelpers(t *testing.T) {
+    if got := extractDeliveryDate("Entrega 10112025", "dd/MM/yy"); got != "10/11/25" {
+        t.Fatalf("extractDeliveryDate: %s", got)
+    }
+    if n, ok := parseBRNumber("1.234,56"); !ok || n != 1234.56 {
+        t.Fatalf("parseBRNumber: %v %v", n, ok)
+    }
+    if normalizeHeader("  Índice Agregação (Serviços) ") != "indice agregacao (servicos)" {
+        t.Fatalf("normalizeHeader não removeu acentos")
+    }
+    // Hash estável compatível com o script Node (dataEntrega|portal|mesAnoReferencia)
+    if id := computeHashedId("11/2025", "transparencia_go", "4/2025", "x", 1); id != "1df7af085d81cd10167b8f3920f58d13b7750fc3" {
+        t.Fatalf("computeHashedId diverge do script: %s", id)
+    }
+}
@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+)
+
+// ErrInvalidSearch indica uma busca sem termos
+var ErrInvalidSearch = errors.New("busca inválida")
+
+// Limites de resultados da busca
+const (
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+)
+
+// searchSnippetRadius é quantas runas de contexto o trecho mostra antes e depois dos termos
+const searchSnippetRadius = 40
+
+// searchSnippetFields são os campos da linha de portal cobertos pelo índice de texto
+var searchSnippetFields = []string{"portal", "observacaoTimeDados", "status", "esfera"}
+
+// PortalSearchService faz a busca textual nas linhas de portal e nos comentários
+type PortalSearchService interface {
+	// Search devolve as linhas que atendem à busca q, da mais relevante para a menos relevante,
+	// com os trechos destacados. Comentários encontrados somam relevância à linha comentada.
+	Search(q string, limit int) (model.PortalSearchResult, error)
+}
+
+type portalSearchService struct {
+	portalRepo  repository.PortalRepository
+	commentRepo repository.PortalCommentRepository
+}
+
+func NewPortalSearchService(portalRepo repository.PortalRepository, commentRepo repository.PortalCommentRepository) PortalSearchService {
+	return &portalSearchService{portalRepo: portalRepo, commentRepo: commentRepo}
+}
+
+func (s *portalSearchService) Search(q string, limit int) (model.PortalSearchResult, error) {
+	q = strings.TrimSpace(q)
+	query := model.ParseSearchQuery(q)
+	if query.IsEmpty() {
+		return model.PortalSearchResult{}, fmt.Errorf("%w: informe ao menos um termo", ErrInvalidSearch)
+	}
+	if limit <= 0 {
+		limit = DefaultSearchLimit
+	}
+	if limit > MaxSearchLimit {
+		limit = MaxSearchLimit
+	}
+
+	portalHits, err := s.portalRepo.SearchPortals(q, limit)
+	if err != nil {
+		return model.PortalSearchResult{}, err
+	}
+	commentHits, err := s.commentRepo.SearchComments(q, limit)
+	if err != nil {
+		return model.PortalSearchResult{}, err
+	}
+
+	hits := map[string]*model.PortalSearchHit{}
+	order := []string{}
+	for i := range portalHits {
+		hit := portalHits[i]
+		for _, field := range searchSnippetFields {
+			value, _ := hit.Portal.FieldValue(field)
+			text, _ := value.(string)
+			if snippet, ok := searchSnippet(query, text); ok {
+				hit.Trechos = append(hit.Trechos, model.SearchSnippet{Campo: field, Trecho: snippet})
+			}
+		}
+		hits[hit.Portal.ID] = &hit
+		order = append(order, hit.Portal.ID)
+	}
+	for _, c := range commentHits {
+		hit, ok := hits[c.Comment.PortalID]
+		if !ok {
+			portal, err := s.portalRepo.GetPortalByID(c.Comment.PortalID)
+			if err != nil {
+				// Comentário de uma linha removida: não há o que mostrar
+				continue
+			}
+			hit = &model.PortalSearchHit{Portal: portal}
+			hits[portal.ID] = hit
+			order = append(order, portal.ID)
+		}
+		hit.Score += c.Score
+		if snippet, ok := searchSnippet(query, c.Comment.Texto); ok {
+			hit.Trechos = append(hit.Trechos, model.SearchSnippet{Campo: "comentario", Trecho: snippet})
+		}
+	}
+
+	items := make([]model.PortalSearchHit, 0, len(order))
+	for _, id := range order {
+		hit := hits[id]
+		if hit.Trechos == nil {
+			hit.Trechos = []model.SearchSnippet{}
+		}
+		items = append(items, *hit)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].Portal.Portal < items[j].Portal.Portal
+	})
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return model.PortalSearchResult{Query: q, Total: len(items), Items: items}, nil
+}
+
+// searchSnippet recorta o texto em volta dos termos encontrados, com escape de HTML e os termos
+// entre <mark>; ok é falso quando nenhum termo aparece no texto
+func searchSnippet(query model.SearchQuery, text string) (string, bool) {
+	spans := query.Spans(text)
+	if len(spans) == 0 {
+		return "", false
+	}
+	runes := []rune(text)
+	start := spans[0][0] - searchSnippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := spans[len(spans)-1][1] + searchSnippetRadius
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	pos := start
+	for _, span := range spans {
+		b.WriteString(html.EscapeString(string(runes[pos:span[0]])))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[span[0]:span[1]])))
+		b.WriteString("</mark>")
+		pos = span[1]
+	}
+	b.WriteString(html.EscapeString(string(runes[pos:end])))
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String(), true
+}
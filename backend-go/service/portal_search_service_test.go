@@ -0,0 +1,66 @@
+package service
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+)
+
+func TestPortalSearch_RankingSnippetsAndComments(t *testing.T) {
+    portalRepo := repository.NewMockPortalRepository()
+    commentRepo := repository.NewMockPortalCommentRepository()
+    svc := NewPortalSearchService(portalRepo, commentRepo)
+
+    // Comentário na linha de transparencia_mg (id "4") e outro numa linha que não existe mais
+    if _, err := commentRepo.InsertComment(model.PortalComment{PortalID: "4", Portal: "transparencia_mg", Texto: "O robô quebrou em junho, <b>revisar</b> a coleta"}); err != nil {
+        t.Fatalf("InsertComment falhou: %v", err)
+    }
+    if _, err := commentRepo.InsertComment(model.PortalComment{PortalID: "removida", Texto: "robô parado em junho"}); err != nil {
+        t.Fatalf("InsertComment falhou: %v", err)
+    }
+
+    if _, err := svc.Search("   ", 0); !errors.Is(err, ErrInvalidSearch) {
+        t.Fatalf("esperava ErrInvalidSearch sem termos, obtive %v", err)
+    }
+    if _, err := svc.Search("-dados", 0); !errors.Is(err, ErrInvalidSearch) {
+        t.Fatalf("esperava ErrInvalidSearch só com exclusões, obtive %v", err)
+    }
+
+    // Todos casam pelo nome; a observação e o comentário desempatam
+    result, err := svc.Search("revisar transparencia", 0)
+    if err != nil {
+        t.Fatalf("Search falhou: %v", err)
+    }
+    if result.Total != 5 || result.Items[0].Portal.Portal != "transparencia_sp" || result.Items[1].Portal.Portal != "transparencia_mg" {
+        t.Fatalf("ordem inesperada: %+v", result.Items)
+    }
+    sp := result.Items[0]
+    if len(sp.Trechos) != 2 || sp.Trechos[1].Campo != "observacaoTimeDados" || sp.Trechos[1].Trecho != "<mark>Revisar</mark> dados" {
+        t.Fatalf("trechos inesperados: %+v", sp.Trechos)
+    }
+
+    // Sem acento na busca, com escape de HTML no trecho; o comentário órfão é ignorado
+    result, err = svc.Search("robo JUNHO", 0)
+    if err != nil {
+        t.Fatalf("Search falhou: %v", err)
+    }
+    if result.Total != 1 || result.Items[0].Portal.ID != "4" {
+        t.Fatalf("esperava só a linha comentada: %+v", result.Items)
+    }
+    trecho := result.Items[0].Trechos[0]
+    if trecho.Campo != "comentario" || !strings.Contains(trecho.Trecho, "O <mark>robô</mark> quebrou em <mark>junho</mark>, &lt;b&gt;revisar") {
+        t.Fatalf("trecho do comentário inesperado: %+v", trecho)
+    }
+
+    // Exclusão e limite
+    result, err = svc.Search("dados -atualizados", 0)
+    if err != nil || result.Total != 1 || result.Items[0].Portal.Portal != "transparencia_sp" {
+        t.Fatalf("exclusão não aplicada: %+v %v", result.Items, err)
+    }
+    result, err = svc.Search("transparencia", 2)
+    if err != nil || result.Total != 2 {
+        t.Fatalf("limite não aplicado: %+v %v", result, err)
+    }
+}
@@ -2,49 +2,104 @@
 package service
 
 import (
-    "log"
+    "errors"
+    "fmt"
+    "reflect"
     "solid_react_golang_mongo_project/backend-go/model"
     "solid_react_golang_mongo_project/backend-go/repository"
     "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+    "go.mongodb.org/mongo-driver/mongo"
 )
 
+// ErrInvalidBulkUpdate indica uma atualização em massa sem seleção de linhas ou sem campos
+var ErrInvalidBulkUpdate = errors.New("atualização em massa inválida")
+
+// ErrInvalidPortalQuery indica ordenação ou paginação inválida na listagem
+var ErrInvalidPortalQuery = errors.New("consulta de portais inválida")
+
+// ErrInvalidPortal indica competências ou datas inválidas na linha a gravar
+var ErrInvalidPortal = errors.New("portal inválido")
+
+// ErrVersionConflict indica que o portal mudou desde a versão que o cliente editou
+var ErrVersionConflict = errors.New("o portal foi alterado por outra edição")
+
 type PortalService interface {
-    InitializeData() error
     GetAllPortals() ([]model.Portal, error)
+    FindPortals(query model.PortalQuery) (model.PortalPage, error)
+    // GetPortalByID retorna ErrPortalNotFound quando a linha não existe
     GetPortalByID(id string) (model.Portal, error)
+    SavePortal(portal model.Portal) (model.Portal, error)
     UpdatePortalFields(id string, observacaoTimeDados string, enviar bool) error
-    UpdatePortalFieldsMap(id string, fields bson.M) error
+    // UpdatePortalFieldsMap aplica a edição e registra as alterações campo a campo em nome de userID.
+    // Com expectedVersion, a edição só é gravada se o portal ainda estiver nessa versão
+    // (ErrVersionConflict caso contrário).
+    UpdatePortalFieldsMap(id string, fields bson.M, userID primitive.ObjectID, expectedVersion *int) error
+    // CheckEditable faz as verificações de UpdatePortalFieldsMap sem gravar: ErrPortalNotFound,
+    // ErrDeliveryLocked ou ErrVersionConflict
+    CheckEditable(id string, expectedVersion *int) error
+    // BulkUpdate aplica os campos às linhas selecionadas (IDs e/ou filtros) em uma única operação.
+    // Com dryRun, apenas conta as linhas afetadas. Linhas de entregas fechadas impedem a operação.
+    BulkUpdate(query model.PortalQuery, fields bson.M, userID primitive.ObjectID, dryRun bool) (model.PortalBulkUpdateResult, error)
 }
 
-type portalService struct {
-    repo repository.PortalRepository
+// PortalServiceDeps agrupa os serviços usados no pipeline de gravação de um portal
+type PortalServiceDeps struct {
+    Metrics    MetricsService
+    History    PortalHistoryService
+    Status     StatusClassifier
+    Rules      RuleService
+    Lag        LagService
+    Deliveries DeliveryService
+    Changes    PortalChangeService
+    Catalog    PortalCatalogService
 }
 
-func NewPortalService(repo repository.PortalRepository) PortalService {
-    return &portalService{repo: repo}
+type portalService struct {
+    repo       repository.PortalRepository
+    metrics    MetricsService
+    history    PortalHistoryService
+    status     StatusClassifier
+    rules      RuleService
+    lag        LagService
+    deliveries DeliveryService
+    changes    PortalChangeService
+    catalog    PortalCatalogService
 }
 
-func (s *portalService) InitializeData() error {
-    data := []model.Portal{
-        {Portal: "transparencia_al", Esfera: "ESTADUAL", MesAnoEnvio: "11/2024"},
-        // Populate other fields based on extracted data
+func NewPortalService(repo repository.PortalRepository, deps PortalServiceDeps) PortalService {
+    return &portalService{
+        repo:       repo,
+        metrics:    deps.Metrics,
+        history:    deps.History,
+        status:     deps.Status,
+        rules:      deps.Rules,
+        lag:        deps.Lag,
+        deliveries: deps.Deliveries,
+        changes:    deps.Changes,
+        catalog:    deps.Catalog,
     }
-    for _, portal := range data {
-        err := s.repo.InsertPortal(portal)
-        if err != nil {
-            log.Println("Error inserting initial data:", err)
-            return err
-        }
-    }
-    return nil
 }
 
 func (s *portalService) GetAllPortals() ([]model.Portal, error) {
     return s.repo.GetAllPortals()
 }
 
+// FindPortals valida a consulta (ordenação e paginação) antes de delegar ao repositório
+func (s *portalService) FindPortals(query model.PortalQuery) (model.PortalPage, error) {
+    if query.Limit < 0 || query.Offset < 0 {
+        return model.PortalPage{}, fmt.Errorf("%w: limit e offset não podem ser negativos", ErrInvalidPortalQuery)
+    }
+    for _, sf := range query.Sort {
+        if !model.PortalSortableFields[sf.Field] {
+            return model.PortalPage{}, fmt.Errorf("%w: campo de ordenação inválido: %s", ErrInvalidPortalQuery, sf.Field)
+        }
+    }
+    return s.repo.FindPortals(query)
+}
+
 func (s *portalService) GetPortalByID(id string) (model.Portal, error) {
-    return s.repo.GetPortalByID(id)
+    return s.getPortal(id)
 }
 
 // UpdatePortalFields atualiza campos editáveis do Portal
@@ -53,10 +108,292 @@ func (s *portalService) UpdatePortalFields(id string, observacaoTimeDados string
         "observacaoTimeDados": observacaoTimeDados,
         "enviar":               enviar,
     }
+    if err := s.checkWritable(id); err != nil {
+        return err
+    }
     return s.repo.UpdatePortalFields(id, fields)
 }
 
-// UpdatePortalFieldsMap permite atualizar um conjunto de campos editáveis
-func (s *portalService) UpdatePortalFieldsMap(id string, fields bson.M) error {
-    return s.repo.UpdatePortalFields(id, fields)
+// UpdatePortalFieldsMap permite atualizar um conjunto de campos editáveis. Um status informado
+// passa a ser um override manual; "statusManual": false devolve o status à classificação automática.
+func (s *portalService) UpdatePortalFieldsMap(id string, fields bson.M, userID primitive.ObjectID, expectedVersion *int) error {
+    if _, ok := fields["status"]; ok {
+        if _, explicit := fields["statusManual"]; !explicit {
+            fields["statusManual"] = true
+        }
+    }
+    before, err := s.editable(id, expectedVersion)
+    if err != nil {
+        return err
+    }
+    portal, err := applyFields(before, fields)
+    if err != nil {
+        return err
+    }
+    // A gravação é condicionada à versão lida: uma edição concorrente entre a leitura e a
+    // gravação também resulta em ErrVersionConflict
+    version := before.Version
+    after, err := s.save(portal, false, &version)
+    if errors.Is(err, repository.ErrVersionConflict) {
+        return fmt.Errorf("%w (portal alterado durante a edição)", ErrVersionConflict)
+    }
+    if err != nil {
+        return err
+    }
+    return s.changes.Record(before, after, userID)
+}
+
+func (s *portalService) BulkUpdate(query model.PortalQuery, fields bson.M, userID primitive.ObjectID, dryRun bool) (model.PortalBulkUpdateResult, error) {
+    if !query.HasFilter() {
+        return model.PortalBulkUpdateResult{}, fmt.Errorf("%w: informe ids ou ao menos um filtro", ErrInvalidBulkUpdate)
+    }
+    if len(fields) == 0 {
+        return model.PortalBulkUpdateResult{}, fmt.Errorf("%w: nenhum campo para atualizar", ErrInvalidBulkUpdate)
+    }
+    if _, ok := fields["status"]; ok {
+        if _, explicit := fields["statusManual"]; !explicit {
+            fields["statusManual"] = true
+        }
+    }
+    query.Sort, query.Limit, query.Offset = nil, 0, 0
+    // Na listagem filter.portal é busca parcial; aqui "al" alteraria todo portal com "al" no nome
+    query.PortalExato = true
+
+    page, err := s.repo.FindPortals(query)
+    if err != nil {
+        return model.PortalBulkUpdateResult{}, err
+    }
+    if len(page.Items) == 0 && query.Portal != "" {
+        partial := query
+        partial.PortalExato = false
+        matches, err := s.repo.FindPortals(partial)
+        if err != nil {
+            return model.PortalBulkUpdateResult{}, err
+        }
+        if matches.Total > 0 {
+            return model.PortalBulkUpdateResult{}, fmt.Errorf("%w: filter.portal deve ser o nome completo do portal (%q casa apenas parcialmente com %d linhas)", ErrInvalidBulkUpdate, query.Portal, matches.Total)
+        }
+    }
+    checked := map[string]bool{}
+    for _, p := range page.Items {
+        if checked[p.Referencia] {
+            continue
+        }
+        checked[p.Referencia] = true
+        if err := s.deliveries.CheckWritable(p.Referencia); err != nil {
+            return model.PortalBulkUpdateResult{}, err
+        }
+    }
+    if dryRun {
+        return model.PortalBulkUpdateResult{DryRun: true, Affected: int64(len(page.Items))}, nil
+    }
+
+    ids := make([]string, 0, len(page.Items))
+    before := make(map[string]model.Portal, len(page.Items))
+    for _, p := range page.Items {
+        ids = append(ids, p.ID)
+        before[p.ID] = p
+    }
+    if len(ids) == 0 {
+        return model.PortalBulkUpdateResult{}, nil
+    }
+    // A atualização usa os _id lidos acima, e não o filtro: linhas que passaram a casar com ele
+    // depois da leitura não são alteradas sem registro no histórico
+    affected, err := s.repo.UpdateManyPortalFields(model.PortalQuery{IDs: ids}, fields)
+    if err != nil {
+        return model.PortalBulkUpdateResult{}, err
+    }
+
+    updated, err := s.repo.FindPortals(model.PortalQuery{IDs: ids})
+    if err != nil {
+        return model.PortalBulkUpdateResult{}, err
+    }
+    for _, after := range updated.Items {
+        after, err := s.refreshAfterBulkUpdate(after)
+        if err != nil {
+            return model.PortalBulkUpdateResult{}, err
+        }
+        // Histórico de alterações: compara cada linha antes/depois da atualização
+        if err := s.changes.Record(before[after.ID], after, userID); err != nil {
+            return model.PortalBulkUpdateResult{}, err
+        }
+    }
+    return model.PortalBulkUpdateResult{Affected: affected}, nil
+}
+
+// refreshAfterBulkUpdate reavalia status e regras da linha gravada pelo UpdateMany, como numa
+// edição individual, e atualiza a cópia da linha em entregas. Se a linha mudou de novo desde a
+// leitura, a gravação concorrente já fez a reavaliação.
+func (s *portalService) refreshAfterBulkUpdate(portal model.Portal) (model.Portal, error) {
+    refreshed, err := s.rules.Evaluate(s.status.Classify(s.lag.Apply(portal)))
+    if err != nil {
+        return portal, err
+    }
+    if !reflect.DeepEqual(refreshed, portal) {
+        err := s.repo.UpsertPortalIfVersion(refreshed, portal.Version)
+        if errors.Is(err, repository.ErrVersionConflict) {
+            return portal, nil
+        }
+        if err != nil {
+            return portal, err
+        }
+        refreshed.Version = portal.Version + 1
+    }
+    return refreshed, s.history.RecordDelivery(refreshed)
+}
+
+// applyFields aplica os campos (nomes BSON) sobre o portal
+func applyFields(portal model.Portal, fields bson.M) (model.Portal, error) {
+    doc, err := portalDocument(portal)
+    if err != nil {
+        return portal, err
+    }
+    for k, v := range fields {
+        doc[k] = v
+    }
+    raw, err := bson.Marshal(doc)
+    if err != nil {
+        return portal, err
+    }
+    var updated model.Portal
+    err = bson.Unmarshal(raw, &updated)
+    return updated, err
+}
+
+// SavePortal grava (upsert) um portal vindo de importação: recalcula as métricas derivadas a partir
+// do histórico do portal, aponta divergências com os valores importados e atualiza as competências
+// posteriores do mesmo portal, cujas médias dependem deste registro. Um status definido
+// manualmente no registro existente é preservado. Linhas de entregas fechadas (CLOSED/SENT)
+// são recusadas com ErrDeliveryLocked. O nome do portal é resolvido no catálogo (aliases viram
+// o nome canônico; nomes desconhecidos são marcados ou recusados com ErrUnknownPortal).
+func (s *portalService) SavePortal(portal model.Portal) (model.Portal, error) {
+    portal, err := s.catalog.Resolve(portal)
+    if err != nil {
+        return portal, err
+    }
+    if err := s.deliveries.CheckWritable(portal.Referencia); err != nil {
+        return portal, err
+    }
+    if existing, err := s.repo.GetPortalByID(portal.ID); err == nil && existing.StatusManual {
+        portal.Status = existing.Status
+        portal.StatusManual = true
+    }
+    return s.save(portal, true, nil)
+}
+
+// save valida, recalcula e grava o portal. Com expectedVersion, a gravação só ocorre se o portal
+// ainda estiver nessa versão (repository.ErrVersionConflict caso contrário).
+func (s *portalService) save(portal model.Portal, flagDivergences bool, expectedVersion *int) (model.Portal, error) {
+    if err := portal.Validate(); err != nil {
+        return portal, fmt.Errorf("%w: %v", ErrInvalidPortal, err)
+    }
+    history, err := s.repo.GetPortalHistory(portal.Portal)
+    if err != nil {
+        return portal, err
+    }
+    history = replaceInHistory(history, portal)
+
+    if !flagDivergences {
+        // Edições preservam as divergências apontadas na importação
+        divergencias := portal.Divergencias
+        portal = s.metrics.Compute(portal, history, false)
+        portal.Divergencias = divergencias
+    } else {
+        portal = s.metrics.Compute(portal, history, true)
+    }
+    portal, err = s.rules.Evaluate(s.status.Classify(s.lag.Apply(portal)))
+    if err != nil {
+        return portal, err
+    }
+    if expectedVersion != nil {
+        if err := s.repo.UpsertPortalIfVersion(portal, *expectedVersion); err != nil {
+            return portal, err
+        }
+        portal.Version = *expectedVersion + 1
+    } else if err := s.repo.UpsertPortal(portal); err != nil {
+        return portal, err
+    }
+    if err := s.history.RecordDelivery(portal); err != nil {
+        return portal, err
+    }
+    if err := s.deliveries.EnsureDelivery(portal.Referencia, portal.DataEntrega); err != nil {
+        return portal, err
+    }
+    history = replaceInHistory(history, portal)
+
+    current := portal.MesAnoReferencia
+    if !current.HasMonth() {
+        return portal, nil
+    }
+    for _, h := range history {
+        c := h.MesAnoReferencia
+        if !c.HasMonth() || !current.Before(c) {
+            continue
+        }
+        // Competências de entregas fechadas não são recalculadas
+        if s.deliveries.CheckWritable(h.Referencia) != nil {
+            continue
+        }
+        recalculated, err := s.rules.Evaluate(s.status.Classify(s.lag.Apply(s.metrics.Compute(h, history, false))))
+        if err != nil {
+            return portal, err
+        }
+        recalculated.Divergencias = h.Divergencias
+        if reflect.DeepEqual(recalculated, h) {
+            continue
+        }
+        if err := s.repo.UpsertPortal(recalculated); err != nil {
+            return portal, err
+        }
+    }
+    return portal, nil
+}
+
+func (s *portalService) CheckEditable(id string, expectedVersion *int) error {
+    _, err := s.editable(id, expectedVersion)
+    return err
+}
+
+// editable carrega a linha a editar, recusando entregas fechadas e versões desatualizadas
+func (s *portalService) editable(id string, expectedVersion *int) (model.Portal, error) {
+    portal, err := s.getPortal(id)
+    if err != nil {
+        return portal, err
+    }
+    if err := s.deliveries.CheckWritable(portal.Referencia); err != nil {
+        return portal, err
+    }
+    if expectedVersion != nil && *expectedVersion != portal.Version {
+        return portal, fmt.Errorf("%w (versão atual %d, esperada %d)", ErrVersionConflict, portal.Version, *expectedVersion)
+    }
+    return portal, nil
+}
+
+// checkWritable recusa edições de linhas cuja entrega está fechada
+func (s *portalService) checkWritable(id string) error {
+    portal, err := s.getPortal(id)
+    if err != nil {
+        return err
+    }
+    return s.deliveries.CheckWritable(portal.Referencia)
+}
+
+// getPortal carrega a linha a editar; a ausência vira ErrPortalNotFound
+func (s *portalService) getPortal(id string) (model.Portal, error) {
+    portal, err := s.repo.GetPortalByID(id)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return portal, fmt.Errorf("%w: %s", ErrPortalNotFound, id)
+    }
+    return portal, err
+}
+
+// replaceInHistory substitui (ou acrescenta) o portal na lista pelo _id
+func replaceInHistory(history []model.Portal, portal model.Portal) []model.Portal {
+    for i := range history {
+        if history[i].ID == portal.ID {
+            history[i] = portal
+            return history
+        }
+    }
+    return append(history, portal)
 }
@@ -0,0 +1,391 @@
+package service
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestLagService(repo repository.PortalRepository) LagService {
+    return NewLagService(repo, map[string]int{"ESTADUAL": 2, "MUNICIPAL": 3}, 3)
+}
+
+// newTestPortalServiceDeps monta as dependências do PortalService sobre repositórios mock
+func newTestPortalServiceDeps(repo repository.PortalRepository) PortalServiceDeps {
+    return PortalServiceDeps{
+        Metrics:    NewMetricsService(0.01),
+        History:    NewPortalHistoryService(repository.NewMockPortalHistoryRepository(), repo),
+        Status:     NewStatusClassifier(DefaultStatusThresholds()),
+        Rules:      NewRuleService(repository.NewMockRuleRepository()),
+        Lag:        newTestLagService(repo),
+        Deliveries: NewDeliveryService(repository.NewMockDeliveryRepository(), repo),
+        Changes:    NewPortalChangeService(repository.NewMockPortalChangeRepository()),
+        Catalog:    NewPortalCatalogService(repository.NewMockPortalCatalogRepository(), false),
+    }
+}
+
+func mustCompetencia(s string) model.Competencia {
+    c, err := model.ParseCompetencia(s)
+    if err != nil {
+        panic(err)
+    }
+    return c
+}
+
+func mustData(s string) model.Data {
+    d, err := model.ParseData(s)
+    if err != nil {
+        panic(err)
+    }
+    return d
+}
+
+func newTestPortalService(repo repository.PortalRepository) PortalService {
+    return NewPortalService(repo, newTestPortalServiceDeps(repo))
+}
+
+func TestFindPortals_FilterSortPaginate(t *testing.T) {
+    svc := newTestPortalService(repository.NewMockPortalRepository())
+
+    page, err := svc.FindPortals(model.PortalQuery{
+        Esfera: "ESTADUAL",
+        Sort:   []model.SortField{{Field: "volumetriaServicos", Desc: true}},
+        Limit:  2,
+    })
+    if err != nil {
+        t.Fatalf("FindPortals falhou: %v", err)
+    }
+    if page.Total != 3 {
+        t.Fatalf("esperava total=3, obtive %d", page.Total)
+    }
+    if len(page.Items) != 2 {
+        t.Fatalf("esperava 2 itens na página, obtive %d", len(page.Items))
+    }
+    if page.Items[0].Portal != "transparencia_pr" || page.Items[1].Portal != "transparencia_rj" {
+        t.Fatalf("ordenação inesperada: %s, %s", page.Items[0].Portal, page.Items[1].Portal)
+    }
+
+    page, _ = svc.FindPortals(model.PortalQuery{Esfera: "ESTADUAL", Offset: 2, Limit: 2})
+    if len(page.Items) != 1 {
+        t.Fatalf("esperava 1 item na última página, obtive %d", len(page.Items))
+    }
+}
+
+func TestFindPortals_PortalPartialAndEnviar(t *testing.T) {
+    svc := newTestPortalService(repository.NewMockPortalRepository())
+
+    enviar := true
+    page, err := svc.FindPortals(model.PortalQuery{Portal: "_SP", Enviar: &enviar})
+    if err != nil {
+        t.Fatalf("FindPortals falhou: %v", err)
+    }
+    if page.Total != 1 || page.Items[0].Portal != "transparencia_sp" {
+        t.Fatalf("esperava apenas transparencia_sp, obtive %+v", page.Items)
+    }
+}
+
+func TestFindPortals_InvalidSortField(t *testing.T) {
+    svc := newTestPortalService(repository.NewMockPortalRepository())
+
+    if _, err := svc.FindPortals(model.PortalQuery{Sort: []model.SortField{{Field: "senha"}}}); !errors.Is(err, ErrInvalidPortalQuery) {
+        t.Fatalf("esperava ErrInvalidPortalQuery para campo de ordenação inválido, obtive %v", err)
+    }
+    if _, err := svc.FindPortals(model.PortalQuery{Offset: -1}); !errors.Is(err, ErrInvalidPortalQuery) {
+        t.Fatalf("esperava ErrInvalidPortalQuery para offset negativo, obtive %v", err)
+    }
+}
+
+func TestFindPortals_PagesOverRepeatedSortKeys(t *testing.T) {
+    svc := newTestPortalService(repository.NewMockPortalRepository())
+
+    // esfera se repete: o _id desempata e as páginas cobrem cada linha uma única vez
+    seen := []string{}
+    for offset := 0; offset < 5; offset += 2 {
+        page, err := svc.FindPortals(model.PortalQuery{Sort: []model.SortField{{Field: "esfera", Desc: true}}, Limit: 2, Offset: offset})
+        if err != nil {
+            t.Fatalf("FindPortals falhou: %v", err)
+        }
+        for _, p := range page.Items {
+            seen = append(seen, p.ID)
+        }
+    }
+    want := []string{"2", "4", "1", "3", "5"}
+    if strings.Join(seen, ",") != strings.Join(want, ",") {
+        t.Fatalf("ordem inesperada: %v", seen)
+    }
+}
+
+func TestFindPortals_UnsortedPagesFollowID(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    // Inserida por último, mas com o menor _id: sem ordenação, vem primeiro, como no MongoDB
+    repo.InsertPortal(model.Portal{ID: "0", Portal: "transparencia_ce"})
+    svc := newTestPortalService(repo)
+
+    page, err := svc.FindPortals(model.PortalQuery{Limit: 3})
+    if err != nil {
+        t.Fatalf("FindPortals falhou: %v", err)
+    }
+    seen := []string{}
+    for _, p := range page.Items {
+        seen = append(seen, p.ID)
+    }
+    if strings.Join(seen, ",") != "0,1,2" {
+        t.Fatalf("ordem inesperada sem ordenação: %v", seen)
+    }
+}
+
+func TestFindPortals_CompetenciaAndDataRanges(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    repo.InsertPortal(model.Portal{ID: "dez", Portal: "transparencia_ce", MesAnoReferencia: mustCompetencia("12/2023"), DataEntrega: mustData("28/02/2024")})
+    repo.InsertPortal(model.Portal{ID: "jan", Portal: "transparencia_pe", MesAnoReferencia: mustCompetencia("01/2024"), DataEntrega: mustData("01/03/2024")})
+    repo.InsertPortal(model.Portal{ID: "snd", Portal: "transparencia_pi", MesAnoReferencia: model.CompetenciaSemNovosDados})
+    svc := newTestPortalService(repo)
+
+    // "12/2023" vem antes de "01/2024" (ordem cronológica, não textual)
+    page, err := svc.FindPortals(model.PortalQuery{
+        MesAnoReferenciaAte: mustCompetencia("01/2024"),
+        Sort:                []model.SortField{{Field: "mesAnoReferencia"}},
+    })
+    if err != nil {
+        t.Fatalf("FindPortals falhou: %v", err)
+    }
+    if page.Total != 2 || page.Items[0].ID != "dez" || page.Items[1].ID != "jan" {
+        t.Fatalf("intervalo de competência inesperado: %+v", page.Items)
+    }
+
+    // O marcador "SEM NOVOS DADOS" fica fora de qualquer intervalo
+    page, _ = svc.FindPortals(model.PortalQuery{MesAnoReferenciaDe: mustCompetencia("01/2024")})
+    for _, p := range page.Items {
+        if p.ID == "snd" || p.ID == "dez" {
+            t.Fatalf("linha fora do intervalo retornada: %s", p.ID)
+        }
+    }
+
+    // O limite superior inclui o dia inteiro
+    page, _ = svc.FindPortals(model.PortalQuery{DataEntregaDe: mustData("28/02/2024"), DataEntregaAte: mustData("28/02/2024")})
+    if page.Total != 1 || page.Items[0].ID != "dez" {
+        t.Fatalf("intervalo de data inesperado: %+v", page.Items)
+    }
+    page, _ = svc.FindPortals(model.PortalQuery{DataEntregaAte: mustData("03/2024")})
+    if page.Total != 2 {
+        t.Fatalf("esperava 2 entregas até 03/2024, obtive %d", page.Total)
+    }
+}
+
+func TestPortalBSON_InvalidDatesDecodeLeniently(t *testing.T) {
+    // Linha gravada depois da normalização (ex.: pelo script Node) com valores que não são datas
+    raw, err := bson.Marshal(bson.M{"_id": "x", "portal": "transparencia_ce", "mesAnoReferencia": "outubro", "dataEntrega": 45962, "mesAnoEnvio": "2025-11"})
+    if err != nil {
+        t.Fatalf("bson.Marshal falhou: %v", err)
+    }
+    var p model.Portal
+    if err := bson.Unmarshal(raw, &p); err != nil {
+        t.Fatalf("um valor inválido não deveria falhar a leitura da linha: %v", err)
+    }
+    if !p.MesAnoReferencia.IsZero() || p.MesAnoReferencia.Invalido() != "outubro" || !p.DataEntrega.IsZero() || p.DataEntrega.Invalido() != "45962" {
+        t.Fatalf("valores inválidos deveriam ficar vazios, com o original guardado: %+v", p)
+    }
+    if p.MesAnoEnvio != mustCompetencia("11/2025") || p.MesAnoEnvio.Invalido() != "" {
+        t.Fatalf("valor válido lido errado: %+v", p.MesAnoEnvio)
+    }
+
+    // Regravar a linha preserva os valores originais para a normalização (dataEntrega, com
+    // omitempty, fica fora do $set e o valor gravado não é tocado)
+    again, err := bson.Marshal(p)
+    if err != nil {
+        t.Fatalf("bson.Marshal falhou: %v", err)
+    }
+    var doc bson.M
+    if err := bson.Unmarshal(again, &doc); err != nil {
+        t.Fatalf("bson.Unmarshal falhou: %v", err)
+    }
+    if _, written := doc["dataEntrega"]; doc["mesAnoReferencia"] != "outubro" || written {
+        t.Fatalf("valores originais não preservados: %v", doc)
+    }
+}
+
+func TestSavePortal_RejectsEnvioBeforeReferencia(t *testing.T) {
+    svc := newTestPortalService(repository.NewMockPortalRepository())
+
+    _, err := svc.SavePortal(model.Portal{ID: "x", Portal: "transparencia_ce", MesAnoEnvio: mustCompetencia("07/2024"), MesAnoReferencia: mustCompetencia("08/2024")})
+    if !errors.Is(err, ErrInvalidPortal) {
+        t.Fatalf("esperava ErrInvalidPortal, obtive %v", err)
+    }
+}
+
+func TestLagReport_RanksPortalsByDelay(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    lag := newTestLagService(repo)
+
+    // Entrega anterior de transparencia_al não deve ser considerada
+    repo.InsertPortal(model.Portal{ID: "old", Portal: "transparencia_al", Esfera: "ESTADUAL", MesAnoEnvio: mustCompetencia("01/2024"), MesAnoReferencia: mustCompetencia("01/2023")})
+    repo.InsertPortal(model.Portal{ID: "nodate", Portal: "transparencia_xx", Esfera: "MUNICIPAL"})
+    repo.InsertPortal(model.Portal{ID: "fromSheet", Portal: "transparencia_ce", Esfera: "ESTADUAL", DataEntrega: mustData("10/11/2024"), MesAnoReferencia: mustCompetencia("05/2024")})
+
+    report, err := lag.Report("", "")
+    if err != nil {
+        t.Fatalf("Report falhou: %v", err)
+    }
+    if len(report.Items) != 6 || len(report.SemDatas) != 1 || report.SemDatas[0] != "transparencia_xx" {
+        t.Fatalf("relatório inesperado: %+v", report)
+    }
+    first := report.Items[0]
+    // DataEntrega 10/11/2024 vs competência 05/2024: 6 meses, 4 acima do esperado
+    if first.Portal != "transparencia_ce" || first.DefasagemMeses != 6 || first.Atraso != 4 || !first.DefasagemNosDados {
+        t.Fatalf("portal mais atrasado inesperado: %+v", first)
+    }
+    for _, item := range report.Items {
+        if item.Portal == "transparencia_al" && item.PortalID != "1" {
+            t.Fatalf("deveria usar a entrega mais recente de transparencia_al: %+v", item)
+        }
+    }
+
+    municipal := lag.Apply(model.Portal{Esfera: "MUNICIPAL", MesAnoEnvio: mustCompetencia("11/2024"), MesAnoReferencia: mustCompetencia("08/2024"), DefasagemNosDados: true})
+    if municipal.DefasagemMeses == nil || *municipal.DefasagemMeses != 3 || municipal.DefasagemNosDados {
+        t.Fatalf("3 meses está dentro do esperado para MUNICIPAL: %+v", municipal)
+    }
+}
+
+func TestUpdatePortalFieldsMap_RejectsStaleVersion(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := newTestPortalService(repo)
+    editor := primitive.NewObjectID()
+
+    read, _ := svc.GetPortalByID("1")
+    stale := read.Version
+    if err := svc.UpdatePortalFieldsMap("1", bson.M{"observacaoTimeDados": "primeira"}, editor, &stale); err != nil {
+        t.Fatalf("edição com a versão lida falhou: %v", err)
+    }
+    current, _ := svc.GetPortalByID("1")
+    if current.Version != stale+1 {
+        t.Fatalf("esperava versão %d, obtive %d", stale+1, current.Version)
+    }
+
+    // Segunda edição a partir da mesma leitura: conflito, sem sobrescrever
+    if err := svc.UpdatePortalFieldsMap("1", bson.M{"observacaoTimeDados": "segunda"}, editor, &stale); !errors.Is(err, ErrVersionConflict) {
+        t.Fatalf("esperava ErrVersionConflict, obtive %v", err)
+    }
+    if got, _ := svc.GetPortalByID("1"); got.ObservacaoTimeDados != "primeira" || got.Version != current.Version {
+        t.Fatalf("portal sobrescrito após conflito: %+v", got)
+    }
+
+    // O repositório aplica a mesma regra à gravação condicional
+    if err := repo.UpsertPortalIfVersion(current, stale); !errors.Is(err, repository.ErrVersionConflict) {
+        t.Fatalf("esperava repository.ErrVersionConflict, obtive %v", err)
+    }
+}
+
+func TestCheckEditable_MatchesUpdateChecks(t *testing.T) {
+    svc := newTestPortalService(repository.NewMockPortalRepository())
+
+    current, _ := svc.GetPortalByID("1")
+    if err := svc.CheckEditable("1", &current.Version); err != nil {
+        t.Fatalf("CheckEditable com a versão atual falhou: %v", err)
+    }
+    stale := current.Version - 1
+    if err := svc.CheckEditable("1", &stale); !errors.Is(err, ErrVersionConflict) {
+        t.Fatalf("esperava ErrVersionConflict, obtive %v", err)
+    }
+    if err := svc.CheckEditable("inexistente", nil); !errors.Is(err, ErrPortalNotFound) {
+        t.Fatalf("esperava ErrPortalNotFound, obtive %v", err)
+    }
+}
+
+func TestUpdatePortalFieldsMap_UnknownID(t *testing.T) {
+    svc := newTestPortalService(repository.NewMockPortalRepository())
+
+    if err := svc.UpdatePortalFieldsMap("inexistente", bson.M{"observacaoTimeDados": "x"}, primitive.NewObjectID(), nil); !errors.Is(err, ErrPortalNotFound) {
+        t.Fatalf("esperava ErrPortalNotFound, obtive %v", err)
+    }
+    if _, err := svc.GetPortalByID("inexistente"); !errors.Is(err, ErrPortalNotFound) {
+        t.Fatalf("esperava ErrPortalNotFound na leitura, obtive %v", err)
+    }
+}
+
+func TestBulkUpdate_DryRunAndUpdateMany(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    deps := newTestPortalServiceDeps(repo)
+    svc := NewPortalService(repo, deps)
+    editor := primitive.NewObjectID()
+    query := model.PortalQuery{Referencia: "10-11-2024", Esfera: "ESTADUAL"}
+    fields := bson.M{"enviar": false, "status": model.StatusWarning}
+
+    if _, err := svc.BulkUpdate(model.PortalQuery{}, fields, editor, false); !errors.Is(err, ErrInvalidBulkUpdate) {
+        t.Fatalf("esperava ErrInvalidBulkUpdate sem seleção, obtive %v", err)
+    }
+
+    dry, err := svc.BulkUpdate(query, fields, editor, true)
+    if err != nil || !dry.DryRun || dry.Affected != 3 {
+        t.Fatalf("dry-run inesperado: %+v (%v)", dry, err)
+    }
+    if page, _ := repo.FindPortals(model.PortalQuery{Referencia: "10-11-2024", Esfera: "ESTADUAL", Status: model.StatusWarning}); page.Total != 0 {
+        t.Fatalf("dry-run alterou %d linhas", page.Total)
+    }
+
+    result, err := svc.BulkUpdate(query, fields, editor, false)
+    if err != nil || result.Affected != 3 {
+        t.Fatalf("atualização inesperada: %+v (%v)", result, err)
+    }
+    page, _ := repo.FindPortals(query)
+    for _, p := range page.Items {
+        if p.Enviar || p.Status != model.StatusWarning || !p.StatusManual || p.Version == 0 {
+            t.Fatalf("linha não atualizada: %+v", p)
+        }
+    }
+    changes, _ := deps.Changes.FindChanges(model.PortalChangeQuery{UserID: editor})
+    if len(changes) != 3 {
+        t.Fatalf("esperava 3 registros de alteração, obtive %d", len(changes))
+    }
+    // A cópia da linha em entregas acompanha a atualização em massa
+    history, err := deps.History.GetHistory(page.Items[0].Portal)
+    if err != nil || len(history.Entregas) == 0 {
+        t.Fatalf("GetHistory falhou: %+v (%v)", history, err)
+    }
+    for _, e := range history.Entregas {
+        if e.Enviar || e.Status != model.StatusWarning {
+            t.Fatalf("entrega não atualizada: %+v", e)
+        }
+    }
+
+    // statusManual=false devolve a cada linha o status automático, reavaliado na atualização
+    first := page.Items[0]
+    first.StatusAutomatico = model.StatusOK
+    first.StatusMotivos = nil
+    if err := repo.UpsertPortal(first); err != nil {
+        t.Fatalf("UpsertPortal falhou: %v", err)
+    }
+    if _, err := svc.BulkUpdate(model.PortalQuery{IDs: []string{first.ID}}, bson.M{"statusManual": false}, editor, false); err != nil {
+        t.Fatalf("BulkUpdate falhou: %v", err)
+    }
+    got, _ := repo.GetPortalByID(first.ID)
+    if got.StatusManual || got.StatusAutomatico != model.StatusWarning || got.Status != got.StatusAutomatico || len(got.StatusMotivos) == 0 {
+        t.Fatalf("status automático não reavaliado: %+v", got)
+    }
+}
+
+func TestBulkUpdate_PortalFilterIsExact(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := newTestPortalService(repo)
+    editor := primitive.NewObjectID()
+    fields := bson.M{"observacaoTimeDados": "revisado"}
+
+    // "transparencia" casaria com todas as linhas na listagem
+    if _, err := svc.BulkUpdate(model.PortalQuery{Portal: "transparencia"}, fields, editor, false); !errors.Is(err, ErrInvalidBulkUpdate) {
+        t.Fatalf("esperava ErrInvalidBulkUpdate para filtro parcial, obtive %v", err)
+    }
+    all, _ := repo.GetAllPortals()
+    for _, p := range all {
+        if p.ObservacaoTimeDados == "revisado" {
+            t.Fatalf("filtro parcial alterou %s", p.ID)
+        }
+    }
+
+    result, err := svc.BulkUpdate(model.PortalQuery{Portal: "transparencia_al"}, fields, editor, false)
+    if err != nil || result.Affected != 1 {
+        t.Fatalf("atualização pelo nome completo inesperada: %+v (%v)", result, err)
+    }
+}
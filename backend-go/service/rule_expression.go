@@ -0,0 +1,410 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+)
+
+// ruleExpr é uma expressão compilada de regra, avaliada sobre um model.Portal.
+// Os valores possíveis são float64 (campos inteiros são convertidos), string e bool.
+type ruleExpr func(p *model.Portal) (interface{}, error)
+
+// compileRuleExpression compila expressões como
+//
+//	percentualVolumetriaMediaMovel < 80 && mediaMovelUltimos12Meses > 0
+//	esfera == "MUNICIPAL" && !(indiceDados >= 90 || volumeFonte == 0)
+//
+// Operadores: || && ! == != < <= > >= + - * / e parênteses. Identificadores são os nomes
+// JSON dos campos do portal; textos vão entre aspas duplas.
+func compileRuleExpression(src string) (ruleExpr, error) {
+	tokens, err := tokenizeRule(src)
+	if err != nil {
+		return nil, err
+	}
+	p := &ruleParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("token inesperado %q", p.tokens[p.pos].text)
+	}
+	return expr, nil
+}
+
+type ruleTokenKind int
+
+const (
+	tokNumber ruleTokenKind = iota
+	tokString
+	tokIdent
+	tokOp
+)
+
+type ruleToken struct {
+	kind ruleTokenKind
+	text string
+}
+
+var ruleOperators = []string{"||", "&&", "==", "!=", "<=", ">=", "<", ">", "!", "+", "-", "*", "/", "(", ")"}
+
+func tokenizeRule(src string) ([]ruleToken, error) {
+	var tokens []ruleToken
+	runes := []rune(src)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{tokNumber, string(runes[start:i])})
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("texto sem aspas de fechamento")
+			}
+			tokens = append(tokens, ruleToken{tokString, string(runes[i+1 : end])})
+			i = end + 1
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_') {
+				i++
+			}
+			tokens = append(tokens, ruleToken{tokIdent, string(runes[start:i])})
+		default:
+			matched := false
+			for _, op := range ruleOperators {
+				if strings.HasPrefix(string(runes[i:]), op) {
+					tokens = append(tokens, ruleToken{tokOp, op})
+					i += len([]rune(op))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, fmt.Errorf("caractere inválido %q", r)
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type ruleParser struct {
+	tokens []ruleToken
+	pos    int
+}
+
+func (p *ruleParser) peekOp(ops ...string) (string, bool) {
+	if p.pos >= len(p.tokens) || p.tokens[p.pos].kind != tokOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if p.tokens[p.pos].text == op {
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *ruleParser) parseOr() (ruleExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.peekOp("||"); !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr(left, right, true)
+	}
+}
+
+func (p *ruleParser) parseAnd() (ruleExpr, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if _, ok := p.peekOp("&&"); !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = logicalExpr(left, right, false)
+	}
+}
+
+// logicalExpr combina dois operandos booleanos com curto-circuito (or=true para ||)
+func logicalExpr(left, right ruleExpr, or bool) ruleExpr {
+	return func(portal *model.Portal) (interface{}, error) {
+		l, err := evalBool(left, portal)
+		if err != nil {
+			return nil, err
+		}
+		if l == or {
+			return l, nil
+		}
+		return evalBool(right, portal)
+	}
+}
+
+func (p *ruleParser) parseNot() (ruleExpr, error) {
+	if _, ok := p.peekOp("!"); ok {
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return func(portal *model.Portal) (interface{}, error) {
+			v, err := evalBool(operand, portal)
+			return !v, err
+		}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *ruleParser) parseComparison() (ruleExpr, error) {
+	left, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op, ok := p.peekOp("==", "!=", "<=", ">=", "<", ">")
+	if !ok {
+		return left, nil
+	}
+	p.pos++
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	return func(portal *model.Portal) (interface{}, error) {
+		l, err := left(portal)
+		if err != nil {
+			return nil, err
+		}
+		r, err := right(portal)
+		if err != nil {
+			return nil, err
+		}
+		return compareRuleValues(op, l, r)
+	}, nil
+}
+
+func compareRuleValues(op string, l, r interface{}) (bool, error) {
+	switch lv := l.(type) {
+	case float64:
+		rv, ok := r.(float64)
+		if !ok {
+			return false, fmt.Errorf("comparação entre número e %T", r)
+		}
+		switch op {
+		case "==":
+			return lv == rv, nil
+		case "!=":
+			return lv != rv, nil
+		case "<":
+			return lv < rv, nil
+		case "<=":
+			return lv <= rv, nil
+		case ">":
+			return lv > rv, nil
+		default:
+			return lv >= rv, nil
+		}
+	case string:
+		rv, ok := r.(string)
+		if !ok {
+			return false, fmt.Errorf("comparação entre texto e %T", r)
+		}
+		switch op {
+		case "==":
+			return strings.EqualFold(lv, rv), nil
+		case "!=":
+			return !strings.EqualFold(lv, rv), nil
+		}
+		return false, fmt.Errorf("operador %s não se aplica a textos", op)
+	case bool:
+		rv, ok := r.(bool)
+		if !ok {
+			return false, fmt.Errorf("comparação entre booleano e %T", r)
+		}
+		switch op {
+		case "==":
+			return lv == rv, nil
+		case "!=":
+			return lv != rv, nil
+		}
+		return false, fmt.Errorf("operador %s não se aplica a booleanos", op)
+	}
+	return false, fmt.Errorf("valor não comparável: %v", l)
+}
+
+func (p *ruleParser) parseSum() (ruleExpr, error) {
+	left, err := p.parseProduct()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOp("+", "-")
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseProduct()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmeticExpr(op, left, right)
+	}
+}
+
+func (p *ruleParser) parseProduct() (ruleExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.peekOp("*", "/")
+		if !ok {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = arithmeticExpr(op, left, right)
+	}
+}
+
+func arithmeticExpr(op string, left, right ruleExpr) ruleExpr {
+	return func(portal *model.Portal) (interface{}, error) {
+		l, err := evalNumber(left, portal)
+		if err != nil {
+			return nil, err
+		}
+		r, err := evalNumber(right, portal)
+		if err != nil {
+			return nil, err
+		}
+		switch op {
+		case "+":
+			return l + r, nil
+		case "-":
+			return l - r, nil
+		case "*":
+			return l * r, nil
+		}
+		// Divisão por zero resulta em 0, como os percentuais sem base do MetricsService
+		if r == 0 {
+			return 0.0, nil
+		}
+		return l / r, nil
+	}
+}
+
+func (p *ruleParser) parseUnary() (ruleExpr, error) {
+	if _, ok := p.peekOp("-"); ok {
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return func(portal *model.Portal) (interface{}, error) {
+			v, err := evalNumber(operand, portal)
+			return -v, err
+		}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *ruleParser) parsePrimary() (ruleExpr, error) {
+	if p.pos >= len(p.tokens) {
+		return nil, fmt.Errorf("expressão incompleta")
+	}
+	tok := p.tokens[p.pos]
+	p.pos++
+	switch tok.kind {
+	case tokNumber:
+		n, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("número inválido %q", tok.text)
+		}
+		return func(*model.Portal) (interface{}, error) { return n, nil }, nil
+	case tokString:
+		s := tok.text
+		return func(*model.Portal) (interface{}, error) { return s, nil }, nil
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			b := tok.text == "true"
+			return func(*model.Portal) (interface{}, error) { return b, nil }, nil
+		}
+		field := tok.text
+		if _, ok := (&model.Portal{}).FieldValue(field); !ok {
+			return nil, fmt.Errorf("campo desconhecido %q", field)
+		}
+		return func(portal *model.Portal) (interface{}, error) {
+			v, _ := portal.FieldValue(field)
+			if n, ok := v.(int); ok {
+				return float64(n), nil
+			}
+			return v, nil
+		}, nil
+	}
+	if tok.text == "(" {
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := p.peekOp(")"); !ok {
+			return nil, fmt.Errorf("parêntese sem fechamento")
+		}
+		p.pos++
+		return expr, nil
+	}
+	return nil, fmt.Errorf("token inesperado %q", tok.text)
+}
+
+func evalBool(expr ruleExpr, portal *model.Portal) (bool, error) {
+	v, err := expr(portal)
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("esperado valor booleano, obtido %v", v)
+	}
+	return b, nil
+}
+
+func evalNumber(expr ruleExpr, portal *model.Portal) (float64, error) {
+	v, err := expr(portal)
+	if err != nil {
+		return 0, err
+	}
+	n, ok := v.(float64)
+	if !ok {
+		return 0, fmt.Errorf("esperado número, obtido %v", v)
+	}
+	return n, nil
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+	"solid_react_golang_mongo_project/backend-go/repository"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrRuleNotFound = errors.New("regra não encontrada")
+	ErrInvalidRule  = errors.New("regra inválida")
+)
+
+// RuleService gerencia as regras de validação configuráveis e as avalia sobre os portais
+type RuleService interface {
+	ListRules() ([]model.ValidationRule, error)
+	CreateRule(rule model.ValidationRule, userID primitive.ObjectID) (model.ValidationRule, error)
+	UpdateRule(id primitive.ObjectID, rule model.ValidationRule, userID primitive.ObjectID) (model.ValidationRule, error)
+	DeleteRule(id, userID primitive.ObjectID) error
+	// CurrentRuleSet retorna a versão vigente (versão 0, sem regras, antes da primeira alteração)
+	CurrentRuleSet() (model.RuleSet, error)
+	GetRuleSet(version int) (model.RuleSet, error)
+	// Evaluate aplica as regras vigentes: cada regra violada vira um motivo em StatusMotivos e pode
+	// agravar StatusAutomatico (e o Status, sem override manual). RegrasVersao registra a versão usada.
+	Evaluate(portal model.Portal) (model.Portal, error)
+}
+
+type compiledRule struct {
+	rule model.ValidationRule
+	expr ruleExpr
+}
+
+type ruleService struct {
+	repo repository.RuleRepository
+
+	mu       sync.Mutex
+	loaded   bool
+	version  int
+	compiled []compiledRule
+}
+
+func NewRuleService(repo repository.RuleRepository) RuleService {
+	return &ruleService{repo: repo}
+}
+
+func (s *ruleService) ListRules() ([]model.ValidationRule, error) {
+	return s.repo.ListRules()
+}
+
+// validateRule normaliza a regra e garante que a expressão compile e seja avaliável
+func validateRule(rule *model.ValidationRule) error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.Severity = strings.ToUpper(strings.TrimSpace(rule.Severity))
+	if rule.Name == "" {
+		return fmt.Errorf("%w: nome é obrigatório", ErrInvalidRule)
+	}
+	if rule.Severity != model.StatusWarning && rule.Severity != model.StatusError {
+		return fmt.Errorf("%w: severidade deve ser WARNING ou ERROR", ErrInvalidRule)
+	}
+	if strings.TrimSpace(rule.Message) == "" {
+		return fmt.Errorf("%w: mensagem é obrigatória", ErrInvalidRule)
+	}
+	expr, err := compileRuleExpression(rule.Expression)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	// Avalia sobre um portal vazio para detectar erros de tipo (ex.: texto comparado a número)
+	if _, err := evalBool(expr, &model.Portal{}); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRule, err)
+	}
+	return nil
+}
+
+func (s *ruleService) CreateRule(rule model.ValidationRule, userID primitive.ObjectID) (model.ValidationRule, error) {
+	if err := validateRule(&rule); err != nil {
+		return rule, err
+	}
+	rule.CriadoEm = time.Now()
+	rule.AtualizadoEm = rule.CriadoEm
+	created, err := s.repo.InsertRule(rule)
+	if err != nil {
+		return created, err
+	}
+	return created, s.publish(userID)
+}
+
+func (s *ruleService) UpdateRule(id primitive.ObjectID, rule model.ValidationRule, userID primitive.ObjectID) (model.ValidationRule, error) {
+	existing, err := s.repo.GetRule(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return rule, ErrRuleNotFound
+	}
+	if err != nil {
+		return rule, err
+	}
+	if err := validateRule(&rule); err != nil {
+		return rule, err
+	}
+	rule.ID = id
+	rule.CriadoEm = existing.CriadoEm
+	rule.AtualizadoEm = time.Now()
+	if err := s.repo.UpdateRule(rule); err != nil {
+		return rule, err
+	}
+	return rule, s.publish(userID)
+}
+
+func (s *ruleService) DeleteRule(id, userID primitive.ObjectID) error {
+	err := s.repo.DeleteRule(id)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return ErrRuleNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return s.publish(userID)
+}
+
+// publish grava uma nova versão com as regras ativas e invalida o cache de regras compiladas
+func (s *ruleService) publish(userID primitive.ObjectID) error {
+	current, err := s.CurrentRuleSet()
+	if err != nil {
+		return err
+	}
+	rules, err := s.repo.ListRules()
+	if err != nil {
+		return err
+	}
+	active := []model.ValidationRule{}
+	for _, rule := range rules {
+		if rule.Active {
+			active = append(active, rule)
+		}
+	}
+	set := model.RuleSet{Version: current.Version + 1, Rules: active, CriadoEm: time.Now(), CriadoPor: userID}
+	if err := s.repo.InsertRuleSet(set); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.loaded = false
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *ruleService) CurrentRuleSet() (model.RuleSet, error) {
+	set, err := s.repo.LatestRuleSet()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return model.RuleSet{Rules: []model.ValidationRule{}}, nil
+	}
+	return set, err
+}
+
+func (s *ruleService) GetRuleSet(version int) (model.RuleSet, error) {
+	set, err := s.repo.GetRuleSet(version)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return set, ErrRuleNotFound
+	}
+	return set, err
+}
+
+// current devolve as regras compiladas da versão vigente, carregando-as na primeira chamada
+func (s *ruleService) current() (int, []compiledRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.loaded {
+		return s.version, s.compiled, nil
+	}
+	set, err := s.CurrentRuleSet()
+	if err != nil {
+		return 0, nil, err
+	}
+	compiled := make([]compiledRule, 0, len(set.Rules))
+	for _, rule := range set.Rules {
+		expr, err := compileRuleExpression(rule.Expression)
+		if err != nil {
+			log.Printf("Regra %q ignorada (versão %d): %v", rule.Name, set.Version, err)
+			continue
+		}
+		compiled = append(compiled, compiledRule{rule: rule, expr: expr})
+	}
+	s.version, s.compiled, s.loaded = set.Version, compiled, true
+	return s.version, s.compiled, nil
+}
+
+func (s *ruleService) Evaluate(portal model.Portal) (model.Portal, error) {
+	version, rules, err := s.current()
+	if err != nil {
+		return portal, err
+	}
+	for _, r := range rules {
+		if r.rule.Esfera != "" && !strings.EqualFold(r.rule.Esfera, portal.Esfera) {
+			continue
+		}
+		if r.rule.Portal != "" && r.rule.Portal != portal.Portal {
+			continue
+		}
+		violated, err := evalBool(r.expr, &portal)
+		if err != nil {
+			log.Printf("Erro ao avaliar regra %q no portal %s: %v", r.rule.Name, portal.ID, err)
+			continue
+		}
+		if !violated {
+			continue
+		}
+		if statusSeverity[r.rule.Severity] > statusSeverity[portal.StatusAutomatico] {
+			portal.StatusAutomatico = r.rule.Severity
+		}
+		portal.StatusMotivos = append(portal.StatusMotivos, fmt.Sprintf("%s: %s (regra %s)", r.rule.Severity, r.rule.Message, r.rule.Name))
+	}
+	if !portal.StatusManual && portal.StatusAutomatico != "" {
+		portal.Status = portal.StatusAutomatico
+	}
+	portal.RegrasVersao = version
+	return portal, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+    "errors"
+    "strings"
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCompileRuleExpression(t *testing.T) {
+    portal := &model.Portal{Esfera: "MUNICIPAL", VolumetriaServicos: 70, MediaMovelUltimos12Meses: 100, IndiceDados: 95.5, PulouCompetencia: true}
+    cases := map[string]bool{
+        `volumetriaServicos < 0.8 * mediaMovelUltimos12Meses`:                       true,
+        `esfera == "municipal" && !(indiceDados >= 90 || pulouCompetencia == false)`: false,
+        `(volumetriaServicos - mediaMovelUltimos12Meses) / mediaMovelUltimos12Meses < -0.2`: true,
+        `pulouCompetencia && volumeFonte / 0 == 0`:                                   true,
+    }
+    for src, want := range cases {
+        expr, err := compileRuleExpression(src)
+        if err != nil {
+            t.Fatalf("%s: erro de compilação: %v", src, err)
+        }
+        got, err := evalBool(expr, portal)
+        if err != nil || got != want {
+            t.Fatalf("%s: esperava %v, obtive %v (%v)", src, want, got, err)
+        }
+    }
+
+    for _, invalid := range []string{`campoInexistente > 1`, `volumeFonte >`, `(volumeFonte > 1`, `esfera == "x`, `volumeFonte # 2`} {
+        if _, err := compileRuleExpression(invalid); err == nil {
+            t.Fatalf("%s: esperava erro de compilação", invalid)
+        }
+    }
+}
+
+func TestRuleService_VersionedEvaluation(t *testing.T) {
+    svc := NewRuleService(repository.NewMockRuleRepository())
+    admin := primitive.NewObjectID()
+
+    if _, err := svc.CreateRule(model.ValidationRule{Name: "tipo", Expression: `esfera > 1`, Severity: "ERROR", Message: "x"}, admin); !errors.Is(err, ErrInvalidRule) {
+        t.Fatalf("esperava ErrInvalidRule para erro de tipo, obtive %v", err)
+    }
+
+    rule, err := svc.CreateRule(model.ValidationRule{
+        Name: "queda-municipal", Expression: `percentualVolumetriaMediaMovel < 80`, Severity: "warning",
+        Esfera: "MUNICIPAL", Message: "volume abaixo de 80% da média móvel", Active: true,
+    }, admin)
+    if err != nil {
+        t.Fatalf("CreateRule falhou: %v", err)
+    }
+
+    portal := model.Portal{Esfera: "MUNICIPAL", PercentualVolumetriaMediaMovel: 70, StatusAutomatico: model.StatusOK, Status: model.StatusOK}
+    got, err := svc.Evaluate(portal)
+    if err != nil {
+        t.Fatalf("Evaluate falhou: %v", err)
+    }
+    if got.Status != model.StatusWarning || got.RegrasVersao != 1 || len(got.StatusMotivos) != 1 || !strings.Contains(got.StatusMotivos[0], "queda-municipal") {
+        t.Fatalf("avaliação inesperada: %+v", got)
+    }
+    if other, _ := svc.Evaluate(model.Portal{Esfera: "ESTADUAL", PercentualVolumetriaMediaMovel: 70, StatusAutomatico: model.StatusOK}); other.StatusAutomatico != model.StatusOK {
+        t.Fatalf("regra fora do escopo foi aplicada: %+v", other)
+    }
+
+    // Desativar a regra publica a versão 2, e a versão 1 continua consultável
+    rule.Active = false
+    if _, err := svc.UpdateRule(rule.ID, rule, admin); err != nil {
+        t.Fatalf("UpdateRule falhou: %v", err)
+    }
+    got, _ = svc.Evaluate(portal)
+    if got.Status != model.StatusOK || got.RegrasVersao != 2 {
+        t.Fatalf("regra desativada ainda aplicada: %+v", got)
+    }
+    v1, err := svc.GetRuleSet(1)
+    if err != nil || len(v1.Rules) != 1 || v1.CriadoPor != admin {
+        t.Fatalf("versão 1 inesperada: %+v %v", v1, err)
+    }
+    if err := svc.DeleteRule(primitive.NewObjectID(), admin); !errors.Is(err, ErrRuleNotFound) {
+        t.Fatalf("esperava ErrRuleNotFound, obtive %v", err)
+    }
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"fmt"
+
+	"solid_react_golang_mongo_project/backend-go/model"
+)
+
+// StatusThresholds são os limites (em %) usados na classificação automática do Status
+type StatusThresholds struct {
+	// Faixas aceitas para a volumetria em relação à média móvel de 12 meses
+	MediaMovelWarningMin float64
+	MediaMovelWarningMax float64
+	MediaMovelErrorMin   float64
+	MediaMovelErrorMax   float64
+	// Valores mínimos dos índices de agregação
+	IndiceDadosWarning    float64
+	IndiceDadosError      float64
+	IndiceServicosWarning float64
+	IndiceServicosError   float64
+}
+
+// DefaultStatusThresholds reproduz os critérios usados na revisão manual das entregas
+func DefaultStatusThresholds() StatusThresholds {
+	return StatusThresholds{
+		MediaMovelWarningMin:  80,
+		MediaMovelWarningMax:  120,
+		MediaMovelErrorMin:    50,
+		MediaMovelErrorMax:    150,
+		IndiceDadosWarning:    90,
+		IndiceDadosError:      70,
+		IndiceServicosWarning: 95,
+		IndiceServicosError:   80,
+	}
+}
+
+// StatusClassifier deriva o Status (OK/WARNING/ERROR) de um portal a partir das métricas calculadas
+type StatusClassifier interface {
+	// Classify preenche StatusAutomatico e StatusMotivos; o Status só é alterado quando não há override manual
+	Classify(portal model.Portal) model.Portal
+}
+
+type statusClassifier struct {
+	thresholds StatusThresholds
+}
+
+func NewStatusClassifier(thresholds StatusThresholds) StatusClassifier {
+	return &statusClassifier{thresholds: thresholds}
+}
+
+// statusSeverity ordena os status para que prevaleça o mais grave
+var statusSeverity = map[string]int{model.StatusOK: 0, model.StatusWarning: 1, model.StatusError: 2}
+
+func (c *statusClassifier) Classify(portal model.Portal) model.Portal {
+	t := c.thresholds
+	status := model.StatusOK
+	var reasons []string
+	flag := func(s, reason string) {
+		if statusSeverity[s] > statusSeverity[status] {
+			status = s
+		}
+		reasons = append(reasons, fmt.Sprintf("%s: %s", s, reason))
+	}
+
+	// Sem média móvel (primeira entrega) não há base de comparação
+	if portal.MediaMovelUltimos12Meses > 0 {
+		pct := portal.PercentualVolumetriaMediaMovel
+		switch {
+		case pct < t.MediaMovelErrorMin || pct > t.MediaMovelErrorMax:
+			flag(model.StatusError, fmt.Sprintf("volumetria em %.1f%% da média móvel (aceito de %.0f%% a %.0f%%)", pct, t.MediaMovelErrorMin, t.MediaMovelErrorMax))
+		case pct < t.MediaMovelWarningMin || pct > t.MediaMovelWarningMax:
+			flag(model.StatusWarning, fmt.Sprintf("volumetria em %.1f%% da média móvel (esperado de %.0f%% a %.0f%%)", pct, t.MediaMovelWarningMin, t.MediaMovelWarningMax))
+		}
+	}
+	if portal.VolumeFonte > 0 {
+		switch {
+		case portal.IndiceDados < t.IndiceDadosError:
+			flag(model.StatusError, fmt.Sprintf("índice de dados %.1f%% abaixo de %.0f%%", portal.IndiceDados, t.IndiceDadosError))
+		case portal.IndiceDados < t.IndiceDadosWarning:
+			flag(model.StatusWarning, fmt.Sprintf("índice de dados %.1f%% abaixo de %.0f%%", portal.IndiceDados, t.IndiceDadosWarning))
+		}
+	}
+	if portal.VolumetriaDados > 0 {
+		switch {
+		case portal.IndiceServicos < t.IndiceServicosError:
+			flag(model.StatusError, fmt.Sprintf("índice de serviços %.1f%% abaixo de %.0f%%", portal.IndiceServicos, t.IndiceServicosError))
+		case portal.IndiceServicos < t.IndiceServicosWarning:
+			flag(model.StatusWarning, fmt.Sprintf("índice de serviços %.1f%% abaixo de %.0f%%", portal.IndiceServicos, t.IndiceServicosWarning))
+		}
+	}
+	if portal.PulouCompetencia {
+		flag(model.StatusWarning, "competência pulada em relação à entrega anterior")
+	}
+	if portal.DefasagemNosDados {
+		flag(model.StatusWarning, "defasagem nos dados")
+	}
+
+	portal.StatusAutomatico = status
+	portal.StatusMotivos = reasons
+	if !portal.StatusManual {
+		portal.Status = status
+	}
+	return portal
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+    "testing"
+    "solid_react_golang_mongo_project/backend-go/model"
+    "solid_react_golang_mongo_project/backend-go/repository"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestClassifyStatus(t *testing.T) {
+    classifier := NewStatusClassifier(DefaultStatusThresholds())
+
+    ok := classifier.Classify(model.Portal{VolumeFonte: 100, IndiceDados: 99, VolumetriaDados: 99, IndiceServicos: 100, MediaMovelUltimos12Meses: 90, PercentualVolumetriaMediaMovel: 110})
+    if ok.Status != model.StatusOK || len(ok.StatusMotivos) != 0 {
+        t.Fatalf("esperava OK sem motivos: %s %v", ok.Status, ok.StatusMotivos)
+    }
+
+    warning := classifier.Classify(model.Portal{MediaMovelUltimos12Meses: 90, PercentualVolumetriaMediaMovel: 75, PulouCompetencia: true})
+    if warning.Status != model.StatusWarning || len(warning.StatusMotivos) != 2 {
+        t.Fatalf("esperava WARNING com 2 motivos: %s %v", warning.Status, warning.StatusMotivos)
+    }
+
+    // O motivo mais grave prevalece
+    failing := classifier.Classify(model.Portal{VolumeFonte: 100, IndiceDados: 60, DefasagemNosDados: true})
+    if failing.Status != model.StatusError || failing.StatusAutomatico != model.StatusError {
+        t.Fatalf("esperava ERROR: %s %v", failing.Status, failing.StatusMotivos)
+    }
+
+    manual := classifier.Classify(model.Portal{VolumeFonte: 100, IndiceDados: 60, Status: model.StatusOK, StatusManual: true})
+    if manual.Status != model.StatusOK || manual.StatusAutomatico != model.StatusError {
+        t.Fatalf("override manual não preservado: %s / %s", manual.Status, manual.StatusAutomatico)
+    }
+}
+
+func TestStatusManualOverride_SurvivesImport(t *testing.T) {
+    repo := repository.NewMockPortalRepository()
+    svc := newTestPortalService(repo)
+    row := model.Portal{ID: "m1", Portal: "transparencia_ba", MesAnoReferencia: mustCompetencia("08/2024"), VolumeFonte: 100, VolumetriaDados: 50}
+
+    saved, err := svc.SavePortal(row)
+    if err != nil || saved.Status != model.StatusError {
+        t.Fatalf("esperava ERROR automático: %+v %v", saved, err)
+    }
+    if err := svc.UpdatePortalFieldsMap("m1", bson.M{"status": model.StatusWarning}, primitive.NewObjectID(), nil); err != nil {
+        t.Fatalf("UpdatePortalFieldsMap falhou: %v", err)
+    }
+    if _, err := svc.SavePortal(row); err != nil {
+        t.Fatalf("reimportação falhou: %v", err)
+    }
+    got, _ := repo.GetPortalByID("m1")
+    if got.Status != model.StatusWarning || !got.StatusManual || got.StatusAutomatico != model.StatusError {
+        t.Fatalf("override perdido na reimportação: %+v", got)
+    }
+
+    if err := svc.UpdatePortalFieldsMap("m1", bson.M{"statusManual": false}, primitive.NewObjectID(), nil); err != nil {
+        t.Fatalf("UpdatePortalFieldsMap falhou: %v", err)
+    }
+    got, _ = repo.GetPortalByID("m1")
+    if got.Status != model.StatusError || got.StatusManual {
+        t.Fatalf("status deveria voltar ao automático: %+v", got)
+    }
+}
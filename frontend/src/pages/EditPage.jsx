@@ -19,6 +19,8 @@ const EditPage = () => {
   const [saving, setSaving] = useState(false);
   const [saveError, setSaveError] = useState(null);
   const [saveSuccess, setSaveSuccess] = useState(null);
+  // ETag (versão) lido no GET: enviado no If-Match para não sobrescrever edições concorrentes
+  const [etag, setEtag] = useState(null);
 
   useEffect(() => {
     const fetchPortal = async () => {
@@ -28,6 +30,7 @@ const EditPage = () => {
         // Usar proxy de API para funcionar em dev (CRA/Vite) e produção (Nginx)
         const response = await axios.get(`/api/portals/${id}`);
         setPortalData(response.data);
+        setEtag(response.headers.etag || null);
         setObservacaoTimeDados(response.data.observacaoTimeDados || '');
         setEnviar(!!response.data.enviar);
         // Demais campos permanecem somente leitura na UI
@@ -240,17 +243,29 @@ const EditPage = () => {
                 setSaveError(null);
                 setSaveSuccess(null);
                 try {
-                  await axios.put(`/api/portals/${id}`, {
+                  const response = await axios.put(`/api/portals/${id}`, {
                     observacaoTimeDados,
                     enviar,
-                  });
+                  }, { headers: etag ? { 'If-Match': etag } : {} });
                   setSaveSuccess('Alterações salvas com sucesso.');
                   // Atualizar dados exibidos
-                  setPortalData({ ...portalData, observacaoTimeDados, enviar });
+                  setPortalData(response.data.portal || { ...portalData, observacaoTimeDados, enviar });
+                  setEtag(response.headers.etag || null);
                 } catch (err) {
                   console.error('Erro ao salvar dados:', err);
-                  const msg = err.response?.data?.error || 'Erro ao salvar alterações';
-                  setSaveError(msg);
+                  const status = err.response?.status;
+                  if ((status === 409 || status === 412) && err.response.data?.portal) {
+                    // Outra pessoa salvou antes: recarrega a versão atual para o usuário revisar
+                    const current = err.response.data.portal;
+                    setPortalData(current);
+                    setObservacaoTimeDados(current.observacaoTimeDados || '');
+                    setEnviar(!!current.enviar);
+                    setEtag(err.response.headers.etag || null);
+                    setSaveError('O portal foi alterado por outro usuário. Os dados atuais foram recarregados; revise e salve novamente.');
+                  } else {
+                    const msg = err.response?.data?.error || 'Erro ao salvar alterações';
+                    setSaveError(msg);
+                  }
                 } finally {
                   setSaving(false);
                 }
@@ -79,8 +79,9 @@ const ListPage = () => {
     setError(null);
     try {
       // Usar proxy do Nginx/React para compatibilidade em produção e dev
+      // A API retorna uma página { items, total, limit, offset }; sem limit vêm todos os registros
       const response = await axios.get('/api/portals');
-      setPortals(response.data || []);
+      setPortals((response.data && response.data.items) || []);
     } catch (err) {
       setError('Erro ao carregar dados da API: ' + err.message);
       console.error('Erro ao buscar dados da API:', err);